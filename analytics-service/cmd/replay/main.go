@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/4udiwe/big-bob-pizza/analytics-service/internal/app"
+	"github.com/labstack/gommon/log"
+)
+
+// replay повторно прогоняет события order.events через обработчик аналитики.
+//
+//	CONFIG_PATH=config/config.yaml go run ./cmd/replay -consumer order -from 2025-01-01T00:00:00Z -dry-run
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	app := app.New(os.Getenv("CONFIG_PATH"))
+	if err := app.Replay(ctx, os.Args[1:]); err != nil {
+		log.Fatalf("replay: %v", err)
+	}
+}
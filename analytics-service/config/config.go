@@ -1,45 +1,71 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/configloader"
 )
 
 type (
 	Config struct {
-		App       App       `yaml:"app"`
-		HTTP      HTTP      `yaml:"http"`
-		Postgres  Postgres  `yaml:"postgres"`
-		Log       Log       `yaml:"logger"`
-		Kafka     Kafka     `yaml:"kafka"`
+		App        App        `yaml:"app"`
+		HTTP       HTTP       `yaml:"http"`
+		Health     Health     `yaml:"health"`
+		Postgres   Postgres   `yaml:"postgres"`
+		Log        Log        `yaml:"logger"`
+		Kafka      Kafka      `yaml:"kafka"`
 		Prometheus Prometheus `yaml:"prometheus"`
 	}
 
 	App struct {
-		Name    string `env-required:"true" yaml:"name" env:"APP_NAME"`
-		Version string `env-required:"true" yaml:"version" env:"APP_VERSION"`
+		Name    string `yaml:"name" env:"APP_NAME" validate:"required"`
+		Version string `yaml:"version" env:"APP_VERSION" validate:"required"`
+		// ShutdownTimeout — общий бюджет остановки: HTTP, консьюмеры и воркеры дообрабатывают
+		// начатую работу, после чего закрываются пулы.
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT"`
 	}
 
 	HTTP struct {
-		Port string `env-required:"true" yaml:"port" env:"SERVER_PORT"`
+		Port string `yaml:"port" env:"SERVER_PORT" validate:"required,numeric"`
+		// BodyLimit — максимальный размер тела запроса ("1M"); больше — 413.
+		BodyLimit      string        `yaml:"body_limit" env:"HTTP_BODY_LIMIT"`
+		RequestTimeout time.Duration `yaml:"request_timeout" env:"HTTP_REQUEST_TIMEOUT"`
+		// RouteTimeouts переопределяет RequestTimeout для маршрутов: "POST /admin/outbox/republish": 4s.
+		RouteTimeouts map[string]time.Duration `yaml:"route_timeouts"`
+	}
+
+	// Health — пробы /livez и /readyz.
+	Health struct {
+		CacheTTL     time.Duration `yaml:"cache_ttl" env:"HEALTH_CACHE_TTL"`
+		CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+		// MaxPollAge — консьюмер, чей цикл чтения дольше не обращался к брокеру, считается зависшим.
+		MaxPollAge time.Duration `yaml:"max_poll_age" env:"HEALTH_MAX_POLL_AGE"`
+		// DrainDelay — сколько после SIGTERM сервис отвечает «не готов», продолжая принимать запросы.
+		DrainDelay time.Duration `yaml:"drain_delay" env:"HEALTH_DRAIN_DELAY"`
 	}
 
 	Log struct {
-		Level string `env-required:"true" yaml:"level" env:"LOG_LEVEL"`
+		Level string `yaml:"level" env:"LOG_LEVEL" validate:"required,oneof=panic fatal error warn warning info debug trace"`
 	}
 
 	Postgres struct {
-		URL            string        `env-required:"true" yaml:"url" env:"POSTGRES_URL"`
-		ConnectTimeout time.Duration `env-required:"true" yaml:"connect_timeout" env:"POSTGRES_CONNECT_TIMEOUT"`
+		URL            string        `yaml:"url" env:"POSTGRES_URL" validate:"required" secret:"true"`
+		ConnectTimeout time.Duration `yaml:"connect_timeout" env:"POSTGRES_CONNECT_TIMEOUT" validate:"required"`
+		// ReplicaURLs — DSN реплик для чтения вне транзакций; пусто — всё читается с primary.
+		ReplicaURLs   []string      `yaml:"replica_urls" env:"POSTGRES_REPLICA_URLS" secret:"true"`
+		MaxReplicaLag time.Duration `yaml:"max_replica_lag" env:"POSTGRES_MAX_REPLICA_LAG"`
+		// SlowQueryThreshold — запросы дольше этого пишутся в лог как медленные.
+		SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"POSTGRES_SLOW_QUERY_THRESHOLD"`
 	}
 
 	Kafka struct {
-		Brokers []string `env-required:"true" yaml:"brokers" env:"KAFKA_BROKERS"`
+		Brokers []string `yaml:"brokers" env:"KAFKA_BROKERS" validate:"required,dive,hostname_port"`
 		Topics  struct {
-			OrderEvents string `env-required:"true" yaml:"order_events" env:"KAFKA_TOPIC_ORDER_EVENTS"`
-		} `env-required:"true" yaml:"topics" env:"KAFKA_TOPICS"`
+			OrderEvents string `yaml:"order_events" env:"KAFKA_TOPIC_ORDER_EVENTS" validate:"required"`
+		} `yaml:"topics" env:"KAFKA_TOPICS"`
 		Consumer KafkaConsumer `yaml:"consumer"`
 	}
 
@@ -49,25 +75,58 @@ type (
 		SessionTimeout    time.Duration `yaml:"session_timeout" env:"KAFKA_CONSUMER_SESSION_TIMEOUT"`
 		HeartbeatInterval time.Duration `yaml:"heartbeat_interval" env:"KAFKA_CONSUMER_HEARTBEAT_INTERVAL"`
 		CommitInterval    time.Duration `yaml:"commit_interval" env:"KAFKA_CONSUMER_COMMIT_INTERVAL"`
+		Concurrency       int           `yaml:"concurrency" env:"KAFKA_CONSUMER_CONCURRENCY"`
+		DrainTimeout      time.Duration `yaml:"drain_timeout" env:"KAFKA_CONSUMER_DRAIN_TIMEOUT"`
+		BatchSize         int           `yaml:"batch_size" env:"KAFKA_CONSUMER_BATCH_SIZE"`
+		BatchWait         time.Duration `yaml:"batch_wait" env:"KAFKA_CONSUMER_BATCH_WAIT"`
+		// RetryBackoff — пауза перед повтором пачки, на которой упал обработчик; удваивается
+		// до RetryMaxBackoff. Пока пачка не обработана, её offset не коммитятся.
+		RetryBackoff    time.Duration `yaml:"retry_backoff" env:"KAFKA_CONSUMER_RETRY_BACKOFF"`
+		RetryMaxBackoff time.Duration `yaml:"retry_max_backoff" env:"KAFKA_CONSUMER_RETRY_MAX_BACKOFF"`
 	}
 
 	Prometheus struct {
 		Enabled bool   `yaml:"enabled" env:"PROMETHEUS_ENABLED"`
-		Path    string `yaml:"path" env:"PROMETHEUS_PATH"`
+		Path    string `yaml:"path" env:"PROMETHEUS_PATH" validate:"omitempty,startswith=/"`
 	}
 )
 
+// New загружает конфигурацию из YAML-файла и переменных окружения (см. configloader.Load)
+// и проверяет её. Строку подключения к Postgres можно передать файлом: POSTGRES_URL_FILE.
 func New(configPath string) (*Config, error) {
 	cfg := &Config{}
 
-	if err := cleanenv.ReadConfig(configPath, cfg); err != nil {
-		return nil, fmt.Errorf("config - NewConfig - cleanenv.ReadConfig: %w", err)
-	}
-
-	if err := cleanenv.UpdateEnv(cfg); err != nil {
-		return nil, fmt.Errorf("config - NewConfig - cleanenv.UpdateEnv: %w", err)
+	if err := configloader.Load(configPath, cfg); err != nil {
+		return nil, fmt.Errorf("config - NewConfig - configloader.Load: %w", err)
 	}
 
 	return cfg, nil
 }
 
+// Validate проверяет зависимости между полями, которые не выражаются тегами validate.
+func (c *Config) Validate() error {
+	var errs []error
+
+	errs = append(errs, c.App.validate(c.Health)...)
+	errs = append(errs, c.HTTP.validate()...)
+
+	return errors.Join(errs...)
+}
+
+func (a App) validate(h Health) []error {
+	if a.ShutdownTimeout > 0 && h.DrainDelay >= a.ShutdownTimeout {
+		return []error{fmt.Errorf("health.drain_delay: must be less than app.shutdown_timeout (%s), got %s",
+			a.ShutdownTimeout, h.DrainDelay)}
+	}
+	return nil
+}
+
+func (h HTTP) validate() []error {
+	var errs []error
+	for route := range h.RouteTimeouts {
+		if method, path, ok := strings.Cut(route, " "); !ok || method == "" || !strings.HasPrefix(path, "/") {
+			errs = append(errs, fmt.Errorf(`http.route_timeouts: key must be "METHOD /path", got %q`, route))
+		}
+	}
+	return errs
+}
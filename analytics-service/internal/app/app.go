@@ -2,17 +2,18 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/4udiwe/big-bob-pizza/analytics-service/config"
 	consumer_order "github.com/4udiwe/big-bob-pizza/analytics-service/internal/consumer/order"
-	"github.com/4udiwe/big-bob-pizza/analytics-service/internal/database"
 	order_event_repository "github.com/4udiwe/big-bob-pizza/analytics-service/internal/repository/order_event"
 	"github.com/4udiwe/big-bob-pizza/analytics-service/internal/service/analytics"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/health"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/httpserver"
-	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/lifecycle"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
@@ -22,11 +23,15 @@ type App struct {
 	cfg       *config.Config
 	interrupt chan os.Signal
 
+	// autoMigrate — применять миграции при старте (serve -auto-migrate).
+	autoMigrate bool
+
 	// DB
 	postgres *postgres.Postgres
 
 	// Echo
 	echoHandler *echo.Echo
+	health      *health.Health
 
 	// Repositories
 	orderEventRepo *order_event_repository.Repository
@@ -41,7 +46,9 @@ type App struct {
 func New(configPath string) *App {
 	cfg, err := config.New(configPath)
 	if err != nil {
-		log.Fatalf("app - New - config.New: %v", err)
+		// Логгер ещё не настроен, а в JSON список ошибок конфигурации склеился бы в одну строку.
+		fmt.Fprintf(os.Stderr, "app - New - config.New: %v\n", err)
+		os.Exit(1)
 	}
 
 	initLogger(cfg.Log.Level)
@@ -56,65 +63,70 @@ func New(configPath string) *App {
 }
 
 func (app *App) Start() {
+	// Компоненты регистрируются в порядке зависимостей: пул, консьюмер, HTTP.
+	// При остановке порядок обратный — сначала прекращается приём запросов и сообщений,
+	// затем дообрабатываются прочитанные события, и только потом закрывается пул.
+	lc := lifecycle.New(lifecycle.ShutdownTimeout(app.cfg.App.ShutdownTimeout))
+
 	// Postgres
 	log.Info("Connecting to PostgreSQL...")
 
-	postgres, err := postgres.New(app.cfg.Postgres.URL, postgres.ConnAttempts(5))
+	postgres, err := postgres.New(
+		app.cfg.Postgres.URL,
+		postgres.ConnAttempts(5),
+		postgres.Replicas(app.cfg.Postgres.ReplicaURLs...),
+		postgres.MaxReplicaLag(app.cfg.Postgres.MaxReplicaLag),
+		postgres.SlowQueryThreshold(app.cfg.Postgres.SlowQueryThreshold),
+	)
 
 	if err != nil {
 		log.Fatalf("app - Start - Postgres failed:%v", err)
 	}
 	app.postgres = postgres
+	app.Health().AddReadinessCheck("postgres", postgres.Ping)
 
-	defer postgres.Close()
-
-	// Migrations
-	if err := database.RunMigrations(context.Background(), app.postgres.Pool); err != nil {
-		log.Errorf("app - Start - Migrations failed: %v", err)
-	}
-
-	// Общий контекст для всех фоновых задач
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Prometheus metrics
-	metrics := analytics.NewMetrics()
+	lc.Append(lifecycle.Hook{
+		Name: "postgres",
+		OnStop: func(context.Context) error {
+			postgres.Close()
+			return nil
+		},
+	})
 
-	// Инициализируем сервис с метриками
-	app.analyticsService = analytics.NewService(
-		app.OrderEventRepo(),
-		metrics,
-	)
+	// Migrations: схема должна соответствовать миграциям бинарника (см. checkSchema).
+	app.checkSchema(context.Background())
 
 	// Consumer для order.events
-	orderKafkaConsumer := kafka.NewConsumer(app.cfg.Kafka.Brokers)
-
 	app.orderConsumer = consumer_order.New(
-		app.analyticsService,
-		orderKafkaConsumer,
+		app.AnalyticsService(),
+		app.newKafkaConsumer("order consumer"),
 		app.cfg.Kafka.Topics.OrderEvents,
 		app.cfg.Kafka.Consumer.GroupID,
 	)
 
-	// App server
-	log.Info("Starting app server...")
-	httpServer := httpserver.New(app.EchoHandler(), httpserver.Port(app.cfg.HTTP.Port))
-	httpServer.Start()
-	log.Debugf("Server port: %s", app.cfg.HTTP.Port)
+	lc.Append(app.consumerHook("order consumer", app.orderConsumer))
 
-	// Run consumer
-	app.orderConsumer.Run(ctx)
+	// Kafka нужна только консьюмеру: отчёты строятся по Postgres и без брокеров.
+	app.Health().AddOptionalReadinessCheck("kafka", app.kafkaCheck)
 
-	select {
-	case s := <-app.interrupt:
-		log.Infof("app - Start - signal: %v", s)
-	case err := <-httpServer.Notify():
-		log.Errorf("app - Start - server error: %v", err)
-	}
+	// App server
+	httpServer := httpserver.New(app.EchoHandler(), httpserver.Port(app.cfg.HTTP.Port))
 
-	// Останавливаем HTTP‑сервер после получения сигнала/ошибки.
-	if err := httpServer.Shutdown(); err != nil {
-		log.Errorf("HTTP server shutdown error: %v", err)
+	lc.Append(lifecycle.Hook{
+		Name: "http server",
+		OnStart: func(context.Context) error {
+			log.Infof("Starting app server on port %s...", app.cfg.HTTP.Port)
+			httpServer.Start()
+			lc.Watch("http server", httpServer.Notify())
+			return nil
+		},
+		OnStop: httpServer.Stop,
+	})
+
+	lc.Append(app.readinessHook())
+
+	if err := lc.Run(app.interrupt); err != nil {
+		log.Errorf("app - Start: %v", err)
 	}
 
 	log.Info("Shutting down...")
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/lifecycle"
+)
+
+// consumer — консьюмер топика: Run подписывается на топик, Shutdown дообрабатывает прочитанные сообщения.
+type consumer interface {
+	Run(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// newKafkaConsumer создаёт KafkaConsumer с настройками из секции kafka.consumer конфига
+// и регистрирует проверку liveness его цикла чтения под именем name.
+func (app *App) newKafkaConsumer(name string) *kafka.KafkaConsumer {
+	c := app.cfg.Kafka.Consumer
+
+	consumer := kafka.NewConsumer(app.cfg.Kafka.Brokers,
+		kafka.ConsumerConcurrency(c.Concurrency),
+		kafka.ConsumerMaxWait(c.MaxWait),
+		kafka.ConsumerSessionTimeout(c.SessionTimeout),
+		kafka.ConsumerHeartbeatInterval(c.HeartbeatInterval),
+		kafka.ConsumerCommitInterval(c.CommitInterval),
+		kafka.ConsumerRetryBackoff(c.RetryBackoff, c.RetryMaxBackoff),
+		kafka.ConsumerBatch(c.BatchSize, c.BatchWait),
+	)
+	app.Health().AddLivenessCheck(name, app.consumerLiveness(consumer))
+
+	return consumer
+}
+
+// consumerHook регистрирует консьюмер в lifecycle. Дообработка прочитанных сообщений
+// при остановке ограничена kafka.consumer.drain_timeout.
+func (app *App) consumerHook(name string, c consumer) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name:    name,
+		OnStart: c.Run,
+		OnStop: func(ctx context.Context) error {
+			if d := app.cfg.Kafka.Consumer.DrainTimeout; d > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, d)
+				defer cancel()
+			}
+			return c.Shutdown(ctx)
+		},
+	}
+}
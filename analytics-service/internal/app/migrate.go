@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"flag"
+
+	"github.com/4udiwe/big-bob-pizza/analytics-service/internal/database"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/migrate"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
+	"github.com/labstack/gommon/log"
+)
+
+// Serve разбирает флаги подкоманды serve и запускает сервис.
+func (app *App) Serve(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	autoMigrate := fs.Bool("auto-migrate", false, "apply pending migrations on startup (for local development)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	app.autoMigrate = *autoMigrate
+	app.Start()
+	return nil
+}
+
+// Migrate выполняет подкоманду migrate со встроенными миграциями сервиса.
+// args — аргументы после "migrate" (см. migrate.Command).
+func (app *App) Migrate(ctx context.Context, args []string) error {
+	cmd := migrate.Command{
+		Migrations: database.Migrations(),
+		Dir:        database.SourceDir,
+		Connect: func(ctx context.Context) (*postgres.Postgres, error) {
+			return postgres.New(app.cfg.Postgres.URL, postgres.ConnAttempts(5))
+		},
+	}
+	return cmd.Run(ctx, args)
+}
+
+// checkSchema при -auto-migrate применяет миграции, а затем останавливает запуск,
+// если схема БД отстаёт от миграций в бинарнике.
+func (app *App) checkSchema(ctx context.Context) {
+	m, err := migrate.New(app.postgres.Pool, database.Migrations())
+	if err != nil {
+		log.Fatalf("app - Start - migrate.New: %v", err)
+	}
+	defer m.Close()
+
+	if app.autoMigrate {
+		if err := m.Up(ctx); err != nil {
+			log.Fatalf("app - Start - Migrations failed: %v", err)
+		}
+	}
+
+	if err := m.Check(ctx); err != nil {
+		log.Fatalf("app - Start - %v", err)
+	}
+}
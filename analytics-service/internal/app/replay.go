@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	consumer_order "github.com/4udiwe/big-bob-pizza/analytics-service/internal/consumer/order"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/replay"
+)
+
+// Replay повторно прогоняет события через обработчики консьюмеров сервиса.
+// args — аргументы командной строки replay (см. replay.Command).
+// Обработчик analytics идемпотентен по eventId, поэтому повторный прогон безопасен.
+func (app *App) Replay(ctx context.Context, args []string) error {
+	pg, err := postgres.New(app.cfg.Postgres.URL, postgres.ConnAttempts(5))
+	if err != nil {
+		return fmt.Errorf("app - Replay - postgres.New: %w", err)
+	}
+	app.postgres = pg
+
+	defer pg.Close()
+
+	// Kafka-консьюмер не нужен: события передаются напрямую в обработчик.
+	orderConsumer := consumer_order.New(app.AnalyticsService(), nil, app.cfg.Kafka.Topics.OrderEvents, "")
+
+	cmd := &replay.Command{
+		Brokers: app.cfg.Kafka.Brokers,
+		Targets: map[string]replay.Target{
+			"order": {Topic: app.cfg.Kafka.Topics.OrderEvents, Handler: orderConsumer.HandleBatch},
+		},
+	}
+
+	return cmd.Run(ctx, args)
+}
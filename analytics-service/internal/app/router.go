@@ -2,9 +2,10 @@ package app
 
 import (
 	"fmt"
-	"net/http"
 
-	"github.com/4udiwe/subscription-service/pkg/validator"
+	mw "github.com/4udiwe/big-bob-pizza/order-service/pkg/middleware"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/problem"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/validator"
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -17,6 +18,14 @@ func (app *App) EchoHandler() *echo.Echo {
 
 	handler := echo.New()
 	handler.Validator = validator.NewCustomValidator()
+	handler.HTTPErrorHandler = problem.New().Handle
+
+	mw.Install(handler,
+		mw.BodyLimit(app.cfg.HTTP.BodyLimit),
+		mw.RequestTimeout(app.cfg.HTTP.RequestTimeout),
+		mw.RouteTimeouts(app.cfg.HTTP.RouteTimeouts),
+		mw.SkipLog("/health", "/livez", "/readyz", app.cfg.Prometheus.Path),
+	)
 
 	app.configureRouter(handler)
 
@@ -36,7 +45,10 @@ func (app *App) configureRouter(handler *echo.Echo) {
 		analyticsGroup.GET("/orders/:orderId/events", app.GetOrderEventsHandler().Handle)
 	}
 
-	handler.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	// /health оставлен для совместимости и равносилен /livez.
+	handler.GET("/health", app.Health().Live)
+	handler.GET("/livez", app.Health().Live)
+	handler.GET("/readyz", app.Health().Ready)
 
 	// Prometheus metrics endpoint
 	if app.cfg.Prometheus.Enabled {
@@ -5,6 +5,12 @@ import (
 )
 
 func (app *App) AnalyticsService() *analytics.Service {
+	if app.analyticsService != nil {
+		return app.analyticsService
+	}
+	app.analyticsService = analytics.NewService(
+		app.OrderEventRepo(),
+		analytics.NewMetrics(),
+	)
 	return app.analyticsService
 }
-
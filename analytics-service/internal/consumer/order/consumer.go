@@ -2,12 +2,12 @@ package consumer_order
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/4udiwe/big-bob-pizza/analytics-service/internal/entity"
 	"github.com/4udiwe/big-bob-pizza/analytics-service/internal/service/analytics"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
-	"github.com/google/uuid"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
 	"github.com/sirupsen/logrus"
 )
 
@@ -36,159 +36,75 @@ func New(
 func (c *Consumer) Run(ctx context.Context) error {
 	logrus.Infof("OrderAnalyticsConsumer: subscribing to topic=%s group=%s", c.topic, c.groupID)
 
-	return c.consumer.Subscribe(ctx, c.topic, c.groupID, func(ctx context.Context, key, value []byte) error {
-		// Парсим envelope
-		var env kafka.Envelope
-		if err := json.Unmarshal(value, &env); err != nil {
-			logrus.Errorf("OrderAnalyticsConsumer: failed to parse envelope: %v", err)
-			return nil
-		}
-
-		// Обрабатываем только нужные события
-		switch env.EventType {
-		case "order.created":
-			return c.handleOrderCreated(ctx, env)
-		case "order.paid":
-			return c.handleOrderPaid(ctx, env)
-		case "order.cancelled":
-			return c.handleOrderCancelled(ctx, env)
-		case "order.completed":
-			return c.handleOrderCompleted(ctx, env)
-		default:
-			// Игнорируем другие события
-			return nil
-		}
-	})
+	return c.consumer.SubscribeBatch(ctx, c.topic, c.groupID, c.HandleBatch)
 }
 
-func (c *Consumer) handleOrderCreated(ctx context.Context, env kafka.Envelope) error {
-	var payload struct {
-		OrderID    uuid.UUID `json:"orderId"`
-		UserID     uuid.UUID `json:"userId"`
-		TotalPrice float64   `json:"totalPrice"`
-	}
-
-	if env.Data == nil {
-		logrus.Errorf("OrderAnalyticsConsumer: empty data for event %s", env.EventType)
-		return nil
-	}
-
-	if err := json.Unmarshal(env.Data, &payload); err != nil {
-		logrus.Errorf("OrderAnalyticsConsumer: failed to parse payload: %v", err)
-		return nil
-	}
-
-	event := entity.OrderEvent{
-		EventID:    env.EventID,
-		EventType:  "order.created",
-		OrderID:    payload.OrderID,
-		UserID:     &payload.UserID,
-		Amount:     &payload.TotalPrice,
-		OccurredAt: env.OccurredAt,
-	}
-
-	if err := c.analyticsService.SaveOrderEvent(ctx, event); err != nil {
-		logrus.Errorf("OrderAnalyticsConsumer: failed to save order.created event: %v", err)
-		return err
-	}
-
-	logrus.Infof("OrderAnalyticsConsumer: processed order.created orderID=%s", payload.OrderID)
-	return nil
+// Shutdown прекращает чтение топика и дожидается сохранения уже прочитанных событий.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	return c.consumer.Shutdown(ctx)
 }
 
-func (c *Consumer) handleOrderPaid(ctx context.Context, env kafka.Envelope) error {
-	var payload struct {
-		OrderID   uuid.UUID `json:"orderId"`
-		PaymentID uuid.UUID `json:"paymentId"`
-	}
+// HandleBatch сохраняет пачку событий одним запросом.
+// Битые сообщения и события, не нужные аналитике, пропускаются.
+func (c *Consumer) HandleBatch(ctx context.Context, msgs []kafka.Message) error {
+	batch := make([]entity.OrderEvent, 0, len(msgs))
 
-	if env.Data == nil {
-		logrus.Errorf("OrderAnalyticsConsumer: empty data for event %s", env.EventType)
-		return nil
-	}
-
-	if err := json.Unmarshal(env.Data, &payload); err != nil {
-		logrus.Errorf("OrderAnalyticsConsumer: failed to parse payload: %v", err)
-		return nil
-	}
-
-	event := entity.OrderEvent{
-		EventID:    env.EventID,
-		EventType:  "order.paid",
-		OrderID:    payload.OrderID,
-		PaymentID:  &payload.PaymentID,
-		OccurredAt: env.OccurredAt,
-	}
-
-	if err := c.analyticsService.SaveOrderEvent(ctx, event); err != nil {
-		logrus.Errorf("OrderAnalyticsConsumer: failed to save order.paid event: %v", err)
-		return err
-	}
-
-	logrus.Infof("OrderAnalyticsConsumer: processed order.paid orderID=%s", payload.OrderID)
-	return nil
-}
+	for _, m := range msgs {
+		env, err := kafka.DecodeEnvelope(m.Value, schema.Default())
+		if err != nil {
+			logrus.Errorf("OrderAnalyticsConsumer: failed to parse envelope: %v", err)
+			continue
+		}
 
-func (c *Consumer) handleOrderCancelled(ctx context.Context, env kafka.Envelope) error {
-	var payload struct {
-		OrderID uuid.UUID `json:"orderId"`
-		Reason  string    `json:"reason"`
-	}
+		ev, err := events.Decode(env.EventType, env.Data)
+		if err != nil {
+			logrus.Errorf("OrderAnalyticsConsumer: failed to parse payload: %v", err)
+			continue
+		}
 
-	if env.Data == nil {
-		logrus.Errorf("OrderAnalyticsConsumer: empty data for event %s", env.EventType)
-		return nil
+		if event, ok := toOrderEvent(env, ev); ok {
+			batch = append(batch, event)
+		}
 	}
 
-	if err := json.Unmarshal(env.Data, &payload); err != nil {
-		logrus.Errorf("OrderAnalyticsConsumer: failed to parse payload: %v", err)
+	if len(batch) == 0 {
 		return nil
 	}
 
-	event := entity.OrderEvent{
-		EventID:    env.EventID,
-		EventType:  "order.cancelled",
-		OrderID:    payload.OrderID,
-		Reason:     &payload.Reason,
-		OccurredAt: env.OccurredAt,
-	}
-
-	if err := c.analyticsService.SaveOrderEvent(ctx, event); err != nil {
-		logrus.Errorf("OrderAnalyticsConsumer: failed to save order.cancelled event: %v", err)
+	if err := c.analyticsService.SaveOrderEvents(ctx, batch); err != nil {
+		logrus.Errorf("OrderAnalyticsConsumer: failed to save %d events: %v", len(batch), err)
 		return err
 	}
 
-	logrus.Infof("OrderAnalyticsConsumer: processed order.cancelled orderID=%s", payload.OrderID)
+	logrus.Infof("OrderAnalyticsConsumer: processed batch of %d events", len(batch))
 	return nil
 }
 
-func (c *Consumer) handleOrderCompleted(ctx context.Context, env kafka.Envelope) error {
-	var payload struct {
-		OrderID uuid.UUID `json:"orderId"`
-	}
-
-	if env.Data == nil {
-		logrus.Errorf("OrderAnalyticsConsumer: empty data for event %s", env.EventType)
-		return nil
-	}
-
-	if err := json.Unmarshal(env.Data, &payload); err != nil {
-		logrus.Errorf("OrderAnalyticsConsumer: failed to parse payload: %v", err)
-		return nil
-	}
-
+// toOrderEvent переводит событие заказа в запись аналитики.
+// Возвращает false для событий, которые аналитика не хранит.
+func toOrderEvent(env kafka.Envelope, ev events.Event) (entity.OrderEvent, bool) {
 	event := entity.OrderEvent{
 		EventID:    env.EventID,
-		EventType:  "order.completed",
-		OrderID:    payload.OrderID,
+		EventType:  ev.EventType(),
 		OccurredAt: env.OccurredAt,
 	}
 
-	if err := c.analyticsService.SaveOrderEvent(ctx, event); err != nil {
-		logrus.Errorf("OrderAnalyticsConsumer: failed to save order.completed event: %v", err)
-		return err
-	}
-
-	logrus.Infof("OrderAnalyticsConsumer: processed order.completed orderID=%s", payload.OrderID)
-	return nil
+	switch p := ev.(type) {
+	case *events.OrderCreated:
+		event.OrderID = p.OrderID
+		event.UserID = &p.UserID
+		event.Amount = &p.TotalPrice
+	case *events.OrderPaid:
+		event.OrderID = p.OrderID
+		event.PaymentID = &p.PaymentID
+	case *events.OrderCancelled:
+		event.OrderID = p.OrderID
+		event.Reason = &p.Reason
+	case *events.OrderCompleted:
+		event.OrderID = p.OrderID
+	default:
+		return entity.OrderEvent{}, false
+	}
+
+	return event, true
 }
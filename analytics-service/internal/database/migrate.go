@@ -2,74 +2,35 @@ package database
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
-	"log"
-	"os"
+	"embed"
+	"io/fs"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/migrate"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/jackc/pgx/v5/stdlib"
-	"github.com/pressly/goose/v3"
 )
 
-func RunMigrations(ctx context.Context, pool *pgxpool.Pool) error {
-	db, err := pgxPoolToStdlib(ctx, pool)
-	if err != nil {
-		return fmt.Errorf("failed to convert pool: %w", err)
-	}
-
-	if err := goose.SetDialect("postgres"); err != nil {
-		return fmt.Errorf("failed to set dialect: %w", err)
-	}
-
-	// Try both paths: for production (database/migrations) and tests (internal/database/migrations)
-	cwd, _ := os.Getwd()
+// SourceDir — каталог исходников миграций относительно корня модуля, для `migrate create`.
+const SourceDir = "internal/database/migrations"
 
-	paths := []string{
-		"/app/database/migrations",
-		"/app/internal/database/migrations",
-		"database/migrations",
-		"internal/database/migrations",
-	}
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
 
-	var migrationPath string
-	var found bool
-	for _, path := range paths {
-		if info, err := os.Stat(path); err == nil && info.IsDir() {
-			entries, err := os.ReadDir(path)
-			if err == nil && len(entries) > 0 {
-				migrationPath = path
-				found = true
-				log.Printf("Found migrations at: %s (cwd: %s)", migrationPath, cwd)
-				break
-			}
-		}
-	}
-
-	if !found {
-		return fmt.Errorf("migration directory not found. Tried paths: %v (cwd: %s)", paths, cwd)
-	}
-
-	if err := goose.Up(db, migrationPath); err != nil {
-		return fmt.Errorf("failed to apply migrations: %w", err)
-	}
-
-	version, err := goose.GetDBVersion(db)
+// Migrations возвращает встроенные в бинарник миграции сервиса.
+func Migrations() fs.FS {
+	sub, err := fs.Sub(migrationFiles, "migrations")
 	if err != nil {
-		return fmt.Errorf("failed to get DB version: %w", err)
+		panic(err)
 	}
-	log.Printf("Migrations applied. Current version: %d", version)
-
-	return nil
+	return sub
 }
 
-func pgxPoolToStdlib(ctx context.Context, pool *pgxpool.Pool) (*sql.DB, error) {
-	conn, err := pool.Acquire(ctx)
+// RunMigrations применяет все неприменённые миграции сервиса.
+func RunMigrations(ctx context.Context, pool *pgxpool.Pool) error {
+	m, err := migrate.New(pool, Migrations())
 	if err != nil {
-		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+		return err
 	}
-	defer conn.Release()
+	defer m.Close()
 
-	db := stdlib.OpenDB(*conn.Conn().Config())
-	return db, nil
+	return m.Up(ctx)
 }
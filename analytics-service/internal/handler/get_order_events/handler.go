@@ -43,7 +43,7 @@ func (h *handler) Handle(c echo.Context) error {
 
 	events, err := h.s.GetOrderEvents(c.Request().Context(), orderID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := OrderEventsResponse{
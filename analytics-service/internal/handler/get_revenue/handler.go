@@ -57,7 +57,7 @@ func (h *handler) Handle(c echo.Context) error {
 
 	revenue, err := h.s.GetRevenue(c.Request().Context(), startDate, endDate)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := RevenueResponse{
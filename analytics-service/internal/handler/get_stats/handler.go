@@ -58,7 +58,7 @@ func (h *handler) Handle(c echo.Context) error {
 
 	stats, err := h.s.GetStats(c.Request().Context(), startDate, endDate)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := StatsResponse{
@@ -10,6 +10,7 @@ import (
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/sirupsen/logrus"
 )
@@ -24,6 +25,8 @@ func New(pg *postgres.Postgres) *Repository {
 
 // Save сохраняет событие заказа. Если событие с таким event_id уже существует, возвращает nil (идемпотентность)
 func (r *Repository) Save(ctx context.Context, event entity.OrderEvent) error {
+	ctx = postgres.WithOperation(ctx, "OrderEventRepository.Save")
+
 	logrus.Infof("OrderEventRepository.Save: eventType=%s orderID=%s eventID=%s", event.EventType, event.OrderID, event.EventID)
 
 	var dto OrderEventDTO
@@ -82,8 +85,67 @@ func (r *Repository) Save(ctx context.Context, event entity.OrderEvent) error {
 	return nil
 }
 
+// SaveBatch сохраняет пачку событий одним INSERT. События, чей event_id уже есть в таблице,
+// пропускаются (идемпотентность). Возвращает только действительно сохранённые события.
+func (r *Repository) SaveBatch(ctx context.Context, events []entity.OrderEvent) ([]entity.OrderEvent, error) {
+	ctx = postgres.WithOperation(ctx, "OrderEventRepository.SaveBatch")
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	logrus.Infof("OrderEventRepository.SaveBatch: count=%d", len(events))
+
+	now := time.Now()
+	builder := r.Builder.
+		Insert("order_events").
+		Columns("id", "event_id", "event_type", "order_id", "user_id", "amount", "payment_id", "reason", "occurred_at", "created_at")
+
+	for _, e := range events {
+		builder = builder.Values(uuid.New(), e.EventID, e.EventType, e.OrderID, e.UserID, e.Amount, e.PaymentID, e.Reason, e.OccurredAt, now)
+	}
+
+	query, args, err := builder.
+		Suffix("ON CONFLICT (event_id) DO NOTHING RETURNING event_id").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.GetTxManager(ctx).Query(ctx, query, args...)
+	if err != nil {
+		logrus.Errorf("OrderEventRepository.SaveBatch: insert error: %v", err)
+		return nil, err
+	}
+
+	inserted, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		logrus.Errorf("OrderEventRepository.SaveBatch: scan error: %v", err)
+		return nil, err
+	}
+
+	insertedIDs := make(map[uuid.UUID]bool, len(inserted))
+	for _, id := range inserted {
+		insertedIDs[id] = true
+	}
+
+	saved := make([]entity.OrderEvent, 0, len(inserted))
+	for _, e := range events {
+		if insertedIDs[e.EventID] {
+			saved = append(saved, e)
+			// В пачке могут быть дубли одного события — сохраняем его один раз.
+			delete(insertedIDs, e.EventID)
+		}
+	}
+
+	logrus.Infof("OrderEventRepository.SaveBatch: saved=%d", len(saved))
+	return saved, nil
+}
+
 // GetByOrderID возвращает все события для заказа
 func (r *Repository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]entity.OrderEvent, error) {
+	ctx = postgres.WithOperation(ctx, "OrderEventRepository.GetByOrderID")
+
 	query := `
 		SELECT id, event_id, event_type, order_id, user_id, amount, payment_id, reason, occurred_at, created_at
 		FROM order_events
@@ -126,6 +188,8 @@ func (r *Repository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]ent
 
 // GetStatsByDateRange возвращает статистику за период
 func (r *Repository) GetStatsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]OrderStats, error) {
+	ctx = postgres.WithOperation(ctx, "OrderEventRepository.GetStatsByDateRange")
+
 	query := `
 		SELECT 
 			DATE_TRUNC('day', occurred_at) AS date,
@@ -176,6 +240,8 @@ func (r *Repository) GetStatsByDateRange(ctx context.Context, startDate, endDate
 
 // GetTotalRevenue возвращает общую выручку за период
 func (r *Repository) GetTotalRevenue(ctx context.Context, startDate, endDate time.Time) (float64, error) {
+	ctx = postgres.WithOperation(ctx, "OrderEventRepository.GetTotalRevenue")
+
 	query := `
 		SELECT COALESCE(SUM(amount), 0)
 		FROM order_events
@@ -14,6 +14,7 @@ import (
 
 type OrderEventRepo interface {
 	Save(ctx context.Context, event entity.OrderEvent) error
+	SaveBatch(ctx context.Context, events []entity.OrderEvent) ([]entity.OrderEvent, error)
 	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]entity.OrderEvent, error)
 	GetStatsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]order_event_repo.OrderStats, error)
 	GetTotalRevenue(ctx context.Context, startDate, endDate time.Time) (float64, error)
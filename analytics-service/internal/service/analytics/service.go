@@ -30,6 +30,29 @@ func (s *Service) SaveOrderEvent(ctx context.Context, event entity.OrderEvent) e
 	return nil
 }
 
+// SaveOrderEvents сохраняет пачку событий заказа одним запросом.
+// Метрики обновляются только для новых событий: повторно доставленные пропускаются по event_id.
+func (s *Service) SaveOrderEvents(ctx context.Context, events []entity.OrderEvent) error {
+	logrus.Infof("AnalyticsService.SaveOrderEvents: count=%d", len(events))
+
+	saved, err := s.OrderEventRepo.SaveBatch(ctx, events)
+	if err != nil {
+		logrus.Errorf("AnalyticsService.SaveOrderEvents: failed to save events: %v", err)
+		return err
+	}
+
+	for _, event := range saved {
+		s.Metrics.RecordOrderEvent(event.EventType)
+
+		if event.Amount != nil {
+			s.Metrics.RecordOrderAmount(*event.Amount, event.EventType)
+		}
+	}
+
+	logrus.Infof("AnalyticsService.SaveOrderEvents: saved=%d duplicates=%d", len(saved), len(events)-len(saved))
+	return nil
+}
+
 // GetOrderEvents возвращает все события для заказа
 func (s *Service) GetOrderEvents(ctx context.Context, orderID uuid.UUID) ([]entity.OrderEvent, error) {
 	logrus.Infof("AnalyticsService.GetOrderEvents: orderID=%s", orderID)
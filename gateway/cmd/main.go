@@ -1,12 +1,39 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/4udiwe/big-bob-pizza/gateway/internal/app"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/configloader"
 )
 
+const usage = `usage: gateway [command]
+
+commands:
+  serve                          run the gateway (default)
+  config print [--redacted]      print the effective configuration, --redacted masks secrets`
+
 func main() {
-	application := app.New(os.Getenv("CONFIG_PATH"))
-	application.Start()
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		application := app.New(os.Getenv("CONFIG_PATH"))
+		application.Start()
+	case "config":
+		err := configloader.Command(os.Stdout, args, func() (any, error) {
+			return app.LoadConfig(os.Getenv("CONFIG_PATH"))
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", command, usage)
+		os.Exit(2)
+	}
 }
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/configloader"
+)
+
+// defaultConfigPath — конфиг, который Dockerfile кладёт в образ.
+const defaultConfigPath = "/config/config.yaml"
+
+type Config struct {
+	App struct {
+		Name string `yaml:"name" env:"APP_NAME" validate:"required"`
+	} `yaml:"app"`
+	HTTP struct {
+		Port string `yaml:"port" env:"SERVER_PORT" validate:"required,numeric"`
+	} `yaml:"http"`
+	Logger struct {
+		Level string `yaml:"level" env:"LOG_LEVEL" validate:"required,oneof=panic fatal error warn warning info debug trace"`
+	} `yaml:"logger"`
+	Upstreams struct {
+		Order     string `yaml:"order" env:"ORDER_URL" validate:"required,http_url"`
+		Payment   string `yaml:"payment" env:"PAYMENT_URL" validate:"required,http_url"`
+		Analytics string `yaml:"analytics" env:"ANALYTICS_URL" validate:"required,http_url"`
+		Menu      string `yaml:"menu" env:"MENU_URL" validate:"required,http_url"`
+	} `yaml:"upstreams"`
+}
+
+// LoadConfig загружает конфигурацию gateway из YAML-файла и переменных окружения
+// (см. configloader.Load). Пустой путь — defaultConfigPath.
+func LoadConfig(configPath string) (*Config, error) {
+	if configPath == "" {
+		configPath = defaultConfigPath
+	}
+
+	cfg := &Config{}
+	if err := configloader.Load(configPath, cfg); err != nil {
+		return nil, fmt.Errorf("app - LoadConfig - configloader.Load: %w", err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// upstreamCheckTimeout ограничивает проверку одного upstream в /readyz.
+	upstreamCheckTimeout = time.Second
+	// readyzCacheTTL — сколько переиспользуется отчёт /readyz, как CacheTTL в order-service/pkg/health.
+	readyzCacheTTL = time.Second
+)
+
+// Статусы проб — те же, что у сервисов (order-service/pkg/health).
+const (
+	statusOK       = "ok"
+	statusDegraded = "degraded"
+)
+
+type checkResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type healthReport struct {
+	Status    string        `json:"status"`
+	CheckedAt time.Time     `json:"checkedAt"`
+	Checks    []checkResult `json:"checks"`
+}
+
+// livez — процесс gateway жив: своих зависимостей у него нет.
+func livez(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, &healthReport{Status: statusOK, CheckedAt: time.Now().UTC(), Checks: []checkResult{}})
+}
+
+// readyz проверяет /readyz каждого upstream. Неготовый upstream переводит отчёт в degraded,
+// но gateway остаётся готовым: маршруты остальных сервисов продолжают работать, а запросы
+// к недоступному получают 502.
+//
+// Отчёт кэшируется на readyzCacheTTL, мьютекс держится на время проверок: частые пробы
+// не размножаются в запросы к каждому upstream.
+func readyz(upstreams map[string]string) http.HandlerFunc {
+	client := &http.Client{Timeout: upstreamCheckTimeout}
+
+	var (
+		mu     sync.Mutex
+		cached *healthReport
+	)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if cached != nil && time.Since(cached.CheckedAt) < readyzCacheTTL {
+			writeReport(w, cached)
+			return
+		}
+
+		// Результат проверки попадёт в кэш, поэтому она не обрывается вместе с запросом пробы.
+		ctx := context.WithoutCancel(r.Context())
+
+		names := make([]string, 0, len(upstreams))
+		for name := range upstreams {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		results := make([]checkResult, len(names))
+		var wg sync.WaitGroup
+		for i, name := range names {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = checkUpstream(ctx, client, name, upstreams[name])
+			}()
+		}
+		wg.Wait()
+
+		report := &healthReport{Status: statusOK, CheckedAt: time.Now().UTC(), Checks: results}
+		for _, res := range results {
+			if res.Status != statusOK {
+				report.Status = statusDegraded
+			}
+		}
+
+		cached = report
+		writeReport(w, report)
+	}
+}
+
+func checkUpstream(ctx context.Context, client *http.Client, name, target string) checkResult {
+	start := time.Now()
+	res := checkResult{Name: name, Status: statusOK}
+
+	err := func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target+"/readyz", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	}()
+
+	res.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		res.Status = statusDegraded
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+func writeReport(w http.ResponseWriter, report *healthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -1,12 +1,15 @@
 package app
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"io"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -30,6 +33,32 @@ func (r *statusRecorder) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// requestIDHeader — заголовок с ID запроса. Gateway проставляет его, если клиент не прислал свой,
+// передаёт во внутренние сервисы и возвращает клиенту: по нему связываются логи gateway и сервисов.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen — более длинный ID от клиента заменяется своим.
+const maxRequestIDLen = 128
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" || len(id) > maxRequestIDLen {
+			id = newRequestID()
+			r.Header.Set(requestIDHeader, id)
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -38,12 +67,13 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(rec, r)
 
 		log.WithFields(log.Fields{
-			"method":   r.Method,
-			"path":     r.URL.Path,
-			"remote":   r.RemoteAddr,
-			"status":   rec.status,
-			"size":     rec.size,
-			"duration": time.Since(start).String(),
+			"request_id": r.Header.Get(requestIDHeader),
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"remote":     r.RemoteAddr,
+			"status":     rec.status,
+			"size":       rec.size,
+			"duration":   time.Since(start).String(),
 		}).Info("request completed")
 	})
 }
@@ -74,10 +104,18 @@ func newReverseProxy(target string) *httputil.ReverseProxy {
 func NewRouter(cfg Config) http.Handler {
 	mux := http.NewServeMux()
 
-	// health
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
+	// health: /health оставлен для совместимости и равносилен /livez
+	mux.HandleFunc("/health", livez)
+	mux.HandleFunc("/livez", livez)
+	mux.HandleFunc("/readyz", readyz(map[string]string{
+		"order":     cfg.Upstreams.Order,
+		"payment":   cfg.Upstreams.Payment,
+		"analytics": cfg.Upstreams.Analytics,
+		"menu":      cfg.Upstreams.Menu,
+	}))
+
+	// metrics
+	mux.Handle("/metrics", promhttp.Handler())
 
 	// proxies
 	orderProxy := newReverseProxy(cfg.Upstreams.Order)
@@ -101,5 +139,5 @@ func NewRouter(cfg Config) http.Handler {
 		http.NotFound(w, r)
 	})
 
-	return loggingMiddleware(mux)
+	return requestIDMiddleware(loggingMiddleware(mux))
 }
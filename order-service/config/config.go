@@ -1,61 +1,121 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/configloader"
 )
 
 type (
 	Config struct {
-		App      App      `yaml:"app"`
-		HTTP     HTTP     `yaml:"http"`
-		Postgres Postgres `yaml:"postgres"`
-		Log      Log      `yaml:"logger"`
-		Redis    Redis    `yaml:"redis"`
-		Kafka    Kafka    `yaml:"kafka"`
-		Outbox   Outbox   `yaml:"outbox"`
+		App        App        `yaml:"app"`
+		HTTP       HTTP       `yaml:"http"`
+		Health     Health     `yaml:"health"`
+		Postgres   Postgres   `yaml:"postgres"`
+		Log        Log        `yaml:"logger"`
+		Redis      Redis      `yaml:"redis"`
+		Kafka      Kafka      `yaml:"kafka"`
+		Outbox     Outbox     `yaml:"outbox"`
+		Prometheus Prometheus `yaml:"prometheus"`
+		Admin      Admin      `yaml:"admin"`
 	}
 
 	App struct {
-		Name    string `env-required:"true" yaml:"name" env:"APP_NAME"`
-		Version string `env-required:"true" yaml:"version" env:"APP_VERSION"`
+		Name    string `yaml:"name" env:"APP_NAME" validate:"required"`
+		Version string `yaml:"version" env:"APP_VERSION" validate:"required"`
+		// ShutdownTimeout — общий бюджет остановки: HTTP, консьюмеры и воркеры дообрабатывают
+		// начатую работу, после чего закрываются пулы.
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"APP_SHUTDOWN_TIMEOUT"`
 	}
 
 	HTTP struct {
-		Port string `env-required:"true" yaml:"port" env:"SERVER_PORT"`
+		Port string `yaml:"port" env:"SERVER_PORT" validate:"required,numeric"`
+		// BodyLimit — максимальный размер тела запроса ("1M"); больше — 413.
+		BodyLimit      string        `yaml:"body_limit" env:"HTTP_BODY_LIMIT"`
+		RequestTimeout time.Duration `yaml:"request_timeout" env:"HTTP_REQUEST_TIMEOUT"`
+		// RouteTimeouts переопределяет RequestTimeout для маршрутов: "POST /admin/outbox/republish": 4s.
+		RouteTimeouts map[string]time.Duration `yaml:"route_timeouts"`
+	}
+
+	// Health — пробы /livez и /readyz.
+	Health struct {
+		CacheTTL     time.Duration `yaml:"cache_ttl" env:"HEALTH_CACHE_TTL"`
+		CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+		// MaxPollAge — консьюмер, чей цикл чтения дольше не обращался к брокеру, считается зависшим.
+		MaxPollAge time.Duration `yaml:"max_poll_age" env:"HEALTH_MAX_POLL_AGE"`
+		// DrainDelay — сколько после SIGTERM сервис отвечает «не готов», продолжая принимать запросы.
+		DrainDelay time.Duration `yaml:"drain_delay" env:"HEALTH_DRAIN_DELAY"`
 	}
 
 	Log struct {
-		Level string `env-required:"true" yaml:"level" env:"LOG_LEVEL"`
+		Level string `yaml:"level" env:"LOG_LEVEL" validate:"required,oneof=panic fatal error warn warning info debug trace"`
 	}
 
 	Postgres struct {
-		URL            string        `env-required:"true" yaml:"url" env:"POSTGRES_URL"`
-		ConnectTimeout time.Duration `env-required:"true" yaml:"connect_timeout" env:"POSTGRES_CONNECT_TIMEOUT"`
+		URL            string        `yaml:"url" env:"POSTGRES_URL" validate:"required" secret:"true"`
+		ConnectTimeout time.Duration `yaml:"connect_timeout" env:"POSTGRES_CONNECT_TIMEOUT" validate:"required"`
+		// ReplicaURLs — DSN реплик для чтения вне транзакций; пусто — всё читается с primary.
+		ReplicaURLs   []string      `yaml:"replica_urls" env:"POSTGRES_REPLICA_URLS" secret:"true"`
+		MaxReplicaLag time.Duration `yaml:"max_replica_lag" env:"POSTGRES_MAX_REPLICA_LAG"`
+		// SlowQueryThreshold — запросы дольше этого пишутся в лог как медленные.
+		SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"POSTGRES_SLOW_QUERY_THRESHOLD"`
 	}
 
 	Redis struct {
-		Addr     string `env-required:"true" yaml:"addr" env:"REDIS_ADDR"`
+		// Mode — "standalone" (по умолчанию), "sentinel" или "cluster".
+		Mode string `yaml:"mode" env:"REDIS_MODE" validate:"omitempty,oneof=standalone sentinel cluster"`
+		// Addrs — адрес узла, адреса sentinel-узлов или узлов кластера, в зависимости от Mode.
+		Addrs      []string `yaml:"addrs" env:"REDIS_ADDRS" validate:"required,dive,hostname_port"`
+		MasterName string   `yaml:"master_name" env:"REDIS_MASTER_NAME"`
+		Username   string   `yaml:"username" env:"REDIS_USERNAME"`
+		Password   string   `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+		// SentinelPassword — пароль sentinel-узлов, если у них включена авторизация.
+		SentinelPassword string        `yaml:"sentinel_password" env:"REDIS_SENTINEL_PASSWORD" secret:"true"`
+		DB               int           `yaml:"db" env:"REDIS_DB"`
+		PoolSize         int           `yaml:"pool_size" env:"REDIS_POOL_SIZE"`
+		CommandTimeout   time.Duration `yaml:"command_timeout" env:"REDIS_COMMAND_TIMEOUT"`
+		// KeyPrefix добавляется ко всем ключам кэша: "<prefix>:order:<id>".
+		KeyPrefix string       `yaml:"key_prefix" env:"REDIS_KEY_PREFIX"`
+		TLS       RedisTLS     `yaml:"tls"`
+		Breaker   RedisBreaker `yaml:"breaker"`
+	}
+
+	RedisTLS struct {
+		Enabled bool `yaml:"enabled" env:"REDIS_TLS_ENABLED"`
+		// CAFile — CA для проверки сертификата сервера; пусто — системные корневые сертификаты.
+		CAFile     string `yaml:"ca_file" env:"REDIS_TLS_CA_FILE"`
+		ServerName string `yaml:"server_name" env:"REDIS_TLS_SERVER_NAME"`
+	}
+
+	// RedisBreaker — circuit breaker: после FailureThreshold подряд сетевых ошибок кэш отключается
+	// на OpenTimeout, и заказы читаются из Postgres.
+	RedisBreaker struct {
+		FailureThreshold int           `yaml:"failure_threshold" env:"REDIS_BREAKER_FAILURE_THRESHOLD"`
+		OpenTimeout      time.Duration `yaml:"open_timeout" env:"REDIS_BREAKER_OPEN_TIMEOUT"`
 	}
 
 	Kafka struct {
-		Brokers []string `env-required:"true" yaml:"brokers" env:"KAFKA_BROKERS"`
+		Brokers []string `yaml:"brokers" env:"KAFKA_BROKERS" validate:"required,dive,hostname_port"`
 		Topics  struct {
-			PaymentEvents  string `env-required:"true" yaml:"payment_events"`
-			KitchenEvents  string `env-required:"true" yaml:"kitchen_events"`
-			DeliveryEvents string `env-required:"true" yaml:"delivery_events"`
-		} `env-required:"true" yaml:"topics" env:"KAFKA_TOPICS"`
+			PaymentEvents  string `yaml:"payment_events" validate:"required"`
+			KitchenEvents  string `yaml:"kitchen_events" validate:"required"`
+			DeliveryEvents string `yaml:"delivery_events" validate:"required"`
+		} `yaml:"topics" env:"KAFKA_TOPICS"`
 		Producer KafkaProducer `yaml:"producer"`
 		Consumer KafkaConsumer `yaml:"consumer"`
 	}
 
 	KafkaProducer struct {
-		RequiredAcks int           `yaml:"required_acks" env:"KAFKA_PRODUCER_REQUIRED_ACKS"`
+		RequiredAcks int           `yaml:"required_acks" env:"KAFKA_PRODUCER_REQUIRED_ACKS" validate:"oneof=-1 0 1"`
 		BatchSize    int           `yaml:"batch_size" env:"KAFKA_PRODUCER_BATCH_SIZE"`
 		BatchTimeout time.Duration `yaml:"batch_timeout" env:"KAFKA_PRODUCER_BATCH_TIMEOUT"`
-		Compression  string        `yaml:"compression" env:"KAFKA_PRODUCER_COMPRESSION"`
+		Compression  string        `yaml:"compression" env:"KAFKA_PRODUCER_COMPRESSION" validate:"omitempty,oneof=none gzip snappy lz4 zstd"`
+		// Mode — "", "idempotent" или "transactional" (см. kafka.ProducerConfig).
+		Mode            string `yaml:"mode" env:"KAFKA_PRODUCER_MODE" validate:"omitempty,oneof=idempotent transactional"`
+		TransactionalID string `yaml:"transactional_id" env:"KAFKA_PRODUCER_TRANSACTIONAL_ID"`
 	}
 
 	KafkaConsumer struct {
@@ -64,27 +124,105 @@ type (
 		SessionTimeout    time.Duration `yaml:"session_timeout" env:"KAFKA_CONSUMER_SESSION_TIMEOUT"`
 		HeartbeatInterval time.Duration `yaml:"heartbeat_interval" env:"KAFKA_CONSUMER_HEARTBEAT_INTERVAL"`
 		CommitInterval    time.Duration `yaml:"commit_interval" env:"KAFKA_CONSUMER_COMMIT_INTERVAL"`
+		Concurrency       int           `yaml:"concurrency" env:"KAFKA_CONSUMER_CONCURRENCY"`
+		DrainTimeout      time.Duration `yaml:"drain_timeout" env:"KAFKA_CONSUMER_DRAIN_TIMEOUT"`
+		// RetryBackoff — пауза перед повтором пачки, на которой упал обработчик; удваивается
+		// до RetryMaxBackoff. Пока пачка не обработана, её offset не коммитятся.
+		RetryBackoff    time.Duration `yaml:"retry_backoff" env:"KAFKA_CONSUMER_RETRY_BACKOFF"`
+		RetryMaxBackoff time.Duration `yaml:"retry_max_backoff" env:"KAFKA_CONSUMER_RETRY_MAX_BACKOFF"`
 	}
 
 	Outbox struct {
-		Topic           string        `env-required:"true" yaml:"topic" env:"OUTBOX_PUB_TOPIC"`
-		BatchLimit      int           `env-required:"true" yaml:"batch_limit" env:"OUTBOX_BATCH_LIMIT"`
-		Interval        time.Duration `env-required:"true" yaml:"interval" env:"OUTBOX_INTERVAL"`
-		RequeBatchLimit int           `env-required:"true" yaml:"reque_batch_limit" env:"OUTBOX_REQUE_BATCH_LIMIT"`
-		RequeInterval   time.Duration `env-required:"true" yaml:"reque_interval" env:"OUTBOX_REQUE_INTERVAL"`
+		Topic           string        `yaml:"topic" env:"OUTBOX_PUB_TOPIC" validate:"required"`
+		BatchLimit      int           `yaml:"batch_limit" env:"OUTBOX_BATCH_LIMIT" validate:"required"`
+		Interval        time.Duration `yaml:"interval" env:"OUTBOX_INTERVAL" validate:"required"`
+		RequeBatchLimit int           `yaml:"reque_batch_limit" env:"OUTBOX_REQUE_BATCH_LIMIT" validate:"required"`
+		RequeInterval   time.Duration `yaml:"reque_interval" env:"OUTBOX_REQUE_INTERVAL" validate:"required"`
+		MaxAttempts     int           `yaml:"max_attempts" env:"OUTBOX_MAX_ATTEMPTS"`
+		RetryBackoff    time.Duration `yaml:"retry_backoff" env:"OUTBOX_RETRY_BACKOFF"`
+		RetryMaxBackoff time.Duration `yaml:"retry_max_backoff" env:"OUTBOX_RETRY_MAX_BACKOFF"`
+		Lease           time.Duration `yaml:"lease" env:"OUTBOX_LEASE"`
+		// RetentionMaxAge — через сколько после публикации processed-записи переносятся в outbox_archive.
+		// 0 отключает очистку.
+		RetentionMaxAge   time.Duration `yaml:"retention_max_age" env:"OUTBOX_RETENTION_MAX_AGE"`
+		RetentionInterval time.Duration `yaml:"retention_interval" env:"OUTBOX_RETENTION_INTERVAL"`
+		// Routes — таблица маршрутизации событий по топикам. Пустая — все события идут в Topic.
+		Routes []OutboxRoute `yaml:"routes" validate:"dive"`
+	}
+
+	// OutboxRoute — маршрут outbox-событий. Шаблоны в синтаксисе path.Match, пустой — любое значение.
+	OutboxRoute struct {
+		AggregateType string `yaml:"aggregate_type"`
+		EventType     string `yaml:"event_type"`
+		Topic         string `yaml:"topic" validate:"required"`
+		Transform     string `yaml:"transform"`
+	}
+
+	Prometheus struct {
+		Enabled bool   `yaml:"enabled" env:"PROMETHEUS_ENABLED"`
+		Path    string `yaml:"path" env:"PROMETHEUS_PATH" validate:"omitempty,startswith=/"`
+	}
+
+	// Admin — доступ к админскому API (/admin). Пустой ключ отключает админские маршруты.
+	Admin struct {
+		APIKey string `yaml:"api_key" env:"ADMIN_API_KEY" secret:"true"`
 	}
 )
 
+// New загружает конфигурацию из YAML-файла и переменных окружения (см. configloader.Load)
+// и проверяет её. Секреты можно передать файлом: POSTGRES_URL_FILE, REDIS_PASSWORD_FILE и т. д.
 func New(configPath string) (*Config, error) {
 	cfg := &Config{}
 
-	if err := cleanenv.ReadConfig(configPath, cfg); err != nil {
-		return nil, fmt.Errorf("config - NewConfig - cleanenv.ReadConfig: %w", err)
+	if err := configloader.Load(configPath, cfg); err != nil {
+		return nil, fmt.Errorf("config - NewConfig - configloader.Load: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Validate проверяет зависимости между полями, которые не выражаются тегами validate.
+func (c *Config) Validate() error {
+	var errs []error
+
+	errs = append(errs, c.App.validate(c.Health)...)
+	errs = append(errs, c.HTTP.validate()...)
+
+	if c.Redis.Mode == "sentinel" && c.Redis.MasterName == "" {
+		errs = append(errs, errors.New(`redis.master_name: is required when redis.mode is "sentinel"`))
 	}
 
-	if err := cleanenv.UpdateEnv(cfg); err != nil {
-		return nil, fmt.Errorf("config - NewConfig - cleanenv.UpdateEnv: %w", err)
+	if m := c.Kafka.Producer.Mode; m != "" && c.Kafka.Producer.RequiredAcks != -1 {
+		errs = append(errs, fmt.Errorf("kafka.producer.required_acks: must be -1 when kafka.producer.mode is %q", m))
 	}
 
-	return cfg, nil
+	errs = append(errs, c.Outbox.validate()...)
+
+	return errors.Join(errs...)
+}
+
+func (a App) validate(h Health) []error {
+	if a.ShutdownTimeout > 0 && h.DrainDelay >= a.ShutdownTimeout {
+		return []error{fmt.Errorf("health.drain_delay: must be less than app.shutdown_timeout (%s), got %s",
+			a.ShutdownTimeout, h.DrainDelay)}
+	}
+	return nil
+}
+
+func (h HTTP) validate() []error {
+	var errs []error
+	for route := range h.RouteTimeouts {
+		if method, path, ok := strings.Cut(route, " "); !ok || method == "" || !strings.HasPrefix(path, "/") {
+			errs = append(errs, fmt.Errorf(`http.route_timeouts: key must be "METHOD /path", got %q`, route))
+		}
+	}
+	return errs
+}
+
+func (o Outbox) validate() []error {
+	if o.RetryBackoff > 0 && o.RetryMaxBackoff > 0 && o.RetryBackoff > o.RetryMaxBackoff {
+		return []error{fmt.Errorf("outbox.retry_backoff: must not exceed outbox.retry_max_backoff (%s), got %s",
+			o.RetryMaxBackoff, o.RetryBackoff)}
+	}
+	return nil
 }
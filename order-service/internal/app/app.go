@@ -2,21 +2,26 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/4udiwe/big-bob-pizza/order-service/config"
 	consumer_delivery "github.com/4udiwe/big-bob-pizza/order-service/internal/consumer/delivery"
 	consumer_kitchen "github.com/4udiwe/big-bob-pizza/order-service/internal/consumer/kitchen"
 	consumer_payment "github.com/4udiwe/big-bob-pizza/order-service/internal/consumer/payment"
-	"github.com/4udiwe/big-bob-pizza/order-service/internal/database"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/handler"
 	cache_repository "github.com/4udiwe/big-bob-pizza/order-service/internal/repository/cache"
 	item_repository "github.com/4udiwe/big-bob-pizza/order-service/internal/repository/item"
 	order_repository "github.com/4udiwe/big-bob-pizza/order-service/internal/repository/order"
 	outbox_repository "github.com/4udiwe/big-bob-pizza/order-service/internal/repository/outbox"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/service/order"
+	outbox_service "github.com/4udiwe/big-bob-pizza/order-service/internal/service/outbox"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/health"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/httpserver"
-	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/leader"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/lifecycle"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/redis"
@@ -28,12 +33,16 @@ type App struct {
 	cfg       *config.Config
 	interrupt <-chan os.Signal
 
+	// autoMigrate — применять миграции при старте (serve -auto-migrate).
+	autoMigrate bool
+
 	// DB
 	postgres *postgres.Postgres
 	redis    *redis.Redis
 
 	// Echo
 	echoHandler *echo.Echo
+	health      *health.Health
 
 	// Repositories
 	cacheRepo  *cache_repository.CacheOrderRepository
@@ -42,7 +51,8 @@ type App struct {
 	outboxRepo *outbox_repository.Repository
 
 	// Services
-	orderService *order.Service
+	orderService  *order.Service
+	outboxService *outbox_service.Service
 
 	// Handlers
 	postOrderHandler handler.Handler
@@ -53,116 +63,187 @@ type App struct {
 	paymentConsumer  *consumer_payment.Consumer
 
 	// Outbox
-	OutboxWorker *outbox.Worker
+	OutboxWorker    *outbox.Worker
+	OutboxRetention *outbox.Retention
+
+	// Leader election фоновых задач
+	elector *leader.Elector
 }
 
 func New(configPath string) *App {
 	cfg, err := config.New(configPath)
 	if err != nil {
-		log.Fatalf("app - New - config.New: %v", err)
+		// Логгер ещё не настроен, а в JSON список ошибок конфигурации склеился бы в одну строку.
+		fmt.Fprintf(os.Stderr, "app - New - config.New: %v\n", err)
+		os.Exit(1)
 	}
 
 	initLogger(cfg.Log.Level)
 
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
 	return &App{
-		cfg: cfg,
+		cfg:       cfg,
+		interrupt: interrupt,
 	}
 }
 
 func (app *App) Start() {
+	// Компоненты регистрируются в порядке зависимостей: пулы, outbox, консьюмеры, HTTP.
+	// При остановке порядок обратный — сначала прекращается приём запросов и сообщений,
+	// затем дообрабатывается начатая работа, и только потом закрываются пулы.
+	lc := lifecycle.New(lifecycle.ShutdownTimeout(app.cfg.App.ShutdownTimeout))
+
 	// Postgres
 	log.Info("Connecting to PostgreSQL...")
 
-	postgres, err := postgres.New(app.cfg.Postgres.URL, postgres.ConnAttempts(5))
+	postgres, err := postgres.New(
+		app.cfg.Postgres.URL,
+		postgres.ConnAttempts(5),
+		postgres.Replicas(app.cfg.Postgres.ReplicaURLs...),
+		postgres.MaxReplicaLag(app.cfg.Postgres.MaxReplicaLag),
+		postgres.SlowQueryThreshold(app.cfg.Postgres.SlowQueryThreshold),
+	)
 
 	if err != nil {
 		log.Fatalf("app - Start - Postgres failed:%v", err)
 	}
 	app.postgres = postgres
+	app.Health().AddReadinessCheck("postgres", postgres.Ping)
 
-	defer postgres.Close()
+	lc.Append(lifecycle.Hook{
+		Name: "postgres",
+		OnStop: func(context.Context) error {
+			postgres.Close()
+			return nil
+		},
+	})
 
-	// Migrations
-	if err := database.RunMigrations(context.Background(), app.postgres.Pool); err != nil {
-		log.Errorf("app - Start - Migrations failed: %v", err)
-	}
+	// Migrations: схема должна соответствовать миграциям бинарника (см. checkSchema).
+	app.checkSchema(context.Background())
 
 	// Redis
-	redis, err := redis.New(app.cfg.Redis.Addr, "", 0)
+	redis, err := app.newRedis()
 	if err != nil {
 		log.Fatalf("app - Start - Redis failed:%v", err)
 	}
 	app.redis = redis
+	// Без Redis заказы читаются из Postgres, поэтому его недоступность не снимает сервис с трафика.
+	app.Health().AddOptionalReadinessCheck("redis", redis.Ping)
 
-	defer redis.Close()
+	lc.Append(lifecycle.Hook{
+		Name: "redis",
+		OnStop: func(context.Context) error {
+			return redis.Close()
+		},
+	})
 
-	// Общий контекст для всех фоновых задач (HTTP‑сервер, Kafka‑consumer’ы, OutboxWorker).
-	// По сигналу/ошибке сервера мы вызываем cancel() и корректно останавливаем фоновые горутины.
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Outbox publisher
+	kafkaPublisher := app.newKafkaPublisher()
 
-	// Consumers
-	paymentKafkaConsumer := kafka.NewConsumer(app.cfg.Kafka.Brokers)
-	kitchenKafkaConsumer := kafka.NewConsumer(app.cfg.Kafka.Brokers)
-	deliveryKafkaConsumer := kafka.NewConsumer(app.cfg.Kafka.Brokers)
+	lc.Append(lifecycle.Hook{
+		Name: "kafka publisher",
+		OnStop: func(context.Context) error {
+			return kafkaPublisher.Close()
+		},
+	})
+
+	// Listener будит воркер по NOTIFY из триггера outbox, не дожидаясь интервала опроса.
+	outboxListener := outbox.NewListener(app.postgres.Pool, outbox.DefaultChannel)
+
+	app.OutboxWorker = outbox.NewWorker(
+		app.OutboxRepo(),
+		kafkaPublisher,
+		app.cfg.Outbox.Topic,
+		app.cfg.Outbox.BatchLimit,
+		app.cfg.Outbox.RequeBatchLimit,
+		app.cfg.Outbox.Interval,
+		app.cfg.Outbox.RequeInterval,
+		outbox.MaxAttempts(app.cfg.Outbox.MaxAttempts),
+		outbox.RetryBackoff(app.cfg.Outbox.RetryBackoff, app.cfg.Outbox.RetryMaxBackoff),
+		outbox.Lease(app.cfg.Outbox.Lease),
+		outbox.WakeUp(outboxListener.C()),
+		outbox.Routes(app.outboxRouter()),
+	)
 
+	lc.Append(
+		lifecycle.Component("outbox listener", outboxListener),
+		lifecycle.Component("outbox worker", app.OutboxWorker),
+	)
+
+	// Retention переносит старые processed-записи в outbox_archive.
+	if app.cfg.Outbox.RetentionMaxAge > 0 {
+		app.OutboxRetention = outbox.NewRetention(
+			app.OutboxRepo(),
+			app.cfg.Outbox.RetentionMaxAge,
+			outbox.RetentionInterval(app.cfg.Outbox.RetentionInterval),
+			outbox.RetentionTopic(app.cfg.Outbox.Topic),
+		)
+
+		// Архивацию выполняет одна реплика — лидер; остальные ждут, пока он не пропадёт.
+		lc.Append(lifecycle.Go("outbox retention", func(ctx context.Context) {
+			err := app.Elector().RunAsLeader(ctx, app.cfg.App.Name+".outbox-retention", func(ctx context.Context) error {
+				app.OutboxRetention.Run(ctx)
+				<-ctx.Done()
+				return app.OutboxRetention.Shutdown(context.Background())
+			})
+			if err != nil {
+				log.Errorf("app - Start - outbox retention: %v", err)
+			}
+		}))
+	}
+
+	// Consumers
 	app.paymentConsumer = consumer_payment.New(
 		app.OrderService(),
-		paymentKafkaConsumer,
+		app.newKafkaConsumer("payment consumer"),
 		app.cfg.Kafka.Topics.PaymentEvents,
 		app.cfg.Kafka.Consumer.GroupID,
 	)
 
 	app.kitchenConsumer = consumer_kitchen.New(
 		app.OrderService(),
-		kitchenKafkaConsumer,
+		app.newKafkaConsumer("kitchen consumer"),
 		app.cfg.Kafka.Topics.KitchenEvents,
 		app.cfg.Kafka.Consumer.GroupID,
 	)
 
 	app.deliveryConsumer = consumer_delivery.New(
 		app.OrderService(),
-		deliveryKafkaConsumer,
+		app.newKafkaConsumer("delivery consumer"),
 		app.cfg.Kafka.Topics.DeliveryEvents,
 		app.cfg.Kafka.Consumer.GroupID,
 	)
 
-	// Outbox publisher
-	kafkaPublisher := kafka.NewKafkaPublisher(app.cfg.Kafka.Brokers)
-
-	app.OutboxWorker = outbox.NewWorker(
-		app.OutboxRepo(),
-		kafkaPublisher,
-		app.cfg.Outbox.Topic,
-		app.cfg.Outbox.BatchLimit,
-		app.cfg.Outbox.RequeBatchLimit,
-		app.cfg.Outbox.Interval,
-		app.cfg.Outbox.RequeInterval,
+	lc.Append(
+		app.consumerHook("payment consumer", app.paymentConsumer),
+		app.consumerHook("kitchen consumer", app.kitchenConsumer),
+		app.consumerHook("delivery consumer", app.deliveryConsumer),
 	)
 
+	// Kafka нужна консьюмерам и outbox, но не обработке HTTP-запросов: события копятся в outbox
+	// и уйдут, когда брокеры вернутся.
+	app.Health().AddOptionalReadinessCheck("kafka", app.kafkaCheck)
+
 	// App server
-	log.Info("Starting app server...")
 	httpServer := httpserver.New(app.EchoHandler(), httpserver.Port(app.cfg.HTTP.Port))
-	httpServer.Start()
-	log.Debugf("Server port: %s", app.cfg.HTTP.Port)
-
-	// Run consumers and publisher
-	app.paymentConsumer.Run(ctx)
-	app.kitchenConsumer.Run(ctx)
-	app.deliveryConsumer.Run(ctx)
-
-	app.OutboxWorker.Run(ctx)
-
-	select {
-	case s := <-app.interrupt:
-		log.Infof("app - Start - signal: %v", s)
-	case err := <-httpServer.Notify():
-		log.Errorf("app - Start - server error: %v", err)
-	}
 
-	// Останавливаем HTTP‑сервер после получения сигнала/ошибки.
-	if err := httpServer.Shutdown(); err != nil {
-		log.Errorf("HTTP server shutdown error: %v", err)
+	lc.Append(lifecycle.Hook{
+		Name: "http server",
+		OnStart: func(context.Context) error {
+			log.Infof("Starting app server on port %s...", app.cfg.HTTP.Port)
+			httpServer.Start()
+			lc.Watch("http server", httpServer.Notify())
+			return nil
+		},
+		OnStop: httpServer.Stop,
+	})
+
+	lc.Append(app.readinessHook())
+
+	if err := lc.Run(app.interrupt); err != nil {
+		log.Errorf("app - Start: %v", err)
 	}
 
 	log.Info("Shutting down...")
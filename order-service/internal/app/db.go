@@ -5,6 +5,7 @@ import (
 	item_repository "github.com/4udiwe/big-bob-pizza/order-service/internal/repository/item"
 	order_repository "github.com/4udiwe/big-bob-pizza/order-service/internal/repository/order"
 	outbox_repository "github.com/4udiwe/big-bob-pizza/order-service/internal/repository/outbox"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/leader"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/redis"
 )
@@ -17,6 +18,15 @@ func (app *App) Redis() *redis.Redis {
 	return app.redis
 }
 
+// Elector — выборы лидера для задач, которые должны выполняться на одной реплике.
+func (app *App) Elector() *leader.Elector {
+	if app.elector != nil {
+		return app.elector
+	}
+	app.elector = leader.New(leader.NewPostgresLocker(app.Postgres()))
+	return app.elector
+}
+
 func (app *App) CacheRepo() *cache_repository.CacheOrderRepository {
 	if app.cacheRepo != nil {
 		return app.cacheRepo
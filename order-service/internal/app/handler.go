@@ -6,7 +6,10 @@ import (
 	get_all_orders "github.com/4udiwe/big-bob-pizza/order-service/internal/handler/get_all_orders"
 	get_order "github.com/4udiwe/big-bob-pizza/order-service/internal/handler/get_order"
 	get_orders_by_user "github.com/4udiwe/big-bob-pizza/order-service/internal/handler/get_orders_by_user"
+	get_outbox_event "github.com/4udiwe/big-bob-pizza/order-service/internal/handler/get_outbox_event"
+	get_outbox_events "github.com/4udiwe/big-bob-pizza/order-service/internal/handler/get_outbox_events"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/handler/post_order"
+	post_outbox_action "github.com/4udiwe/big-bob-pizza/order-service/internal/handler/post_outbox_action"
 )
 
 func (app *App) PostOrderHandler() handler.Handler {
@@ -32,3 +35,23 @@ func (app *App) GetActiveOrdersByUserHandler() handler.Handler {
 func (app *App) GetAllOrdersHandler() handler.Handler {
 	return get_all_orders.New(app.OrderService())
 }
+
+func (app *App) GetOutboxEventsHandler() handler.Handler {
+	return get_outbox_events.New(app.OutboxService())
+}
+
+func (app *App) GetOutboxEventHandler() handler.Handler {
+	return get_outbox_event.New(app.OutboxService())
+}
+
+func (app *App) RetryOutboxHandler() handler.Handler {
+	return post_outbox_action.NewRetry(app.OutboxService())
+}
+
+func (app *App) SkipOutboxHandler() handler.Handler {
+	return post_outbox_action.NewSkip(app.OutboxService())
+}
+
+func (app *App) RepublishOutboxHandler() handler.Handler {
+	return post_outbox_action.NewRepublish(app.OutboxService())
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"os"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/lifecycle"
+	"github.com/labstack/gommon/log"
+)
+
+// consumer — консьюмер топика: Run подписывается на топик, Shutdown дообрабатывает прочитанные сообщения.
+type consumer interface {
+	Run(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+// newKafkaConsumer создаёт KafkaConsumer с настройками из секции kafka.consumer конфига
+// и регистрирует проверку liveness его цикла чтения под именем name.
+func (app *App) newKafkaConsumer(name string) *kafka.KafkaConsumer {
+	c := app.cfg.Kafka.Consumer
+
+	consumer := kafka.NewConsumer(app.cfg.Kafka.Brokers,
+		kafka.ConsumerConcurrency(c.Concurrency),
+		kafka.ConsumerMaxWait(c.MaxWait),
+		kafka.ConsumerSessionTimeout(c.SessionTimeout),
+		kafka.ConsumerHeartbeatInterval(c.HeartbeatInterval),
+		kafka.ConsumerCommitInterval(c.CommitInterval),
+		kafka.ConsumerRetryBackoff(c.RetryBackoff, c.RetryMaxBackoff),
+	)
+	app.Health().AddLivenessCheck(name, app.consumerLiveness(consumer))
+
+	return consumer
+}
+
+// newKafkaPublisher создаёт KafkaPublisher с настройками из секции kafka.producer конфига.
+// В транзакционном режиме без transactional_id идентификатор строится из имени приложения и хоста,
+// чтобы у каждой реплики был свой.
+func (app *App) newKafkaPublisher() *kafka.KafkaPublisher {
+	p := app.cfg.Kafka.Producer
+
+	cfg := kafka.ProducerConfig{
+		RequiredAcks:    p.RequiredAcks,
+		BatchSize:       p.BatchSize,
+		BatchTimeout:    p.BatchTimeout,
+		Compression:     p.Compression,
+		Mode:            p.Mode,
+		TransactionalID: p.TransactionalID,
+	}
+	if cfg.Mode == kafka.ProducerModeTransactional && cfg.TransactionalID == "" {
+		host, _ := os.Hostname()
+		cfg.TransactionalID = app.cfg.App.Name + "-" + host
+	}
+
+	broker, err := kafka.NewProducerBroker(app.cfg.Kafka.Brokers, cfg)
+	if err != nil {
+		log.Fatalf("app - newKafkaPublisher: %v", err)
+	}
+
+	return kafka.NewKafkaPublisher(app.cfg.Kafka.Brokers, kafka.PublisherBroker(broker))
+}
+
+// consumerHook регистрирует консьюмер в lifecycle. Дообработка прочитанных сообщений
+// при остановке ограничена kafka.consumer.drain_timeout.
+func (app *App) consumerHook(name string, c consumer) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name:    name,
+		OnStart: c.Run,
+		OnStop: func(ctx context.Context) error {
+			if d := app.cfg.Kafka.Consumer.DrainTimeout; d > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, d)
+				defer cancel()
+			}
+			return c.Shutdown(ctx)
+		},
+	}
+}
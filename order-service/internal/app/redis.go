@@ -0,0 +1,65 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/redis"
+)
+
+// newRedis подключается к Redis с настройками из секции redis конфига.
+func (app *App) newRedis() (*redis.Redis, error) {
+	c := app.cfg.Redis
+
+	opts := []redis.Option{
+		redis.Auth(c.Username, c.Password),
+		redis.DB(c.DB),
+		redis.PoolSize(c.PoolSize),
+		redis.CommandTimeout(c.CommandTimeout),
+		redis.Prefix(c.KeyPrefix),
+		redis.Breaker(c.Breaker.FailureThreshold, c.Breaker.OpenTimeout),
+	}
+
+	switch redis.Mode(c.Mode) {
+	case redis.ModeStandalone, "":
+	case redis.ModeSentinel:
+		opts = append(opts, redis.Sentinel(c.MasterName), redis.SentinelAuth("", c.SentinelPassword))
+	case redis.ModeCluster:
+		opts = append(opts, redis.Cluster())
+	default:
+		return nil, fmt.Errorf("app - newRedis: unknown redis.mode %q", c.Mode)
+	}
+
+	if c.TLS.Enabled {
+		tlsConfig, err := redisTLSConfig(c.TLS.CAFile, c.TLS.ServerName)
+		if err != nil {
+			return nil, fmt.Errorf("app - newRedis: %w", err)
+		}
+		opts = append(opts, redis.TLS(tlsConfig))
+	}
+
+	return redis.New(c.Addrs, opts...)
+}
+
+func redisTLSConfig(caFile, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read redis CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in redis CA file %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
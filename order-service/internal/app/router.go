@@ -1,11 +1,15 @@
 package app
 
 import (
+	"crypto/subtle"
 	"fmt"
-	"net/http"
 
-	"github.com/4udiwe/subscription-service/pkg/validator"
+	mw "github.com/4udiwe/big-bob-pizza/order-service/pkg/middleware"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/validator"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/log"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
@@ -16,6 +20,14 @@ func (app *App) EchoHandler() *echo.Echo {
 
 	handler := echo.New()
 	handler.Validator = validator.NewCustomValidator()
+	handler.HTTPErrorHandler = app.errorHandler()
+
+	mw.Install(handler,
+		mw.BodyLimit(app.cfg.HTTP.BodyLimit),
+		mw.RequestTimeout(app.cfg.HTTP.RequestTimeout),
+		mw.RouteTimeouts(app.cfg.HTTP.RouteTimeouts),
+		mw.SkipLog("/health", "/livez", "/readyz", app.cfg.Prometheus.Path),
+	)
 
 	app.configureRouter(handler)
 
@@ -38,8 +50,45 @@ func (app *App) configureRouter(handler *echo.Echo) {
 		orderGroup.GET("/user/:userId/active", app.GetActiveOrdersByUserHandler().Handle)
 	}
 
-	handler.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	// Admin API
+	if app.cfg.Admin.APIKey != "" {
+		adminGroup := handler.Group("admin", app.adminKeyAuth())
+		{
+			adminGroup.GET("/outbox", app.GetOutboxEventsHandler().Handle)
+			adminGroup.GET("/outbox/:id", app.GetOutboxEventHandler().Handle)
+			adminGroup.POST("/outbox/retry", app.RetryOutboxHandler().Handle)
+			adminGroup.POST("/outbox/skip", app.SkipOutboxHandler().Handle)
+			adminGroup.POST("/outbox/republish", app.RepublishOutboxHandler().Handle)
+		}
+	} else {
+		log.Warn("Admin API is disabled: admin.api_key is not set")
+	}
+
+	// /health оставлен для совместимости и равносилен /livez.
+	handler.GET("/health", app.Health().Live)
+	handler.GET("/livez", app.Health().Live)
+	handler.GET("/readyz", app.Health().Ready)
+
+	// Prometheus metrics endpoint
+	if app.cfg.Prometheus.Enabled {
+		handler.GET(app.cfg.Prometheus.Path, func(c echo.Context) error {
+			promhttp.Handler().ServeHTTP(c.Response(), c.Request())
+			return nil
+		})
+	}
 
 	// Swagger UI
 	handler.GET("/swagger/*", echoSwagger.WrapHandler)
 }
+
+// adminKeyAuth пропускает запросы с ключом из конфигурации в заголовке X-API-Key.
+func (app *App) adminKeyAuth() echo.MiddlewareFunc {
+	key := []byte(app.cfg.Admin.APIKey)
+
+	return middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+		KeyLookup: "header:X-API-Key",
+		Validator: func(got string, _ echo.Context) (bool, error) {
+			return subtle.ConstantTimeCompare([]byte(got), key) == 1, nil
+		},
+	})
+}
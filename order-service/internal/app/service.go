@@ -1,6 +1,9 @@
 package app
 
-import "github.com/4udiwe/big-bob-pizza/order-service/internal/service/order"
+import (
+	"github.com/4udiwe/big-bob-pizza/order-service/internal/service/order"
+	outbox_service "github.com/4udiwe/big-bob-pizza/order-service/internal/service/outbox"
+)
 
 func (app *App) OrderService() *order.Service {
 	if app.orderService != nil {
@@ -15,3 +18,11 @@ func (app *App) OrderService() *order.Service {
 	)
 	return app.orderService
 }
+
+func (app *App) OutboxService() *outbox_service.Service {
+	if app.outboxService != nil {
+		return app.outboxService
+	}
+	app.outboxService = outbox_service.NewService(app.OutboxRepo())
+	return app.outboxService
+}
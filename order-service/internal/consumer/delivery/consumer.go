@@ -5,9 +5,10 @@ import (
 
 	"github.com/sirupsen/logrus"
 
-	"github.com/4udiwe/big-bob-pizza/order-service/internal/consumer"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/service/order"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
 )
 
 // Обработчик событий для топика доставки
@@ -35,26 +36,25 @@ func New(
 func (c *Consumer) Run(ctx context.Context) error {
 	logrus.Infof("OrderConsumer: subscribing to topic=%s group=%s", c.topic, c.groupID)
 
-	return c.consumer.Subscribe(ctx, c.topic, c.groupID, func(ctx context.Context, key, value []byte) error {
-		event, err := consumer.ParseOrderEvent(value)
-		if err != nil {
-			logrus.Errorf("OrderConsumer: failed to parse event: %v", err)
-			return nil
-		}
-
-		switch event.Type {
+	return c.consumer.Subscribe(ctx, c.topic, c.groupID, c.Dispatcher().Dispatch)
+}
 
-		case consumer.DeliveryCompleted:
-			_, err = c.svc.MarkOrderCompleted(ctx, event.Payload.OrderID)
-			if err != nil {
-				logrus.Errorf("OrderConsumer: MarkOrderCompleted failed: %v", err)
-			}
+// Dispatcher возвращает обработчики событий топика.
+func (c *Consumer) Dispatcher() *kafka.Dispatcher {
+	d := kafka.NewDispatcher(schema.Default())
 
-		default:
-			logrus.Errorf("OrderConsumer: unknown event type %s", event.Type)
-			return nil
+	kafka.Handle(d, func(ctx context.Context, _ kafka.Envelope, ev events.DeliveryCompleted) error {
+		if _, err := c.svc.MarkOrderCompleted(ctx, ev.OrderID); err != nil {
+			logrus.Errorf("OrderConsumer: MarkOrderCompleted failed: %v", err)
+			return err
 		}
-
-		return err
+		return nil
 	})
+
+	return d
+}
+
+// Shutdown прекращает чтение топика и дожидается обработки уже прочитанных событий.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	return c.consumer.Shutdown(ctx)
 }
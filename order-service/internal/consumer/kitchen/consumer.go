@@ -3,12 +3,14 @@ package consumer_kitchen
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 
-	"github.com/4udiwe/big-bob-pizza/order-service/internal/consumer"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/entity"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/service/order"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
 )
 
 // Обработчик событий для топика кухни
@@ -36,38 +38,48 @@ func New(
 func (c *Consumer) Run(ctx context.Context) error {
 	logrus.Infof("OrderConsumer: subscribing to topic=%s group=%s", c.topic, c.groupID)
 
-	return c.consumer.Subscribe(ctx, c.topic, c.groupID, func(ctx context.Context, key, value []byte) error {
-		event, err := consumer.ParseOrderEvent(value)
-		if err != nil {
-			logrus.Errorf("OrderConsumer: failed to parse event: %v", err)
-			return nil
-		}
+	return c.consumer.Subscribe(ctx, c.topic, c.groupID, c.Dispatcher().Dispatch)
+}
 
-		switch event.Type {
-		case consumer.KitchenAccepted:
-			status := entity.OrderStatus{Name: entity.StatusPrepearing}
-			_, err = c.svc.UpdateOrderStatus(ctx, event.Payload.OrderID, status)
-			if err != nil {
-				logrus.Errorf("OrderConsumer: UpdateOrderStatus(prepearing) failed: %v", err)
-			}
+// Dispatcher возвращает обработчики событий топика.
+func (c *Consumer) Dispatcher() *kafka.Dispatcher {
+	d := kafka.NewDispatcher(schema.Default())
 
-		case consumer.KitchenReady:
-			_, err := c.svc.MarkOrderReady(ctx, event.Payload.OrderID)
-			if err != nil {
-				logrus.Errorf("OrderConsumer: MarkOrderReady failed: %v", err)
-			}
+	kafka.Handle(d, func(ctx context.Context, _ kafka.Envelope, ev events.KitchenAccepted) error {
+		status := entity.OrderStatus{Name: entity.StatusPrepearing}
+		if _, err := c.svc.UpdateOrderStatus(ctx, ev.OrderID, status); err != nil {
+			logrus.Errorf("OrderConsumer: UpdateOrderStatus(prepearing) failed: %v", err)
+			return err
+		}
+		return nil
+	})
 
-		case consumer.KitchenHandedToCourier:
-			_, err = c.svc.MarkOrderDelivering(ctx, event.Payload.OrderID, event.Payload.DeliveryID)
-			if err != nil {
-				logrus.Errorf("OrderConsumer: MarkOrderDelivering failed: %v", err)
-			}
+	kafka.Handle(d, func(ctx context.Context, _ kafka.Envelope, ev events.KitchenReady) error {
+		if _, err := c.svc.MarkOrderReady(ctx, ev.OrderID); err != nil {
+			logrus.Errorf("OrderConsumer: MarkOrderReady failed: %v", err)
+			return err
+		}
+		return nil
+	})
 
-		default:
-			logrus.Errorf("OrderConsumer: unknown event type %s", event.Type)
-			return nil
+	kafka.Handle(d, func(ctx context.Context, _ kafka.Envelope, ev events.KitchenHandedToCourier) error {
+		// deliveryId необязателен: кухня может передать заказ до создания доставки
+		deliveryID := uuid.Nil
+		if ev.DeliveryID != nil {
+			deliveryID = *ev.DeliveryID
 		}
 
-		return err
+		if _, err := c.svc.MarkOrderDelivering(ctx, ev.OrderID, deliveryID); err != nil {
+			logrus.Errorf("OrderConsumer: MarkOrderDelivering failed: %v", err)
+			return err
+		}
+		return nil
 	})
+
+	return d
+}
+
+// Shutdown прекращает чтение топика и дожидается обработки уже прочитанных событий.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	return c.consumer.Shutdown(ctx)
 }
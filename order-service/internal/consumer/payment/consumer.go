@@ -5,10 +5,11 @@ import (
 
 	"github.com/sirupsen/logrus"
 
-	"github.com/4udiwe/big-bob-pizza/order-service/internal/consumer"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/entity"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/service/order"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
 )
 
 // Обработчик событий для топика оплаты
@@ -36,32 +37,34 @@ func New(
 func (c *Consumer) Run(ctx context.Context) error {
 	logrus.Infof("OrderConsumer: subscribing to topic=%s group=%s", c.topic, c.groupID)
 
-	return c.consumer.Subscribe(ctx, c.topic, c.groupID, func(ctx context.Context, key, value []byte) error {
-		event, err := consumer.ParseOrderEvent(value)
-		if err != nil {
-			logrus.Errorf("OrderConsumer: failed to parse event: %v", err)
-			return nil
-		}
-
-		switch event.Type {
-		case consumer.PaymentSuccess:
-			_, err = c.svc.MarkOrderPaid(ctx, event.Payload.OrderID, event.Payload.PaymentID)
-			if err != nil {
-				logrus.Errorf("OrderConsumer: MarkOrderPaid failed: %v", err)
-			}
+	return c.consumer.Subscribe(ctx, c.topic, c.groupID, c.Dispatcher().Dispatch)
+}
 
-		case consumer.PaymentFailed:
-			status := entity.OrderStatus{Name: entity.StatusCancelled}
-			_, err = c.svc.UpdateOrderStatus(ctx, event.Payload.OrderID, status)
-			if err != nil {
-				logrus.Errorf("OrderConsumer: UpdateOrderStatus(cancelled) failed: %v", err)
-			}
+// Dispatcher возвращает обработчики событий топика.
+func (c *Consumer) Dispatcher() *kafka.Dispatcher {
+	d := kafka.NewDispatcher(schema.Default())
 
-		default:
-			logrus.Errorf("OrderConsumer: unknown event type %s", event.Type)
-			return nil
+	kafka.Handle(d, func(ctx context.Context, _ kafka.Envelope, ev events.PaymentSucceeded) error {
+		if _, err := c.svc.MarkOrderPaid(ctx, ev.OrderID, ev.PaymentID); err != nil {
+			logrus.Errorf("OrderConsumer: MarkOrderPaid failed: %v", err)
+			return err
 		}
+		return nil
+	})
 
-		return err
+	kafka.Handle(d, func(ctx context.Context, _ kafka.Envelope, ev events.PaymentFailed) error {
+		status := entity.OrderStatus{Name: entity.StatusCancelled}
+		if _, err := c.svc.UpdateOrderStatus(ctx, ev.OrderID, status); err != nil {
+			logrus.Errorf("OrderConsumer: UpdateOrderStatus(cancelled) failed: %v", err)
+			return err
+		}
+		return nil
 	})
+
+	return d
+}
+
+// Shutdown прекращает чтение топика и дожидается обработки уже прочитанных событий.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	return c.consumer.Shutdown(ctx)
 }
@@ -22,8 +22,6 @@ func NewBindAndValidateDecorator[T any](inner handler[T]) h.Handler {
 }
 
 func (d *bindAndValidateDecorator[T]) Handle(c echo.Context) error {
-	logrus.Infof("HTTP %s %s from %s", c.Request().Method, c.Path(), c.Request().RemoteAddr)
-
 	var in T
 
 	if err := c.Bind(&in); err != nil {
@@ -31,9 +29,10 @@ func (d *bindAndValidateDecorator[T]) Handle(c echo.Context) error {
 		return d.handleError(err, err.Error())
 	}
 
+	// Ошибка валидации отдаётся как есть: центральный обработчик ошибок вернёт ошибки по полям.
 	if err := c.Validate(in); err != nil {
 		logrus.Errorf("Failed to validate request: %v", err)
-		return d.handleError(err, err.Error())
+		return err
 	}
 
 	return d.inner.Handle(c, in)
@@ -48,7 +48,7 @@ func (h *handler) Handle(c echo.Context) error {
 				Total:  0,
 			})
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := OrdersResponse{
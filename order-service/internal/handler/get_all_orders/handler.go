@@ -54,7 +54,7 @@ func (h *handler) Handle(c echo.Context) error {
 
 	orders, total, err := h.s.GetAllOrders(c.Request().Context(), limit, offset)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := OrdersResponse{
@@ -60,7 +60,7 @@ func (h *handler) Handle(c echo.Context) error {
 
 	orders, total, err := h.s.GetOrdersByUser(c.Request().Context(), userID, limit, offset)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := OrdersResponse{
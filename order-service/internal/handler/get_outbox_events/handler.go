@@ -0,0 +1,144 @@
+package get_outbox_events
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/internal/entity"
+	h "github.com/4udiwe/big-bob-pizza/order-service/internal/handler"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	s OutboxService
+}
+
+func New(s OutboxService) h.Handler {
+	return &handler{s: s}
+}
+
+// GetOutboxEvents godoc
+// @Summary Список outbox-событий (админ)
+// @Description Возвращает outbox-события с фильтрацией по статусу, агрегату и интервалу создания [from, to). Без payload
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminAPIKey
+// @Param status query string false "Статус события" Enums(pending, failed, processed, dead, skipped)
+// @Param aggregateType query string false "Тип агрегата"
+// @Param aggregateId query string false "ID агрегата (UUID)"
+// @Param from query string false "Начало интервала, RFC3339"
+// @Param to query string false "Конец интервала, RFC3339"
+// @Param limit query int false "Количество записей на странице" default(20) minimum(1) maximum(100)
+// @Param offset query int false "Смещение для пагинации" default(0) minimum(0)
+// @Success 200 {object} EventsResponse
+// @Failure 400 {string} string "Ошибка валидации"
+// @Failure 401 {string} string "Неверный API-ключ"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Router /admin/outbox [get]
+func (h *handler) Handle(c echo.Context) error {
+	limit := 20
+	offset := 0
+	var err error
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 100 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit parameter")
+		}
+	}
+
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid offset parameter")
+		}
+	}
+
+	filter := entity.OutboxFilter{
+		Status:        entity.OutboxStatusName(c.QueryParam("status")),
+		AggregateType: c.QueryParam("aggregateType"),
+	}
+
+	switch filter.Status {
+	case "", entity.OutboxStatusPending, entity.OutboxStatusFailed, entity.OutboxStatusProcessed,
+		entity.OutboxStatusDead, entity.OutboxStatusSkipped:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid status parameter")
+	}
+
+	if idStr := c.QueryParam("aggregateId"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid aggregateId parameter")
+		}
+		filter.AggregateID = &id
+	}
+
+	if fromStr := c.QueryParam("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid from parameter")
+		}
+		filter.From = &from
+	}
+
+	if toStr := c.QueryParam("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid to parameter")
+		}
+		filter.To = &to
+	}
+
+	events, total, err := h.s.List(c.Request().Context(), filter, limit, offset)
+	if err != nil {
+		return err
+	}
+
+	resp := EventsResponse{
+		Events: make([]EventResponse, len(events)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	for i, ev := range events {
+		resp.Events[i] = EventResponse{
+			ID:            ev.ID,
+			AggregateType: ev.AggregateType,
+			AggregateID:   ev.AggregateID,
+			EventType:     ev.EventType,
+			Status:        ev.Status.Name,
+			Attempts:      ev.Attempts,
+			LastError:     ev.LastError,
+			NextAttemptAt: ev.NextAttemptAt,
+			CreatedAt:     ev.CreatedAt,
+			ProcessedAt:   ev.ProcessedAt,
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+type EventsResponse struct {
+	Events []EventResponse `json:"events"`
+	Total  int             `json:"total"`
+	Limit  int             `json:"limit"`
+	Offset int             `json:"offset"`
+}
+
+type EventResponse struct {
+	ID            uuid.UUID               `json:"id"`
+	AggregateType string                  `json:"aggregateType"`
+	AggregateID   uuid.UUID               `json:"aggregateId"`
+	EventType     string                  `json:"eventType"`
+	Status        entity.OutboxStatusName `json:"status"`
+	Attempts      int                     `json:"attempts"`
+	LastError     *string                 `json:"lastError,omitempty"`
+	NextAttemptAt *time.Time              `json:"nextAttemptAt,omitempty"`
+	CreatedAt     time.Time               `json:"createdAt"`
+	ProcessedAt   *time.Time              `json:"processedAt,omitempty"`
+}
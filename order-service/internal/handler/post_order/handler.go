@@ -1,14 +1,12 @@
 package post_order
 
 import (
-	"errors"
 	"net/http"
 	"time"
 
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/entity"
 	h "github.com/4udiwe/big-bob-pizza/order-service/internal/handler"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/handler/decorator"
-	service "github.com/4udiwe/big-bob-pizza/order-service/internal/service/order"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/samber/lo"
@@ -93,10 +91,7 @@ func (h *handler) Handle(c echo.Context, in Request) error {
 
 	offer, err := h.s.CreateOrder(c.Request().Context(), order)
 	if err != nil {
-		if errors.Is(err, service.ErrOrderAlreadyExists) {
-			return echo.NewHTTPError(http.StatusConflict, err.Error())
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := Response{
@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ../../pkg/transactor/transactor.go
+// Source: transactor.go
 //
 // Generated by this command:
 //
-//	mockgen -source=transactor.go -destination=../../internal/mocks/mock_transactor.go
+//	mockgen -source=transactor.go -destination=../../internal/mocks/mock_transactor.go -package=mocks
 //
 
 // Package mocks is a generated GoMock package.
@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	transactor "github.com/4udiwe/big-bob-pizza/order-service/pkg/transactor"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -20,6 +21,7 @@ import (
 type MockTransactor struct {
 	ctrl     *gomock.Controller
 	recorder *MockTransactorMockRecorder
+	isgomock struct{}
 }
 
 // MockTransactorMockRecorder is the mock recorder for MockTransactor.
@@ -40,15 +42,20 @@ func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
 }
 
 // WithinTransaction mocks base method.
-func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+func (m *MockTransactor) WithinTransaction(ctx context.Context, fn func(context.Context) error, opts ...transactor.Option) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	varargs := []any{ctx, fn}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithinTransaction", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // WithinTransaction indicates an expected call of WithinTransaction.
-func (mr *MockTransactorMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+func (mr *MockTransactorMockRecorder) WithinTransaction(ctx, fn any, opts ...any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockTransactor)(nil).WithinTransaction), ctx, fn)
+	varargs := append([]any{ctx, fn}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockTransactor)(nil).WithinTransaction), varargs...)
 }
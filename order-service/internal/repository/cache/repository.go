@@ -3,10 +3,12 @@ package cache_repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/entity"
+	"github.com/4udiwe/big-bob-pizza/order-service/internal/repository"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/redis"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
@@ -19,6 +21,10 @@ const (
 	statusOrdersKey = "orders:status:%s"
 
 	orderTTL = 24 * time.Hour
+
+	// notFoundTTL — сколько помнить, что заказа нет в Postgres.
+	notFoundTTL    = 30 * time.Second
+	notFoundMarker = "-"
 )
 
 type CacheOrderRepository struct {
@@ -26,9 +32,7 @@ type CacheOrderRepository struct {
 }
 
 func NewCacheOrderRepository(client *redis.Redis) *CacheOrderRepository {
-	return &CacheOrderRepository{
-		client: client,
-	}
+	return &CacheOrderRepository{client: client}
 }
 
 // build keys
@@ -60,25 +64,39 @@ func (r *CacheOrderRepository) Save(ctx context.Context, ord *entity.Order) erro
 	return nil
 }
 
-func (r *CacheOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
+// GetByID возвращает заказ из кэша и оставшееся время жизни записи (<= 0 — у ключа нет TTL).
+// (nil, 0, nil) — промах: ключа нет. repository.ErrOrderNotFound — в кэше записано, что заказа нет (SaveNotFound).
+func (r *CacheOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, time.Duration, error) {
 	key := keyOrder(id)
 
-	data, err := r.client.Get(ctx, key)
+	data, ttl, err := r.client.GetWithTTL(ctx, key)
 	if err != nil {
-		return nil, fmt.Errorf("cache order repo - get order: %w", err)
+		if errors.Is(err, redis.Nil) {
+			return nil, 0, nil // not found in cache
+		}
+		return nil, 0, fmt.Errorf("cache order repo - get order: %w", err)
 	}
 
-	if data == "" {
-		return nil, nil // not found in cache
+	if data == notFoundMarker {
+		return nil, 0, repository.ErrOrderNotFound
 	}
 
 	var ord entity.Order
 	if err := json.Unmarshal([]byte(data), &ord); err != nil {
-		return nil, fmt.Errorf("cache order repo - unmarshal order: %w", err)
+		return nil, 0, fmt.Errorf("cache order repo - unmarshal order: %w", err)
 	}
 
 	log.Debugf("redis: loaded order %s from cache", id)
-	return &ord, nil
+	return &ord, ttl, nil
+}
+
+// SaveNotFound запоминает на notFoundTTL, что заказа нет, чтобы запросы несуществующих id не доходили до Postgres.
+// Запись не перетирает заказ, который успели закэшировать параллельно.
+func (r *CacheOrderRepository) SaveNotFound(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.client.SetNX(ctx, keyOrder(id), notFoundMarker, notFoundTTL); err != nil {
+		return fmt.Errorf("cache order repo - set not found: %w", err)
+	}
+	return nil
 }
 
 func (r *CacheOrderRepository) AddToActive(ctx context.Context, ord *entity.Order) error {
@@ -22,6 +22,8 @@ func New(postgres *postgres.Postgres) *Repository {
 // Inserts items for order.
 // Returns filled items (with TotalPrice and ID).
 func (r *Repository) InsertItems(ctx context.Context, orderID uuid.UUID, items []entity.OrderItem) ([]entity.OrderItem, error) {
+	ctx = postgres.WithOperation(ctx, "ItemRepository.InsertItems")
+
 	logrus.Infof("ItemRepository.InsertItems: insert for orderID=%v", orderID)
 
 	builder := r.Builder.
@@ -30,6 +30,8 @@ func New(postgres *postgres.Postgres) *Repository {
 // Inserts only order data (without order items).
 // Receives entity with order data.
 func (r *Repository) Create(ctx context.Context, order entity.Order) (entity.Order, error) {
+	ctx = postgres.WithOperation(ctx, "OrderRepository.Create")
+
 	logrus.Infof("OrderRepository.Create: customerID=%v", order.CustomerID)
 
 	query, args, _ := r.Builder.
@@ -77,6 +79,8 @@ func (r *Repository) Create(ctx context.Context, order entity.Order) (entity.Ord
 }
 
 func (r *Repository) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, status entity.StatusName, time time.Time) error {
+	ctx = postgres.WithOperation(ctx, "OrderRepository.UpdateOrderStatus")
+
 	logrus.Infof("OrderRepository.UpdateOrderStatus: orderID=%v, status=%v", orderID, status)
 
 	query, args, _ := r.Builder.
@@ -98,6 +102,8 @@ func (r *Repository) UpdateOrderStatus(ctx context.Context, orderID uuid.UUID, s
 }
 
 func (r *Repository) UpdateOrderPayment(ctx context.Context, orderID, paymentID uuid.UUID, time time.Time) error {
+	ctx = postgres.WithOperation(ctx, "OrderRepository.UpdateOrderPayment")
+
 	logrus.Infof("OrderRepository.UpdateOrderPayment: orderID=%v", orderID)
 
 	query, args, _ := r.Builder.
@@ -120,6 +126,8 @@ func (r *Repository) UpdateOrderPayment(ctx context.Context, orderID, paymentID
 }
 
 func (r *Repository) UpdateOrderDelivery(ctx context.Context, orderID, deliveryID uuid.UUID, time time.Time) error {
+	ctx = postgres.WithOperation(ctx, "OrderRepository.UpdateOrderDelivery")
+
 	logrus.Infof("OrderRepository.UpdateOrderDelivery: orderID=%v", orderID)
 
 	query, args, _ := r.Builder.
@@ -142,6 +150,8 @@ func (r *Repository) UpdateOrderDelivery(ctx context.Context, orderID, deliveryI
 }
 
 func (r *Repository) GetOrderByID(ctx context.Context, orderID uuid.UUID) (entity.Order, error) {
+	ctx = postgres.WithOperation(ctx, "OrderRepository.GetOrderByID")
+
 	logrus.Infof("OrderRepository.GetOrderByID: orderID=%v", orderID)
 
 	query, args, _ := r.Builder.
@@ -195,6 +205,8 @@ func (r *Repository) GetOrderByID(ctx context.Context, orderID uuid.UUID) (entit
 
 // Return page of found orders sorted by creation time, total items, and error.
 func (r *Repository) GetAllOrders(ctx context.Context, limit, offset int) (orders []entity.Order, total int, err error) {
+	ctx = postgres.WithOperation(ctx, "OrderRepository.GetAllOrders")
+
 	logrus.Infof("OrderRepository.GetAllOrders: limit=%d offset=%d", limit, offset)
 
 	// Get total
@@ -309,6 +321,8 @@ func (r *Repository) GetAllOrders(ctx context.Context, limit, offset int) (order
 }
 
 func (r *Repository) GetOrdersByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) (orders []entity.Order, total int, err error) {
+	ctx = postgres.WithOperation(ctx, "OrderRepository.GetOrdersByUserID")
+
 	logrus.Infof("OrderRepository.GetOrdersByUserID: userID = %v limit=%d offset=%d", userID, limit, offset)
 
 	// Get total count
@@ -423,3 +437,70 @@ func (r *Repository) GetOrdersByUserID(ctx context.Context, userID uuid.UUID, li
 	logrus.Infof("OrderRepository.GetOrdersByUserID: fetched %d orders", len(orders))
 	return orders, total, nil
 }
+
+// GetActiveOrdersByUserID возвращает незавершённые заказы пользователя — источник, когда кэш недоступен.
+func (r *Repository) GetActiveOrdersByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Order, error) {
+	ctx = postgres.WithOperation(ctx, "OrderRepository.GetActiveOrdersByUserID")
+
+	logrus.Infof("OrderRepository.GetActiveOrdersByUserID: userID = %v", userID)
+
+	query, args, _ := r.Builder.
+		Select("o.id", "o.customer_id", "o.status_id", "s.name as status_name", "o.payment_id", "o.delivery_id", "o.total_amount", "o.currency", "o.created_at", "o.updated_at").
+		From("orders AS o").
+		Join("order_status AS s ON o.status_id = s.id").
+		Where("o.customer_id = ?", userID).
+		Where(squirrel.NotEq{"s.name": []string{string(entity.StatusCompleted), string(entity.StatusCancelled)}}).
+		OrderBy("o.created_at DESC").
+		ToSql()
+
+	rows, err := r.GetTxManager(ctx).Query(ctx, query, args...)
+	if err != nil {
+		logrus.Errorf("OrderRepository.GetActiveOrdersByUserID: orders query error: %v", err)
+		return nil, err
+	}
+
+	rowOrders, err := pgx.CollectRows(rows, pgx.RowToStructByName[RowOrder])
+	if err != nil {
+		logrus.Errorf("OrderRepository.GetActiveOrdersByUserID: orders scan error: %v", err)
+		return nil, err
+	}
+
+	if len(rowOrders) == 0 {
+		return []entity.Order{}, nil
+	}
+
+	orderIDs := make([]uuid.UUID, len(rowOrders))
+	orders := lo.Map(rowOrders, func(r RowOrder, i int) entity.Order {
+		orderIDs[i] = r.ID
+		return r.ToEntity()
+	})
+
+	itemsQuery, itemsArgs, _ := r.Builder.
+		Select("id, order_id, product_id, product_name, product_price, amount, total_price, notes").
+		From("order_item").
+		Where("order_id = ANY(?)", orderIDs).
+		ToSql()
+
+	itemRows, err := r.GetTxManager(ctx).Query(ctx, itemsQuery, itemsArgs...)
+	if err != nil {
+		logrus.Errorf("OrderRepository.GetActiveOrdersByUserID: items query error: %v", err)
+		return nil, err
+	}
+
+	rowItems, err := pgx.CollectRows(itemRows, pgx.RowToStructByName[RowItem])
+	if err != nil {
+		logrus.Errorf("OrderRepository.GetActiveOrdersByUserID: items scan error: %v", err)
+		return nil, err
+	}
+
+	itemsByOrder := make(map[uuid.UUID][]entity.OrderItem)
+	for _, r := range rowItems {
+		itemsByOrder[r.OrderID] = append(itemsByOrder[r.OrderID], r.ToEntity())
+	}
+	for i := range orders {
+		orders[i].Items = itemsByOrder[orders[i].ID]
+	}
+
+	logrus.Infof("OrderRepository.GetActiveOrdersByUserID: fetched %d orders", len(orders))
+	return orders, nil
+}
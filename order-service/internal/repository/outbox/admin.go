@@ -0,0 +1,56 @@
+package outbox_repository
+
+import (
+	"context"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/internal/entity"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Методы для админского API: запросы выполняет outbox.Store, здесь — перевод в доменные сущности.
+
+func (r *Repository) List(ctx context.Context, filter entity.OutboxFilter, limit, offset int) ([]entity.OutboxEvent, int, error) {
+	logrus.Infof("OutboxRepository.List: filter=%+v limit=%d offset=%d", filter, limit, offset)
+
+	records, total, err := r.Store.List(ctx, toFilter(filter), limit, offset)
+	if err != nil {
+		logrus.Errorf("OutboxRepository.List: %v", err)
+		return nil, 0, err
+	}
+
+	events := make([]entity.OutboxEvent, len(records))
+	for i, rec := range records {
+		events[i] = toAdminEntity(rec)
+	}
+
+	return events, total, nil
+}
+
+// GetByID возвращает событие или outbox.ErrEventNotFound.
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (entity.OutboxEvent, error) {
+	rec, err := r.Store.GetByID(ctx, id)
+	if err != nil {
+		return entity.OutboxEvent{}, err
+	}
+
+	return toAdminEntity(rec), nil
+}
+
+// Retry возвращает в pending failed- и dead-события выборки со сброшенным счётчиком попыток.
+func (r *Repository) Retry(ctx context.Context, filter entity.OutboxFilter) (int64, error) {
+	logrus.Warnf("OutboxRepository.Retry: filter=%+v", filter)
+	return r.Store.Retry(ctx, toFilter(filter))
+}
+
+// Republish заново ставит в очередь уже отправленные, пропущенные или упавшие события выборки.
+func (r *Repository) Republish(ctx context.Context, filter entity.OutboxFilter) (int64, error) {
+	logrus.Warnf("OutboxRepository.Republish: filter=%+v", filter)
+	return r.Store.Republish(ctx, toFilter(filter))
+}
+
+// Skip исключает из публикации неотправленные события выборки.
+func (r *Repository) Skip(ctx context.Context, filter entity.OutboxFilter) (int64, error) {
+	logrus.Warnf("OutboxRepository.Skip: filter=%+v", filter)
+	return r.Store.Skip(ctx, toFilter(filter))
+}
@@ -1,47 +1,46 @@
 package outbox_repository
 
 import (
-	"encoding/json"
-	"time"
-
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/entity"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
-	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
-type RowOutbox struct {
-	ID            uuid.UUID      `db:"id"`
-	AggregateType string         `db:"aggregate_type"`
-	AggregateID   uuid.UUID      `db:"aggregate_id"`
-	EventType     string         `db:"event_type"`
-	Payload       map[string]any `db:"payload"`
-	StatusID      int            `db:"status_id"`
-	StatusName    string         `db:"status_name"`
-	CreatedAt     time.Time      `db:"created_at"`
-	ProcessedAt   *time.Time     `db:"processed_at"`
+func toFilter(f entity.OutboxFilter) outbox.Filter {
+	return outbox.Filter{
+		IDs:           f.IDs,
+		Status:        string(f.Status),
+		AggregateType: f.AggregateType,
+		AggregateID:   f.AggregateID,
+		From:          f.From,
+		To:            f.To,
+	}
 }
 
-func (r RowOutbox) ToEntity() entity.OutboxEvent {
-	return entity.OutboxEvent{
+// toAdminEntity не падает на payload, который не удаётся декодировать, — такие события
+// как раз и нужно уметь разглядывать через админский API. Сырой payload доступен в RawPayload.
+func toAdminEntity(r outbox.Record) entity.OutboxEvent {
+	ev := entity.OutboxEvent{
 		ID:            r.ID,
 		AggregateType: r.AggregateType,
 		AggregateID:   r.AggregateID,
 		EventType:     r.EventType,
-		Payload:       r.Payload,
-		Status:        entity.OutboxStatus{ID: r.StatusID, Name: entity.OutboxStatusName(r.StatusName)},
+		RawPayload:    r.Payload,
+		Status:        entity.OutboxStatus{ID: r.StatusID, Name: entity.OutboxStatusName(r.Status)},
 		CreatedAt:     r.CreatedAt,
 		ProcessedAt:   r.ProcessedAt,
+		Attempts:      r.Attempts,
+		LastError:     r.LastError,
+		NextAttemptAt: r.NextAttemptAt,
 	}
-}
 
-func (r RowOutbox) ToEvent() outbox.Event {
-	payloadBytes, err := json.Marshal(r.Payload)
+	payload, err := events.Decode(outbox.FullEventType(r.AggregateType, r.EventType), r.Payload)
 	if err != nil {
-		payloadBytes = []byte("{}")
-	}
-	return outbox.Event{
-		ID:        r.ID,
-		EventType: r.AggregateType + "." + r.EventType,
-		Payload:   payloadBytes,
+		logrus.Warnf("OutboxRepository: decode payload of event %s: %v", r.ID, err)
+		return ev
 	}
+	ev.Payload = payload
+
+	return ev
 }
@@ -21,6 +21,8 @@ type OrderRepo interface {
 	// Return page of found orders sorted by creation time, total items, and error.
 	GetAllOrders(ctx context.Context, limit, offset int) (orders []entity.Order, total int, err error)
 	GetOrdersByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) (orders []entity.Order, total int, err error)
+	// Returns not completed and not cancelled orders of user.
+	GetActiveOrdersByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Order, error)
 }
 
 type ItemsRepo interface {
@@ -35,7 +37,11 @@ type OutboxRepo interface {
 
 type CacheRepo interface {
 	Save(ctx context.Context, ord *entity.Order) error
-	GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, error)
+	// Returns the order with its remaining TTL (<= 0 if the key has none), (nil, 0, nil) on cache miss
+	// and repository.ErrOrderNotFound if the order is cached as missing.
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, time.Duration, error)
+	// Caches that the order does not exist for a short time.
+	SaveNotFound(ctx context.Context, id uuid.UUID) error
 	AddToActive(ctx context.Context, ord *entity.Order) error
 	RemoveFromActive(ctx context.Context, id uuid.UUID) error
 	AddUserActive(ctx context.Context, userID uuid.UUID, ordID uuid.UUID) error
@@ -58,6 +58,21 @@ func (mr *MockOrderRepoMockRecorder) Create(ctx, order any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrderRepo)(nil).Create), ctx, order)
 }
 
+// GetActiveOrdersByUserID mocks base method.
+func (m *MockOrderRepo) GetActiveOrdersByUserID(ctx context.Context, userID uuid.UUID) ([]entity.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveOrdersByUserID", ctx, userID)
+	ret0, _ := ret[0].([]entity.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveOrdersByUserID indicates an expected call of GetActiveOrdersByUserID.
+func (mr *MockOrderRepoMockRecorder) GetActiveOrdersByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveOrdersByUserID", reflect.TypeOf((*MockOrderRepo)(nil).GetActiveOrdersByUserID), ctx, userID)
+}
+
 // GetAllOrders mocks base method.
 func (m *MockOrderRepo) GetAllOrders(ctx context.Context, limit, offset int) ([]entity.Order, int, error) {
 	m.ctrl.T.Helper()
@@ -320,12 +335,13 @@ func (mr *MockCacheRepoMockRecorder) GetActiveOrders(ctx any) *gomock.Call {
 }
 
 // GetByID mocks base method.
-func (m *MockCacheRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, error) {
+func (m *MockCacheRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Order, time.Duration, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetByID", ctx, id)
 	ret0, _ := ret[0].(*entity.Order)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(time.Duration)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetByID indicates an expected call of GetByID.
@@ -420,3 +436,16 @@ func (mr *MockCacheRepoMockRecorder) Save(ctx, ord any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockCacheRepo)(nil).Save), ctx, ord)
 }
 
+// SaveNotFound mocks base method.
+func (m *MockCacheRepo) SaveNotFound(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveNotFound", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveNotFound indicates an expected call of SaveNotFound.
+func (mr *MockCacheRepoMockRecorder) SaveNotFound(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNotFound", reflect.TypeOf((*MockCacheRepo)(nil).SaveNotFound), ctx, id)
+}
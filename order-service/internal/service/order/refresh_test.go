@@ -0,0 +1,18 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshEarly(t *testing.T) {
+	// Далеко до истечения TTL запись не обновляется даже при маленьком rnd.
+	require.False(t, refreshEarly(24*time.Hour, 0.001))
+	// У самого истечения обновление почти гарантировано.
+	require.True(t, refreshEarly(100*time.Millisecond, 0.5))
+	require.False(t, refreshEarly(earlyRefreshDelta, 1))
+	// Ключ без TTL не обновляется.
+	require.False(t, refreshEarly(-1, 0.001))
+}
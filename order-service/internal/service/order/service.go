@@ -3,14 +3,19 @@ package order
 import (
 	"context"
 	"errors"
+	"math"
+	"math/rand/v2"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/samber/lo"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/entity"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/repository"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/transactor"
 )
 
@@ -20,8 +25,17 @@ type Service struct {
 	OutboxRepo OutboxRepo
 	CacheRepo  CacheRepo
 	TxManager  transactor.Transactor
+
+	// loads объединяет одновременные чтения одного заказа из Postgres при промахе кэша.
+	loads singleflight.Group
+	// rand — равномерно в (0, 1], для refreshEarly.
+	rand func() float64
 }
 
+// earlyRefreshDelta — масштаб досрочного обновления кэша: примерно за столько до истечения TTL
+// запись начинает обновляться с заметной вероятностью.
+const earlyRefreshDelta = 10 * time.Second
+
 func NewService(
 	orderRepo OrderRepo,
 	itemsRepo ItemsRepo,
@@ -35,6 +49,8 @@ func NewService(
 		OutboxRepo: outboxRepo,
 		CacheRepo:  cacheRepo,
 		TxManager:  txManager,
+		// 1 - [0, 1) даёт (0, 1]: ln(0) не бывает.
+		rand: func() float64 { return 1 - rand.Float64() },
 	}
 }
 
@@ -66,7 +82,7 @@ func (s *Service) CreateOrder(ctx context.Context, ord entity.Order) (entity.Ord
 			AggregateType: "order",
 			AggregateID:   created.ID,
 			EventType:     "created",
-			Payload:       map[string]any{"orderId": created.ID, "userId": created.CustomerID, "totalPrice": created.TotalAmount},
+			Payload:       events.OrderCreated{OrderID: created.ID, UserID: created.CustomerID, TotalPrice: created.TotalAmount},
 			Status:        entity.OutboxStatus{ID: 1, Name: "pending"},
 			CreatedAt:     time.Now(),
 		}
@@ -159,7 +175,7 @@ func (s *Service) MarkOrderReady(ctx context.Context, orderID uuid.UUID) (entity
 			AggregateType: "order",
 			AggregateID:   orderID,
 			EventType:     "prepeared",
-			Payload:       map[string]any{"orderId": orderID},
+			Payload:       events.OrderPrepared{OrderID: orderID},
 			Status:        entity.OutboxStatus{Name: entity.OutboxStatusPending},
 			CreatedAt:     now,
 		}
@@ -213,7 +229,7 @@ func (s *Service) MarkOrderPaid(ctx context.Context, orderID, paymentID uuid.UUI
 			AggregateType: "order",
 			AggregateID:   orderID,
 			EventType:     "paid",
-			Payload:       map[string]any{"orderId": orderID, "paymentId": paymentID},
+			Payload:       events.OrderPaid{OrderID: orderID, PaymentID: paymentID},
 			Status:        entity.OutboxStatus{Name: entity.OutboxStatusPending},
 			CreatedAt:     now,
 		}
@@ -265,7 +281,7 @@ func (s *Service) MarkOrderDelivering(ctx context.Context, orderID, deliveryID u
 			AggregateType: "order",
 			AggregateID:   orderID,
 			EventType:     "delivering",
-			Payload:       map[string]any{"orderId": orderID},
+			Payload:       events.OrderDelivering{OrderID: orderID},
 			Status:        entity.OutboxStatus{Name: entity.OutboxStatusPending},
 			CreatedAt:     now,
 		}
@@ -318,7 +334,7 @@ func (s *Service) MarkOrderCompleted(ctx context.Context, orderID uuid.UUID) (en
 			AggregateType: "order",
 			AggregateID:   orderID,
 			EventType:     "completed",
-			Payload:       map[string]any{"orderId": orderID},
+			Payload:       events.OrderCompleted{OrderID: orderID},
 			Status:        entity.OutboxStatus{Name: entity.OutboxStatusPending},
 			CreatedAt:     now,
 		}
@@ -349,25 +365,68 @@ func (s *Service) MarkOrderCompleted(ctx context.Context, orderID uuid.UUID) (en
 
 func (s *Service) GetOrderByID(ctx context.Context, orderID uuid.UUID) (entity.Order, error) {
 	// Attempt to get from cache
-	ord, err := s.CacheRepo.GetByID(ctx, orderID)
-	if err == nil && ord != nil {
+	ord, ttl, err := s.CacheRepo.GetByID(ctx, orderID)
+	// cached — заказ из кэша, который перечитывается заранее; nil — кэш промахнулся.
+	var cached *entity.Order
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound):
+		return entity.Order{}, ErrOrderNotFound
+	case err == nil && ord != nil && s.refreshEarly(ttl):
+		log.Debugf("OrderService.GetOrderByID: early refresh of %s, ttl %v", orderID, ttl)
+		cached = ord
+	case err == nil && ord != nil:
 		log.Debugf("OrderService.GetOrderByID: hit cache %s", orderID)
 		return *ord, nil
 	}
 
-	// Fallback to Postgres
-	ordFull, err := s.OrderRepo.GetOrderByID(ctx, orderID)
+	// Fallback to Postgres. Одновременные промахи по одному заказу выполняют один запрос,
+	// поэтому истёкший ключ популярного заказа не обрушивает на базу всех, кто его отслеживает.
+	v, err, _ := s.loads.Do(orderID.String(), func() (any, error) {
+		// Запрос общий для всех ожидающих: отмена контекста первого из них не должна прерывать остальных.
+		// Заказ читается с primary: реплика может отставать, и устаревший заказ попал бы в кэш на весь TTL.
+		ctx := postgres.WithPrimary(context.WithoutCancel(ctx))
+
+		ordFull, err := s.OrderRepo.GetOrderByID(ctx, orderID)
+		if err != nil {
+			if errors.Is(err, repository.ErrOrderNotFound) {
+				_ = s.CacheRepo.SaveNotFound(ctx, orderID)
+			}
+			return nil, err
+		}
+
+		// Caching
+		_ = s.CacheRepo.Save(ctx, &ordFull)
+		return ordFull, nil
+	})
 	if err != nil {
 		if errors.Is(err, repository.ErrOrderNotFound) {
 			return entity.Order{}, ErrOrderNotFound
 		}
+		// Досрочное обновление не должно превращать сбой Postgres в ошибку: запись в кэше ещё действует.
+		if cached != nil {
+			log.Warnf("OrderService.GetOrderByID: early refresh of %s failed, serving cached order: %v", orderID, err)
+			return *cached, nil
+		}
 		log.Infof("OrderService.GetOrderByID: error: %v", err)
 		return entity.Order{}, err
 	}
 
-	// Caching
-	_ = s.CacheRepo.Save(ctx, &ordFull)
-	return ordFull, nil
+	return v.(entity.Order), nil
+}
+
+// refreshEarly решает, перечитать ли заказ из Postgres до истечения TTL кэша (probabilistic early
+// expiration, XFetch): запись считается устаревшей, если -earlyRefreshDelta * ln(rnd) >= ttl.
+// Вероятность растёт по мере приближения к истечению, поэтому популярный заказ перечитывает один
+// запрос, а не все сразу в момент истечения ключа. Остальные читатели продолжают получать запись из кэша.
+func (s *Service) refreshEarly(ttl time.Duration) bool {
+	return refreshEarly(ttl, s.rand())
+}
+
+func refreshEarly(ttl time.Duration, rnd float64) bool {
+	if ttl <= 0 {
+		return false
+	}
+	return -float64(earlyRefreshDelta)*math.Log(rnd) >= float64(ttl)
 }
 
 func (s *Service) GetOrdersByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]entity.Order, int, error) {
@@ -397,14 +456,19 @@ func (s *Service) GetActiveOrdersByUser(ctx context.Context, userID uuid.UUID) (
 	log.Infof("OrderService.GetActiveOrdersByUser: userID = %v", userID)
 
 	ids, err := s.CacheRepo.GetUserActiveOrders(ctx, userID)
+	if err != nil {
+		// Redis недоступен (или breaker открыт) — отдаём активные заказы из Postgres.
+		log.Warnf("OrderService.GetActiveOrdersByUser: cache unavailable, reading from Postgres: %v", err)
+		return s.getActiveOrdersFromDB(ctx, userID)
+	}
 
-	if err == nil && len(ids) > 0 {
+	if len(ids) > 0 {
 		activeOrders := lo.FilterMap(ids, func(idStr string, _ int) (entity.Order, bool) {
 			id, err := uuid.Parse(idStr)
 			if err != nil {
 				return entity.Order{}, false
 			}
-			ord, err := s.CacheRepo.GetByID(ctx, id)
+			ord, _, err := s.CacheRepo.GetByID(ctx, id)
 			if err != nil || ord == nil {
 				return entity.Order{}, false
 			}
@@ -417,3 +481,15 @@ func (s *Service) GetActiveOrdersByUser(ctx context.Context, userID uuid.UUID) (
 
 	return nil, ErrNoActiveOrders
 }
+
+func (s *Service) getActiveOrdersFromDB(ctx context.Context, userID uuid.UUID) ([]entity.Order, error) {
+	orders, err := s.OrderRepo.GetActiveOrdersByUserID(ctx, userID)
+	if err != nil {
+		log.Errorf("OrderService.GetActiveOrdersByUser: error: %v", err)
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, ErrNoActiveOrders
+	}
+	return orders, nil
+}
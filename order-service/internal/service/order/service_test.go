@@ -3,6 +3,7 @@ package order_test
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/repository"
 	service "github.com/4udiwe/big-bob-pizza/order-service/internal/service/order"
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/service/order/mocks"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/transactor"
 	"github.com/google/uuid"
 	"go.uber.org/mock/gomock"
 )
@@ -45,7 +47,7 @@ func TestService_CreateOrder(t *testing.T) {
 			setup: func(orderRepo *mocks.MockOrderRepo, itemsRepo *mocks.MockItemsRepo, outboxRepo *mocks.MockOutboxRepo, cacheRepo *mocks.MockCacheRepo, tx *mock_transactor.MockTransactor) {
 				tx.EXPECT().
 					WithinTransaction(gomock.Any(), gomock.Any()).
-					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error, _ ...transactor.Option) error {
 						return fn(ctx)
 					})
 
@@ -60,7 +62,7 @@ func TestService_CreateOrder(t *testing.T) {
 			setup: func(orderRepo *mocks.MockOrderRepo, itemsRepo *mocks.MockItemsRepo, outboxRepo *mocks.MockOutboxRepo, cacheRepo *mocks.MockCacheRepo, tx *mock_transactor.MockTransactor) {
 				tx.EXPECT().
 					WithinTransaction(gomock.Any(), gomock.Any()).
-					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error, _ ...transactor.Option) error {
 						return fn(ctx)
 					})
 
@@ -75,7 +77,7 @@ func TestService_CreateOrder(t *testing.T) {
 			setup: func(orderRepo *mocks.MockOrderRepo, itemsRepo *mocks.MockItemsRepo, outboxRepo *mocks.MockOutboxRepo, cacheRepo *mocks.MockCacheRepo, tx *mock_transactor.MockTransactor) {
 				tx.EXPECT().
 					WithinTransaction(gomock.Any(), gomock.Any()).
-					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error, _ ...transactor.Option) error {
 						return fn(ctx)
 					})
 
@@ -102,7 +104,7 @@ func TestService_CreateOrder(t *testing.T) {
 			setup: func(orderRepo *mocks.MockOrderRepo, itemsRepo *mocks.MockItemsRepo, outboxRepo *mocks.MockOutboxRepo, cacheRepo *mocks.MockCacheRepo, tx *mock_transactor.MockTransactor) {
 				tx.EXPECT().
 					WithinTransaction(gomock.Any(), gomock.Any()).
-					DoAndReturn(func(ctx context.Context, fn func(context.Context) error) error {
+					DoAndReturn(func(ctx context.Context, fn func(context.Context) error, _ ...transactor.Option) error {
 						return fn(ctx)
 					})
 
@@ -212,7 +214,37 @@ func TestService_GetOrderByID(t *testing.T) {
 			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
 				cacheRepo.EXPECT().
 					GetByID(gomock.Any(), orderID).
-					Return(&order, nil)
+					Return(&order, time.Hour, nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "cache entry about to expire, refreshed early from postgres",
+			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
+				cacheRepo.EXPECT().
+					GetByID(gomock.Any(), orderID).
+					Return(&order, time.Nanosecond, nil)
+
+				orderRepo.EXPECT().
+					GetOrderByID(gomock.Any(), orderID).
+					Return(order, nil)
+
+				cacheRepo.EXPECT().
+					Save(gomock.Any(), &order).
+					Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "early refresh fails, cached order is served",
+			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
+				cacheRepo.EXPECT().
+					GetByID(gomock.Any(), orderID).
+					Return(&order, time.Nanosecond, nil)
+
+				orderRepo.EXPECT().
+					GetOrderByID(gomock.Any(), orderID).
+					Return(entity.Order{}, errors.New("connection refused"))
 			},
 			expectedErr: nil,
 		},
@@ -221,7 +253,7 @@ func TestService_GetOrderByID(t *testing.T) {
 			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
 				cacheRepo.EXPECT().
 					GetByID(gomock.Any(), orderID).
-					Return(nil, errors.New("not found"))
+					Return(nil, time.Duration(0), errors.New("not found"))
 
 				orderRepo.EXPECT().
 					GetOrderByID(gomock.Any(), orderID).
@@ -238,14 +270,27 @@ func TestService_GetOrderByID(t *testing.T) {
 			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
 				cacheRepo.EXPECT().
 					GetByID(gomock.Any(), orderID).
-					Return(nil, errors.New("not found"))
+					Return(nil, time.Duration(0), errors.New("not found"))
 
 				orderRepo.EXPECT().
 					GetOrderByID(gomock.Any(), orderID).
 					Return(entity.Order{}, repository.ErrOrderNotFound)
+
+				cacheRepo.EXPECT().
+					SaveNotFound(gomock.Any(), orderID).
+					Return(nil)
 			},
 			expectedErr: repository.ErrOrderNotFound,
 		},
+		{
+			name: "order cached as not found",
+			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
+				cacheRepo.EXPECT().
+					GetByID(gomock.Any(), orderID).
+					Return(nil, time.Duration(0), repository.ErrOrderNotFound)
+			},
+			expectedErr: service.ErrOrderNotFound,
+		},
 	}
 
 	for _, tt := range tests {
@@ -271,6 +316,53 @@ func TestService_GetOrderByID(t *testing.T) {
 	}
 }
 
+func TestService_GetOrderByID_coalescesMisses(t *testing.T) {
+	ctx := context.Background()
+	orderID := uuid.New()
+	order := entity.Order{ID: orderID}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cacheRepo := mocks.NewMockCacheRepo(ctrl)
+	orderRepo := mocks.NewMockOrderRepo(ctrl)
+	svc := service.NewService(orderRepo, mocks.NewMockItemsRepo(ctrl), mocks.NewMockOutboxRepo(ctrl), cacheRepo, mock_transactor.NewMockTransactor(ctrl))
+
+	const readers = 10
+	release := make(chan struct{})
+
+	cacheRepo.EXPECT().GetByID(gomock.Any(), orderID).Return(nil, time.Duration(0), nil).Times(readers)
+	orderRepo.EXPECT().
+		GetOrderByID(gomock.Any(), orderID).
+		DoAndReturn(func(context.Context, uuid.UUID) (entity.Order, error) {
+			<-release
+			return order, nil
+		}).
+		Times(1)
+	cacheRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	var wg sync.WaitGroup
+	var missed sync.WaitGroup
+	missed.Add(readers)
+	for i := 0; i < readers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			missed.Done()
+			got, err := svc.GetOrderByID(ctx, orderID)
+			if err != nil || got.ID != orderID {
+				t.Errorf("expected order %s, got %v, %v", orderID, got.ID, err)
+			}
+		}()
+	}
+
+	// Даём всем читателям дойти до ожидания общего запроса.
+	missed.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
+
 func TestService_GetOrdersByUser(t *testing.T) {
 	ctx := context.Background()
 	userID := uuid.New()
@@ -407,12 +499,12 @@ func TestService_GetActiveOrdersByUser(t *testing.T) {
 
 	tests := []struct {
 		name        string
-		setup       func(cacheRepo *mocks.MockCacheRepo)
+		setup       func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo)
 		expectedErr error
 	}{
 		{
 			name: "no active orders",
-			setup: func(cacheRepo *mocks.MockCacheRepo) {
+			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
 				cacheRepo.EXPECT().
 					GetUserActiveOrders(gomock.Any(), userID).
 					Return([]string{}, nil)
@@ -421,23 +513,54 @@ func TestService_GetActiveOrdersByUser(t *testing.T) {
 		},
 		{
 			name: "success with active orders",
-			setup: func(cacheRepo *mocks.MockCacheRepo) {
+			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
+				cacheRepo.EXPECT().
+					GetUserActiveOrders(gomock.Any(), userID).
+					Return([]string{orderID.String()}, nil)
+
+				cacheRepo.EXPECT().
+					GetByID(gomock.Any(), orderID).
+					Return(&order, time.Hour, nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			// Досрочное обновление касается только GetOrderByID: заказ у истечения TTL остаётся в списке.
+			name: "active order about to expire in cache",
+			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
 				cacheRepo.EXPECT().
 					GetUserActiveOrders(gomock.Any(), userID).
 					Return([]string{orderID.String()}, nil)
 
 				cacheRepo.EXPECT().
 					GetByID(gomock.Any(), orderID).
-					Return(&order, nil)
+					Return(&order, time.Nanosecond, nil)
 			},
 			expectedErr: nil,
 		},
 		{
-			name: "cache error",
-			setup: func(cacheRepo *mocks.MockCacheRepo) {
+			name: "cache unavailable, fallback to postgres",
+			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
 				cacheRepo.EXPECT().
 					GetUserActiveOrders(gomock.Any(), userID).
 					Return(nil, errors.New("cache error"))
+
+				orderRepo.EXPECT().
+					GetActiveOrdersByUserID(gomock.Any(), userID).
+					Return([]entity.Order{order}, nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "cache unavailable, no active orders in postgres",
+			setup: func(cacheRepo *mocks.MockCacheRepo, orderRepo *mocks.MockOrderRepo) {
+				cacheRepo.EXPECT().
+					GetUserActiveOrders(gomock.Any(), userID).
+					Return(nil, errors.New("cache error"))
+
+				orderRepo.EXPECT().
+					GetActiveOrdersByUserID(gomock.Any(), userID).
+					Return([]entity.Order{}, nil)
 			},
 			expectedErr: service.ErrNoActiveOrders,
 		},
@@ -456,7 +579,7 @@ func TestService_GetActiveOrdersByUser(t *testing.T) {
 
 			svc := service.NewService(orderRepo, itemsRepo, outboxRepo, cacheRepo, tx)
 
-			tt.setup(cacheRepo)
+			tt.setup(cacheRepo, orderRepo)
 
 			_, err := svc.GetActiveOrdersByUser(ctx, userID)
 			if !errors.Is(err, tt.expectedErr) && (tt.expectedErr == nil || err == nil || err.Error() != tt.expectedErr.Error()) {
@@ -0,0 +1,8 @@
+package outbox
+
+import "errors"
+
+var (
+	ErrEventNotFound  = errors.New("outbox event not found")
+	ErrEmptySelection = errors.New("selection is empty: specify ids or filter")
+)
@@ -0,0 +1,180 @@
+package configloader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"gopkg.in/yaml.v3"
+)
+
+// FileSuffix — суффикс переменной окружения, в которой вместо значения передаётся путь к файлу
+// с ним: POSTGRES_URL_FILE=/run/secrets/postgres_url. Так секреты не попадают в окружение процесса.
+const FileSuffix = "_FILE"
+
+// Load читает конфигурацию в cfg (указатель на структуру) и проверяет её:
+//
+//  1. YAML-файл path; неизвестные ключи — ошибка, чтобы опечатка не превращалась в значение по умолчанию;
+//  2. переменные X_FILE: содержимое файла записывается в поле с тегом env:"X", если X не задана;
+//  3. переменные окружения (cleanenv);
+//  4. Validate.
+func Load(path string, cfg any) error {
+	if path == "" {
+		return errors.New("configloader - Load: config path is empty, set CONFIG_PATH")
+	}
+
+	if err := parseFile(path, cfg); err != nil {
+		return err
+	}
+
+	if err := readFiles(cfg); err != nil {
+		return fmt.Errorf("configloader - Load - readFiles: %w", err)
+	}
+
+	if err := cleanenv.ReadEnv(cfg); err != nil {
+		return fmt.Errorf("configloader - Load - cleanenv.ReadEnv: %w", err)
+	}
+
+	return Validate(cfg)
+}
+
+func parseFile(path string, cfg any) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("configloader - Load - os.Open: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("configloader - Load - parse %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readFiles заполняет поля из файлов, указанных в переменных X_FILE.
+func readFiles(cfg any) error {
+	for _, f := range fields(cfg) {
+		for _, env := range f.envs {
+			path := os.Getenv(env + FileSuffix)
+			if path == "" {
+				continue
+			}
+			if _, ok := os.LookupEnv(env); ok {
+				return fmt.Errorf("both %s and %s%s are set, use one of them", env, env, FileSuffix)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("%s%s: %w", env, FileSuffix, err)
+			}
+			// Редакторы и `echo` добавляют перевод строки в конец файла — в значении он не нужен.
+			value := strings.TrimRight(string(data), "\r\n")
+
+			if err := setString(f.value, value, f.separator); err != nil {
+				return fmt.Errorf("%s%s: %w", env, FileSuffix, err)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+func setString(v reflect.Value, value, separator string) error {
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		parts := strings.Split(value, separator)
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			s.Index(i).SetString(strings.TrimSpace(p))
+		}
+		v.Set(s)
+	default:
+		return fmt.Errorf("field of type %s cannot be read from a file", v.Type())
+	}
+
+	return nil
+}
+
+// field — конечное поле конфигурации.
+type field struct {
+	// path — путь в YAML: "postgres.url".
+	path      string
+	envs      []string
+	separator string
+	secret    bool
+	value     reflect.Value
+}
+
+// fields обходит вложенные структуры cfg так же, как cleanenv: с учётом env-prefix,
+// без захода в time.Time и элементы слайсов.
+func fields(cfg any) []field {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	var out []field
+	walk(v, "", "", &out)
+	return out
+}
+
+func walk(v reflect.Value, path, envPrefix string, out *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := yamlName(sf)
+		if name == "-" {
+			continue
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Time{}) {
+			walk(fv, name, envPrefix+sf.Tag.Get(cleanenv.TagEnvPrefix), out)
+			continue
+		}
+
+		f := field{
+			path:      name,
+			separator: cleanenv.DefaultSeparator,
+			secret:    sf.Tag.Get("secret") == "true",
+			value:     fv,
+		}
+		if sep, ok := sf.Tag.Lookup(cleanenv.TagEnvSeparator); ok {
+			f.separator = sep
+		}
+		if envs := sf.Tag.Get(cleanenv.TagEnv); envs != "" {
+			for _, env := range strings.Split(envs, cleanenv.DefaultSeparator) {
+				f.envs = append(f.envs, envPrefix+env)
+			}
+		}
+
+		*out = append(*out, f)
+	}
+}
+
+// yamlName возвращает имя поля в YAML по правилам yaml.v3: без тега — имя поля в нижнем регистре.
+func yamlName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(sf.Name)
+	}
+	return name
+}
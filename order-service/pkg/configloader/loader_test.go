@@ -0,0 +1,148 @@
+package configloader
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfig struct {
+	HTTP struct {
+		Port    string        `yaml:"port" env:"TEST_HTTP_PORT" validate:"required,numeric"`
+		Timeout time.Duration `yaml:"timeout" env:"TEST_HTTP_TIMEOUT"`
+	} `yaml:"http"`
+	Postgres struct {
+		URL         string   `yaml:"url" env:"TEST_POSTGRES_URL" validate:"required" secret:"true"`
+		ReplicaURLs []string `yaml:"replica_urls" env:"TEST_POSTGRES_REPLICA_URLS" secret:"true"`
+	} `yaml:"postgres"`
+	Redis struct {
+		Mode       string `yaml:"mode" env:"TEST_REDIS_MODE" validate:"omitempty,oneof=standalone sentinel"`
+		MasterName string `yaml:"master_name" env:"TEST_REDIS_MASTER_NAME"`
+		Password   string `yaml:"password" env:"TEST_REDIS_PASSWORD" secret:"true"`
+	} `yaml:"redis"`
+}
+
+func (c *testConfig) Validate() error {
+	var errs []error
+	if c.Redis.Mode == "sentinel" && c.Redis.MasterName == "" {
+		errs = append(errs, errors.New(`redis.master_name: is required when redis.mode is "sentinel"`))
+	}
+	return errors.Join(errs...)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+const validYAML = `
+http:
+  port: "8080"
+  timeout: 4s
+postgres:
+  url: "postgres://app:secret@db:5432/orders?sslmode=disable"
+`
+
+func TestLoad_FileAndEnv(t *testing.T) {
+	t.Setenv("TEST_HTTP_PORT", "9090")
+
+	var cfg testConfig
+	require.NoError(t, Load(writeFile(t, "config.yaml", validYAML), &cfg))
+
+	assert.Equal(t, "9090", cfg.HTTP.Port)
+	assert.Equal(t, 4*time.Second, cfg.HTTP.Timeout)
+	assert.Equal(t, "postgres://app:secret@db:5432/orders?sslmode=disable", cfg.Postgres.URL)
+}
+
+func TestLoad_SecretFromFile(t *testing.T) {
+	t.Setenv("TEST_POSTGRES_URL_FILE", writeFile(t, "postgres_url", "postgres://app:fromfile@db/orders\n"))
+	t.Setenv("TEST_POSTGRES_REPLICA_URLS_FILE", writeFile(t, "replicas", "postgres://r1/orders,postgres://r2/orders\n"))
+
+	var cfg testConfig
+	require.NoError(t, Load(writeFile(t, "config.yaml", "http:\n  port: \"8080\"\n"), &cfg))
+
+	assert.Equal(t, "postgres://app:fromfile@db/orders", cfg.Postgres.URL)
+	assert.Equal(t, []string{"postgres://r1/orders", "postgres://r2/orders"}, cfg.Postgres.ReplicaURLs)
+}
+
+func TestLoad_ValueAndFileConflict(t *testing.T) {
+	t.Setenv("TEST_POSTGRES_URL", "postgres://db/orders")
+	t.Setenv("TEST_POSTGRES_URL_FILE", writeFile(t, "postgres_url", "postgres://db/other"))
+
+	var cfg testConfig
+	err := Load(writeFile(t, "config.yaml", validYAML), &cfg)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "both TEST_POSTGRES_URL and TEST_POSTGRES_URL_FILE are set")
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	var cfg testConfig
+	err := Load(filepath.Join(t.TempDir(), "missing.yaml"), &cfg)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoad_UnknownKey(t *testing.T) {
+	var cfg testConfig
+	err := Load(writeFile(t, "config.yaml", validYAML+"redis:\n  mater_name: main\n"), &cfg)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field mater_name not found")
+}
+
+func TestLoad_ValidationErrors(t *testing.T) {
+	yaml := "http:\n  port: \"http\"\nredis:\n  mode: \"sentinel\"\n"
+
+	var cfg testConfig
+	err := Load(writeFile(t, "config.yaml", yaml), &cfg)
+
+	var cfgErr *Error
+	require.ErrorAs(t, err, &cfgErr)
+	assert.Equal(t, []string{
+		`http.port: must be a number, got "http"`,
+		"postgres.url: is required (set it in the config file or via TEST_POSTGRES_URL / TEST_POSTGRES_URL_FILE)",
+		`redis.master_name: is required when redis.mode is "sentinel"`,
+	}, cfgErr.Problems)
+}
+
+func TestPrint_Redacted(t *testing.T) {
+	var cfg testConfig
+	cfg.HTTP.Port = "8080"
+	cfg.Postgres.URL = "postgres://app:secret@db:5432/orders?sslmode=disable"
+	cfg.Postgres.ReplicaURLs = []string{"host=replica password=secret"}
+	cfg.Redis.Password = "hunter2"
+
+	var buf bytes.Buffer
+	require.NoError(t, Print(&buf, &cfg, true))
+
+	out := buf.String()
+	assert.NotContains(t, out, "secret")
+	assert.NotContains(t, out, "hunter2")
+	assert.Contains(t, out, "postgres://app:xxxxx@db:5432/orders?sslmode=disable")
+	assert.Contains(t, out, `port: "8080"`)
+	assert.Equal(t, "postgres://app:secret@db:5432/orders?sslmode=disable", cfg.Postgres.URL)
+}
+
+func TestCommand(t *testing.T) {
+	load := func() (any, error) {
+		cfg := &testConfig{}
+		cfg.Redis.Password = "hunter2"
+		return cfg, nil
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Command(&buf, []string{"print", "--redacted"}, load))
+	assert.Contains(t, buf.String(), redactedValue)
+
+	assert.EqualError(t, Command(&buf, []string{"dump"}, load), commandUsage)
+}
@@ -0,0 +1,144 @@
+package configloader
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Validator — проверки, которые не выражаются тегами validate: зависимости между полями.
+// Ошибки, собранные через errors.Join, выводятся по одной на строку.
+type Validator interface {
+	Validate() error
+}
+
+// Error — конфигурация не прошла проверку. Problems — по одной строке на нарушение.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+var validate = newValidate()
+
+func newValidate() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(sf reflect.StructField) string {
+		name := yamlName(sf)
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// Validate проверяет теги validate (поля называются путями в YAML) и, если cfg реализует Validator,
+// зависимости между полями. Все нарушения собираются в один *Error.
+func Validate(cfg any) error {
+	var problems []string
+
+	if err := validate.Struct(cfg); err != nil {
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			return fmt.Errorf("configloader - Validate - validate.Struct: %w", err)
+		}
+
+		envs := make(map[string][]string)
+		for _, f := range fields(cfg) {
+			envs[f.path] = f.envs
+		}
+
+		for _, fe := range verrs {
+			problems = append(problems, describe(fe, envs))
+		}
+	}
+
+	if v, ok := cfg.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			problems = append(problems, split(err)...)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return &Error{Problems: problems}
+}
+
+func describe(fe validator.FieldError, envs map[string][]string) string {
+	// Namespace — "Config.postgres.url": корневую структуру отрезаем.
+	_, path, _ := strings.Cut(fe.Namespace(), ".")
+
+	msg := path + ": " + message(fe)
+
+	if fe.Tag() == "required" {
+		key, _, _ := strings.Cut(path, "[")
+		if names := envs[key]; len(names) > 0 {
+			hints := make([]string, 0, len(names))
+			for _, env := range names {
+				hints = append(hints, env)
+				if fe.Kind() == reflect.String || fe.Kind() == reflect.Slice {
+					hints = append(hints, env+FileSuffix)
+				}
+			}
+			msg += " (set it in the config file or via " + strings.Join(hints, " / ") + ")"
+		}
+	}
+
+	return msg
+}
+
+func message(fe validator.FieldError) string {
+	isLen := fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s], got %q", fe.Param(), fmt.Sprint(fe.Value()))
+	case "min", "gte":
+		if isLen {
+			return fmt.Sprintf("must contain at least %s item(s)", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s, got %v", fe.Param(), fe.Value())
+	case "max", "lte":
+		if isLen {
+			return fmt.Sprintf("must contain at most %s item(s)", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s, got %v", fe.Param(), fe.Value())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s, got %v", fe.Param(), fe.Value())
+	case "url":
+		return "must be a valid URL"
+	case "http_url":
+		return fmt.Sprintf("must be an http(s) URL, got %q", fmt.Sprint(fe.Value()))
+	case "hostname_port":
+		return fmt.Sprintf("must be host:port, got %q", fmt.Sprint(fe.Value()))
+	case "numeric":
+		return fmt.Sprintf("must be a number, got %q", fmt.Sprint(fe.Value()))
+	case "startswith":
+		return fmt.Sprintf("must start with %q", fe.Param())
+	default:
+		return fmt.Sprintf("failed %q check", fe.Tag())
+	}
+}
+
+// split раскладывает ошибку errors.Join на отдельные строки.
+func split(err error) []string {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var out []string
+		for _, e := range joined.Unwrap() {
+			out = append(out, split(e)...)
+		}
+		return out
+	}
+
+	return []string{err.Error()}
+}
@@ -0,0 +1,151 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы всех событий из docs/EVENT_CATALOG.md.
+const (
+	TypeOrderCreated    = "order.created"
+	TypeOrderPaid       = "order.paid"
+	TypeOrderCancelled  = "order.cancelled"
+	TypeOrderPrepared   = "order.prepeared"
+	TypeOrderDelivering = "order.delivering"
+	TypeOrderCompleted  = "order.completed"
+
+	TypePaymentSucceeded = "payment.success"
+	TypePaymentFailed    = "payment.failed"
+
+	TypeKitchenAccepted        = "kitchen.accepted"
+	TypeKitchenReady           = "kitchen.ready"
+	TypeKitchenHandedToCourier = "kitchen.handedToCourier"
+
+	TypeDeliveryAssigned  = "delivery.assigned"
+	TypeDeliveryCompleted = "delivery.completed"
+)
+
+// Event — payload доменного события, публикуемого в Kafka.
+// EventType возвращает тип события, под которым payload лежит в Envelope.
+type Event interface {
+	EventType() string
+}
+
+// ================================
+//  order.events
+// ================================
+
+// OrderCreated — создан новый заказ пользователем.
+type OrderCreated struct {
+	OrderID    uuid.UUID `json:"orderId"`
+	UserID     uuid.UUID `json:"userId"`
+	TotalPrice float64   `json:"totalPrice"`
+}
+
+func (OrderCreated) EventType() string { return TypeOrderCreated }
+
+// OrderPaid — заказ оплачен.
+type OrderPaid struct {
+	OrderID   uuid.UUID `json:"orderId"`
+	PaymentID uuid.UUID `json:"paymentId"`
+}
+
+func (OrderPaid) EventType() string { return TypeOrderPaid }
+
+// OrderCancelled — заказ отменён.
+type OrderCancelled struct {
+	OrderID uuid.UUID `json:"orderId"`
+	Reason  string    `json:"reason,omitempty"`
+}
+
+func (OrderCancelled) EventType() string { return TypeOrderCancelled }
+
+// OrderPrepared — заказ приготовлен кухней.
+type OrderPrepared struct {
+	OrderID uuid.UUID `json:"orderId"`
+}
+
+func (OrderPrepared) EventType() string { return TypeOrderPrepared }
+
+// OrderDelivering — заказ передан курьеру.
+type OrderDelivering struct {
+	OrderID uuid.UUID `json:"orderId"`
+}
+
+func (OrderDelivering) EventType() string { return TypeOrderDelivering }
+
+// OrderCompleted — заказ доставлен и завершён.
+type OrderCompleted struct {
+	OrderID uuid.UUID `json:"orderId"`
+}
+
+func (OrderCompleted) EventType() string { return TypeOrderCompleted }
+
+// ================================
+//  payment.events
+// ================================
+
+// PaymentSucceeded — оплата заказа прошла успешно.
+type PaymentSucceeded struct {
+	PaymentID uuid.UUID `json:"paymentId"`
+	OrderID   uuid.UUID `json:"orderId"`
+	Amount    float64   `json:"amount"`
+}
+
+func (PaymentSucceeded) EventType() string { return TypePaymentSucceeded }
+
+// PaymentFailed — оплата заказа не прошла.
+type PaymentFailed struct {
+	PaymentID uuid.UUID `json:"paymentId"`
+	OrderID   uuid.UUID `json:"orderId"`
+	Reason    string    `json:"reason"`
+}
+
+func (PaymentFailed) EventType() string { return TypePaymentFailed }
+
+// ================================
+//  kitchen.events
+// ================================
+
+// KitchenAccepted — заказ принят в готовку.
+type KitchenAccepted struct {
+	OrderID uuid.UUID `json:"orderId"`
+}
+
+func (KitchenAccepted) EventType() string { return TypeKitchenAccepted }
+
+// KitchenReady — заказ полностью готов.
+type KitchenReady struct {
+	OrderID uuid.UUID `json:"orderId"`
+}
+
+func (KitchenReady) EventType() string { return TypeKitchenReady }
+
+// KitchenHandedToCourier — заказ передан курьеру.
+type KitchenHandedToCourier struct {
+	OrderID    uuid.UUID  `json:"orderId"`
+	DeliveryID *uuid.UUID `json:"deliveryId,omitempty"`
+}
+
+func (KitchenHandedToCourier) EventType() string { return TypeKitchenHandedToCourier }
+
+// ================================
+//  delivery.events
+// ================================
+
+// DeliveryAssigned — на заказ назначен курьер.
+type DeliveryAssigned struct {
+	OrderID   uuid.UUID `json:"orderId"`
+	CourierID uuid.UUID `json:"courierId"`
+}
+
+func (DeliveryAssigned) EventType() string { return TypeDeliveryAssigned }
+
+// DeliveryCompleted — заказ доставлен.
+type DeliveryCompleted struct {
+	OrderID     uuid.UUID `json:"orderId"`
+	DeliveredAt time.Time `json:"deliveredAt"`
+}
+
+func (DeliveryCompleted) EventType() string { return TypeDeliveryCompleted }
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrUnknownEventType = errors.New("events: unknown event type")
+
+// registry сопоставляет тип события с конструктором его payload.
+var registry = map[string]func() Event{
+	TypeOrderCreated:    func() Event { return &OrderCreated{} },
+	TypeOrderPaid:       func() Event { return &OrderPaid{} },
+	TypeOrderCancelled:  func() Event { return &OrderCancelled{} },
+	TypeOrderPrepared:   func() Event { return &OrderPrepared{} },
+	TypeOrderDelivering: func() Event { return &OrderDelivering{} },
+	TypeOrderCompleted:  func() Event { return &OrderCompleted{} },
+
+	TypePaymentSucceeded: func() Event { return &PaymentSucceeded{} },
+	TypePaymentFailed:    func() Event { return &PaymentFailed{} },
+
+	TypeKitchenAccepted:        func() Event { return &KitchenAccepted{} },
+	TypeKitchenReady:           func() Event { return &KitchenReady{} },
+	TypeKitchenHandedToCourier: func() Event { return &KitchenHandedToCourier{} },
+
+	TypeDeliveryAssigned:  func() Event { return &DeliveryAssigned{} },
+	TypeDeliveryCompleted: func() Event { return &DeliveryCompleted{} },
+}
+
+// New возвращает указатель на пустой payload события eventType.
+func New(eventType string) (Event, error) {
+	ctor, ok := registry[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+	return ctor(), nil
+}
+
+// Decode разбирает payload события eventType в соответствующую типизированную структуру.
+// Возвращается указатель на структуру, например *OrderCreated.
+func Decode(eventType string, data []byte) (Event, error) {
+	ev, err := New(eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, ev); err != nil {
+		return nil, fmt.Errorf("events - Decode - %s: %w", eventType, err)
+	}
+
+	return ev, nil
+}
+
+// Types возвращает отсортированный список всех известных типов событий.
+func Types() []string {
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
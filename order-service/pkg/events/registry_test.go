@@ -0,0 +1,61 @@
+package events_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonFields(v any) []string {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+
+	return fields
+}
+
+func TestRegistry_MatchesSchemas(t *testing.T) {
+	require.Equal(t, schema.Default().EventTypes(), events.Types())
+}
+
+func TestRegistry_FieldsMatchSchemas(t *testing.T) {
+	for _, eventType := range events.Types() {
+		ev, err := events.New(eventType)
+		require.NoError(t, err)
+		require.Equal(t, eventType, ev.EventType())
+
+		latest, err := schema.Default().Latest(eventType)
+		require.NoError(t, err)
+
+		fields, err := schema.Default().Fields(eventType, latest)
+		require.NoError(t, err)
+
+		require.Equal(t, fields, jsonFields(ev), "%s: struct fields differ from schema v%d", eventType, latest)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	ev, err := events.Decode(events.TypeOrderCreated,
+		[]byte(`{"orderId": "0b8f4a52-8a0b-4c1e-9d36-0d6f1f0c6a11", "userId": "6f1b8a20-3c55-4d7e-8f0a-2b9c1d3e4f5a", "totalPrice": 100}`))
+	require.NoError(t, err)
+
+	created, ok := ev.(*events.OrderCreated)
+	require.True(t, ok)
+	require.Equal(t, 100.0, created.TotalPrice)
+
+	_, err = events.Decode("unknown.event", []byte(`{}`))
+	require.ErrorIs(t, err, events.ErrUnknownEventType)
+}
@@ -0,0 +1,205 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultCacheTTL     = time.Second
+	defaultCheckTimeout = time.Second
+)
+
+// Статусы проверки и отчёта.
+const (
+	StatusOK = "ok"
+	// StatusDegraded — не прошла некритичная проверка: сервис работает, но без части возможностей
+	// (например, без кэша Redis). На готовность это не влияет.
+	StatusDegraded = "degraded"
+	StatusFail     = "fail"
+)
+
+// ErrDraining — сервис останавливается и больше не принимает трафик.
+var ErrDraining = errors.New("health: service is shutting down")
+
+// CheckFunc проверяет одну зависимость. Контекст ограничен таймаутом проверки.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	critical bool
+}
+
+// CheckResult — результат одной проверки в отчёте.
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report — тело ответа /livez и /readyz.
+type Report struct {
+	Status    string        `json:"status"`
+	CheckedAt time.Time     `json:"checkedAt"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// probe — набор проверок одной пробы с кэшем последнего отчёта.
+type probe struct {
+	checks []check
+
+	mu     sync.Mutex
+	report *Report
+}
+
+// Health выполняет проверки liveness и readiness.
+//
+// Liveness — «процесс жив и не завис»: сюда относятся только проверки, которые лечатся перезапуском
+// (например, зависший цикл чтения консьюмера). Недоступность Postgres перезапуском не лечится,
+// поэтому зависимости проверяются только в readiness.
+//
+// Результаты кэшируются на CacheTTL: частые пробы от нескольких источников не нагружают зависимости.
+type Health struct {
+	live  probe
+	ready probe
+
+	cacheTTL     time.Duration
+	checkTimeout time.Duration
+
+	draining atomic.Bool
+}
+
+func New(opts ...Option) *Health {
+	h := &Health{
+		cacheTTL:     defaultCacheTTL,
+		checkTimeout: defaultCheckTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// AddLivenessCheck регистрирует проверку liveness. Проверки регистрируются до запуска HTTP-сервера.
+func (h *Health) AddLivenessCheck(name string, fn CheckFunc) {
+	h.live.checks = append(h.live.checks, check{name: name, fn: fn, critical: true})
+}
+
+// AddReadinessCheck регистрирует критичную проверку readiness: пока она не проходит, сервис не готов.
+func (h *Health) AddReadinessCheck(name string, fn CheckFunc) {
+	h.ready.checks = append(h.ready.checks, check{name: name, fn: fn, critical: true})
+}
+
+// AddOptionalReadinessCheck регистрирует некритичную проверку readiness: её ошибка переводит
+// отчёт в degraded, но сервис остаётся готовым.
+func (h *Health) AddOptionalReadinessCheck(name string, fn CheckFunc) {
+	h.ready.checks = append(h.ready.checks, check{name: name, fn: fn})
+}
+
+// Drain переводит сервис в состояние «не готов» до конца работы процесса: балансировщик
+// перестаёт направлять запросы, пока сервис дообрабатывает начатую работу.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Live — обработчик /livez: 200, если все проверки liveness прошли, иначе 503.
+func (h *Health) Live(c echo.Context) error {
+	report := h.run(c.Request().Context(), &h.live)
+	return respond(c, report)
+}
+
+// Ready — обработчик /readyz: 200, если прошли все критичные проверки и сервис не останавливается.
+func (h *Health) Ready(c echo.Context) error {
+	if h.draining.Load() {
+		return respond(c, &Report{
+			Status:    StatusFail,
+			CheckedAt: time.Now().UTC(),
+			Checks:    []CheckResult{{Name: "shutdown", Status: StatusFail, Error: ErrDraining.Error()}},
+		})
+	}
+
+	report := h.run(c.Request().Context(), &h.ready)
+	return respond(c, report)
+}
+
+// run возвращает закэшированный отчёт или выполняет проверки параллельно. Мьютекс держится
+// на время проверок, чтобы одновременные пробы не запускали их повторно.
+func (h *Health) run(ctx context.Context, p *probe) *Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.report != nil && time.Since(p.report.CheckedAt) < h.cacheTTL {
+		return p.report
+	}
+
+	// Проверка не должна обрываться из-за того, что клиент пробы отвалился: её результат
+	// попадёт в кэш и достанется следующей пробе.
+	ctx = context.WithoutCancel(ctx)
+
+	results := make([]CheckResult, len(p.checks))
+	var wg sync.WaitGroup
+	for i, c := range p.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.runCheck(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, CheckedAt: time.Now().UTC(), Checks: results}
+	for i, r := range results {
+		if r.Status == StatusOK {
+			continue
+		}
+		if p.checks[i].critical {
+			report.Status = StatusFail
+			break
+		}
+		report.Status = StatusDegraded
+	}
+
+	p.report = report
+	return report
+}
+
+func (h *Health) runCheck(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, h.checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.fn(ctx)
+	res := CheckResult{
+		Name:      c.name,
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		res.Status = StatusFail
+		if !c.critical {
+			res.Status = StatusDegraded
+		}
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+func respond(c echo.Context, report *Report) error {
+	status := http.StatusOK
+	if report.Status == StatusFail {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func call(t *testing.T, handler echo.HandlerFunc) (int, Report) {
+	t.Helper()
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	require.NoError(t, handler(c))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return rec.Code, report
+}
+
+func ok(context.Context) error { return nil }
+
+func TestReady_AllChecksPass(t *testing.T) {
+	h := New()
+	h.AddReadinessCheck("postgres", ok)
+	h.AddReadinessCheck("kafka", ok)
+
+	code, report := call(t, h.Ready)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusOK, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "postgres", report.Checks[0].Name)
+}
+
+func TestReady_CriticalCheckFails(t *testing.T) {
+	h := New()
+	h.AddReadinessCheck("postgres", func(context.Context) error { return errors.New("connection refused") })
+
+	code, report := call(t, h.Ready)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+}
+
+func TestReady_OptionalCheckDegrades(t *testing.T) {
+	h := New()
+	h.AddReadinessCheck("postgres", ok)
+	h.AddOptionalReadinessCheck("redis", func(context.Context) error { return errors.New("circuit open") })
+
+	code, report := call(t, h.Ready)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.Equal(t, StatusDegraded, report.Checks[1].Status)
+}
+
+func TestReady_Draining(t *testing.T) {
+	h := New()
+	h.AddReadinessCheck("postgres", ok)
+	h.Drain()
+
+	code, report := call(t, h.Ready)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusFail, report.Status)
+}
+
+func TestLive_IgnoresReadinessChecks(t *testing.T) {
+	h := New()
+	h.AddReadinessCheck("postgres", func(context.Context) error { return errors.New("down") })
+	h.Drain()
+
+	code, _ := call(t, h.Live)
+
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestResultsAreCached(t *testing.T) {
+	var calls atomic.Int32
+	h := New(CacheTTL(time.Minute))
+	h.AddReadinessCheck("postgres", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	call(t, h.Ready)
+	call(t, h.Ready)
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCheckTimeout(t *testing.T) {
+	h := New(CheckTimeout(10 * time.Millisecond))
+	h.AddReadinessCheck("kafka", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	code, report := call(t, h.Ready)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Contains(t, report.Checks[0].Error, "deadline exceeded")
+}
@@ -0,0 +1,24 @@
+package health
+
+import "time"
+
+// Option -.
+type Option func(*Health)
+
+// CacheTTL задаёт, сколько переиспользуется результат проверок.
+func CacheTTL(d time.Duration) Option {
+	return func(h *Health) {
+		if d > 0 {
+			h.cacheTTL = d
+		}
+	}
+}
+
+// CheckTimeout ограничивает время одной проверки: зависшая зависимость считается недоступной.
+func CheckTimeout(d time.Duration) Option {
+	return func(h *Health) {
+		if d > 0 {
+			h.checkTimeout = d
+		}
+	}
+}
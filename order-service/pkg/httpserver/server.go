@@ -53,6 +53,15 @@ func (s *Server) Notify() <-chan error {
 	return s.notify
 }
 
+// Stop перестаёт принимать соединения и дожидается завершения текущих запросов,
+// но не дольше ctx и shutdownTimeout.
+func (s *Server) Stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.shutdownTimeout)
+	defer cancel()
+
+	return s.server.Shutdown(ctx)
+}
+
 // Shutdown -.
 func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
@@ -0,0 +1,194 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Message — сообщение брокера, не зависящее от конкретной реализации.
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Time      time.Time
+}
+
+// ReaderConfig — параметры чтения топика в составе consumer group.
+// Нулевые значения таймаутов означают значения по умолчанию реализации.
+type ReaderConfig struct {
+	Topic   string
+	GroupID string
+	// MaxWait — сколько брокер ждёт новых сообщений перед ответом на fetch.
+	MaxWait time.Duration
+	// SessionTimeout — через сколько без heartbeat участник исключается из группы.
+	SessionTimeout time.Duration
+	// HeartbeatInterval — как часто участник подтверждает членство в группе.
+	HeartbeatInterval time.Duration
+}
+
+// Reader читает один топик в составе consumer group.
+//
+// Семантика повторяет kafka-go:
+//   - FetchMessage возвращает следующее сообщение из назначенных ридеру партиций;
+//   - offset сохраняется только через CommitMessages;
+//   - после перебалансировки или переподключения чтение продолжается с последнего закоммиченного offset,
+//     поэтому незакоммиченные сообщения доставляются повторно;
+//   - после Close FetchMessage возвращает io.EOF.
+type Reader interface {
+	FetchMessage(ctx context.Context) (Message, error)
+	CommitMessages(ctx context.Context, msgs ...Message) error
+	Close() error
+}
+
+// LagReader — Reader, который знает, сколько сообщений назначенных ему партиций ещё не прочитано.
+// KafkaConsumer периодически публикует это значение в метрику kafka_consumer_lag.
+type LagReader interface {
+	Lag() int64
+}
+
+// Broker — транспорт, поверх которого работают KafkaPublisher и KafkaConsumer.
+// Основная реализация — Kafka (NewKafkaBroker); для тестов и локального запуска
+// есть in-memory и файловая реализации в pkg/kafka/localbroker.
+type Broker interface {
+	WriteMessages(ctx context.Context, msgs ...Message) error
+	NewReader(cfg ReaderConfig) Reader
+	Close() error
+}
+
+// kafkaBroker — реализация Broker поверх kafka-go.
+type kafkaBroker struct {
+	brokers  []string
+	producer ProducerConfig
+	writer   *kafka.Writer
+}
+
+// NewKafkaBroker создаёт Broker, работающий с реальным кластером Kafka.
+// Без опции BrokerProducer сообщения пишутся с подтверждением от лидера партиции и без сжатия.
+func NewKafkaBroker(brokers []string, opts ...BrokerOption) Broker {
+	b := &kafkaBroker{
+		brokers:  brokers,
+		producer: ProducerConfig{RequiredAcks: 1},
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.writer = b.producer.newWriter(brokers)
+
+	return b
+}
+
+func (b *kafkaBroker) WriteMessages(ctx context.Context, msgs ...Message) error {
+	out := make([]kafka.Message, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, kafka.Message{
+			Topic: m.Topic,
+			Key:   m.Key,
+			Value: m.Value,
+			Time:  m.Time,
+		})
+	}
+
+	return b.writer.WriteMessages(ctx, out...)
+}
+
+func (b *kafkaBroker) NewReader(cfg ReaderConfig) Reader {
+	return &kafkaReader{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:           b.brokers,
+			GroupID:           cfg.GroupID,
+			Topic:             cfg.Topic,
+			MinBytes:          10e3,
+			MaxBytes:          10e6,
+			MaxWait:           cfg.MaxWait,
+			SessionTimeout:    cfg.SessionTimeout,
+			HeartbeatInterval: cfg.HeartbeatInterval,
+			// Сообщения отменённых транзакций (ProducerModeTransactional) не читаются.
+			IsolationLevel: kafka.ReadCommitted,
+			// Коммиты синхронные: KafkaConsumer сам объединяет их раз в CommitInterval.
+			CommitInterval: 0,
+		}),
+	}
+}
+
+func (b *kafkaBroker) Close() error {
+	return b.writer.Close()
+}
+
+// kafkaReader адаптирует kafka.Reader к интерфейсу Reader.
+type kafkaReader struct {
+	reader *kafka.Reader
+}
+
+func (r *kafkaReader) FetchMessage(ctx context.Context) (Message, error) {
+	m, err := r.reader.FetchMessage(ctx)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Topic:     m.Topic,
+		Partition: m.Partition,
+		Offset:    m.Offset,
+		Key:       m.Key,
+		Value:     m.Value,
+		Time:      m.Time,
+	}, nil
+}
+
+func (r *kafkaReader) CommitMessages(ctx context.Context, msgs ...Message) error {
+	out := make([]kafka.Message, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, kafka.Message{
+			Topic:     m.Topic,
+			Partition: m.Partition,
+			Offset:    m.Offset,
+		})
+	}
+
+	return r.reader.CommitMessages(ctx, out...)
+}
+
+// Lag возвращает lag из статистики kafka-go: его обновляет фоновое чтение ридера
+// по high water mark каждой полученной от брокера пачки.
+func (r *kafkaReader) Lag() int64 {
+	return r.reader.Stats().Lag
+}
+
+func (r *kafkaReader) Close() error {
+	return r.reader.Close()
+}
+
+// Ping запрашивает метаданные кластера у первого доступного брокера из списка.
+func Ping(ctx context.Context, brokers []string) error {
+	var errs []error
+	for _, addr := range brokers {
+		conn, err := (&kafka.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		_, err = conn.Brokers()
+		_ = conn.Close()
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return errors.New("kafka - Ping: no brokers configured")
+	}
+	return fmt.Errorf("kafka - Ping: %w", errors.Join(errs...))
+}
@@ -2,92 +2,534 @@ package kafka
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 )
 
-// KafkaConsumer — тонкая обёртка над kafka-go Reader.
-// Позволяет подписаться на один топик и обрабатывать сообщения коллбеком.
+const (
+	defaultConcurrency = 1
+	defaultBatchSize   = 1
+	// defaultRetryBackoff, defaultRetryMaxBackoff — пауза перед повтором пачки, на которой
+	// упал обработчик; удваивается с каждой попыткой.
+	defaultRetryBackoff    = 500 * time.Millisecond
+	defaultRetryMaxBackoff = 30 * time.Second
+	// workerQueueSize — сколько прочитанных сообщений может ждать обработки в очереди одного воркера.
+	workerQueueSize = 64
+	// lagInterval — как часто метрика kafka_consumer_lag обновляется из LagReader.
+	lagInterval = 5 * time.Second
+)
+
+// BatchHandler обрабатывает пачку сообщений одного воркера.
+// Сообщения с одинаковым ключом приходят в порядке их offset.
+type BatchHandler func(ctx context.Context, msgs []Message) error
+
+// KafkaConsumer читает один топик в составе consumer group и обрабатывает сообщения
+// несколькими воркерами.
+//
+// Гарантии:
+//   - сообщения с одинаковым ключом обрабатываются одним воркером строго по порядку;
+//   - offset партиции коммитится только после обработки всех предыдущих сообщений этой партиции;
+//   - коммиты объединяются и отправляются раз в CommitInterval;
+//   - пачка, на которой обработчик вернул ошибку, повторяется с экспоненциальной паузой, пока
+//     не обработается; её offset (и offset следующих сообщений партиции) до тех пор не коммитятся;
+//   - при остановке (отмена контекста Subscribe или Shutdown) новые сообщения не читаются,
+//     уже прочитанные дообрабатываются, после чего выполняется финальный коммит.
 type KafkaConsumer struct {
-	// brokers — список адресов Kafka‑брокеров.
-	brokers []string
-	// reader — внутренний kafka-go Reader, создаётся при подписке.
-	reader *kafka.Reader
+	// broker — транспорт сообщений (по умолчанию Kafka).
+	broker Broker
+	// reader — внутренний Reader, создаётся при подписке.
+	reader Reader
+
+	concurrency       int
+	batchSize         int
+	batchWait         time.Duration
+	maxWait           time.Duration
+	sessionTimeout    time.Duration
+	heartbeatInterval time.Duration
+	commitInterval    time.Duration
+	retryBackoff      time.Duration
+	retryMaxBackoff   time.Duration
+
+	// metrics — метрики текущей подписки, создаются при подписке.
+	metrics *consumerMetrics
+
+	// cancel останавливает чтение новых сообщений.
+	cancel context.CancelFunc
+	// done закрывается, когда все воркеры завершились и сделан финальный коммит.
+	done chan struct{}
+
+	// lastPoll — время (unix nano) последнего выхода цикла чтения из FetchMessage;
+	// polling — цикл сейчас внутри FetchMessage. По ним считается PollAge.
+	lastPoll atomic.Int64
+	polling  atomic.Bool
 }
 
 // NewConsumer создаёт экземпляр KafkaConsumer c переданными брокерами.
-// Подписка на конкретный топик выполняется методом Subscribe.
-func NewConsumer(brokers []string) *KafkaConsumer {
-	return &KafkaConsumer{
-		brokers: brokers,
+// Подписка на конкретный топик выполняется методом Subscribe или SubscribeBatch.
+// Опция ConsumerBroker позволяет читать не из Kafka, а, например, из in-memory брокера.
+func NewConsumer(brokers []string, opts ...ConsumerOption) *KafkaConsumer {
+	c := &KafkaConsumer{
+		concurrency:     defaultConcurrency,
+		batchSize:       defaultBatchSize,
+		retryBackoff:    defaultRetryBackoff,
+		retryMaxBackoff: defaultRetryMaxBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	if c.broker == nil {
+		c.broker = NewKafkaBroker(brokers)
+	}
+
+	return c
 }
 
-// Subscribe настраивает kafka-go Reader и запускает бесконечный цикл чтения сообщений в отдельной горутине.
+// Subscribe создаёт Reader и запускает чтение сообщений в отдельных горутинах.
 //
 // Параметры:
-//   - ctx — общий контекст сервиса; по его отмене чтение сообщений останавливается;
+//   - ctx — общий контекст сервиса; по его отмене начинается graceful drain;
 //   - topic — Kafka‑топик для чтения;
 //   - groupID — consumer group id;
 //   - handler — пользовательский обработчик сообщения (key, value).
 //
 // Поведение:
 //   - при ошибке FetchMessage и живом контексте — лог, небольшая пауза и повтор;
-//   - при ошибке handler — сообщение НЕ коммитится (можно реализовать DLQ отдельно);
-//   - при успехе handler — сообщение коммитится.
+//   - после закрытия reader (io.EOF) цикл чтения завершается;
+//   - ошибка handler логируется, и пачка повторяется с паузой (см. ConsumerRetryBackoff), пока не
+//     обработается или не начнётся остановка. Offset необработанной пачки не коммитятся, поэтому
+//     после перезапуска она будет прочитана заново. Повторяется пачка целиком, так что обработчик
+//     должен быть идемпотентным; сообщения, которые нет смысла повторять (битый payload),
+//     обработчик пропускает сам, возвращая nil.
 func (c *KafkaConsumer) Subscribe(
 	ctx context.Context,
 	topic string,
 	groupID string,
 	handler func(context.Context, []byte, []byte) error,
 ) error {
-	c.reader = kafka.NewReader(kafka.ReaderConfig{
-		Brokers:  c.brokers,
-		GroupID:  groupID,
-		Topic:    topic,
-		MinBytes: 10e3,
-		MaxBytes: 10e6,
+	return c.SubscribeBatch(ctx, topic, groupID, func(ctx context.Context, msgs []Message) error {
+		var errs []error
+		for _, m := range msgs {
+			if err := handler(ctx, m.Key, m.Value); err != nil {
+				errs = append(errs, fmt.Errorf("topic=%s partition=%d offset=%d: %w", m.Topic, m.Partition, m.Offset, err))
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// SubscribeBatch работает как Subscribe, но передаёт обработчику пачки сообщений
+// (размер и время набора пачки задаются опцией ConsumerBatch).
+// Ошибка обработчика приводит к повтору пачки, как в Subscribe.
+func (c *KafkaConsumer) SubscribeBatch(
+	ctx context.Context,
+	topic string,
+	groupID string,
+	handler BatchHandler,
+) error {
+	c.reader = c.broker.NewReader(ReaderConfig{
+		Topic:             topic,
+		GroupID:           groupID,
+		MaxWait:           c.maxWait,
+		SessionTimeout:    c.sessionTimeout,
+		HeartbeatInterval: c.heartbeatInterval,
 	})
 
+	c.metrics = newConsumerMetrics(topic, groupID)
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	// Обработчики и коммиты не должны прерываться на середине при остановке:
+	// дообработка уже прочитанных сообщений ограничивается таймаутом Shutdown.
+	workCtx := context.WithoutCancel(ctx)
+
+	tracker := newOffsetTracker()
+	commitNow := make(chan struct{}, 1)
+
+	queues := make([]chan Message, c.concurrency)
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan Message, workerQueueSize)
+
+		workers.Add(1)
+		go func(queue <-chan Message) {
+			defer workers.Done()
+			c.work(workCtx, queue, handler, tracker, commitNow, fetchCtx.Done())
+		}(queues[i])
+	}
+
+	// Lag читается по таймеру, а не при чтении сообщений: иначе метрика замирает,
+	// когда консьюмер перестаёт читать, — ровно тогда, когда отставание растёт.
+	if lr, ok := c.reader.(LagReader); ok {
+		go c.lagLoop(fetchCtx, lr)
+	}
+
+	commitDone := make(chan struct{})
 	go func() {
-		defer c.reader.Close()
+		defer close(commitDone)
+		c.commitLoop(workCtx, tracker, commitNow, fetchCtx.Done())
+	}()
 
-		for {
-			m, err := c.reader.FetchMessage(ctx)
-			if err != nil {
-				if ctx.Err() != nil {
-					logrus.Info("KafkaConsumer: context cancelled, stopping...")
-					return
-				}
-				logrus.Errorf("KafkaConsumer fetch error: %v", err)
-				time.Sleep(time.Second)
-				continue
-			}
+	go func() {
+		defer close(c.done)
 
-			// Обработка сообщения пользовательским хендлером.
-			if err := handler(ctx, m.Key, m.Value); err != nil {
-				logrus.Errorf("KafkaConsumer handler error: %v", err)
-				// Ошибка обработки — не коммитим offset, сообщение может быть прочитано снова.
-				continue
-			}
+		c.fetchLoop(fetchCtx, queues, tracker)
 
-			// Успешная обработка — коммитим offset.
-			if err := c.reader.CommitMessages(ctx, m); err != nil {
-				logrus.Errorf("KafkaConsumer commit error: %v", err)
-			}
+		for _, q := range queues {
+			close(q)
 		}
+		workers.Wait()
+
+		// Воркеры завершились — финальный коммит всего, что успели обработать.
+		cancel()
+		<-commitDone
+		c.commit(workCtx, tracker)
+
+		if err := c.reader.Close(); err != nil {
+			logrus.Errorf("KafkaConsumer: close reader: %v", err)
+		}
+		logrus.Infof("KafkaConsumer: topic=%s group=%s drained", topic, groupID)
 	}()
 
 	return nil
 }
 
+// Shutdown прекращает чтение новых сообщений и ждёт, пока уже прочитанные будут обработаны
+// и закоммичены. Возвращает ctx.Err(), если дообработка не уложилась в ctx.
+func (c *KafkaConsumer) Shutdown(ctx context.Context) error {
+	if c.done == nil {
+		return nil
+	}
+
+	c.cancel()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Close вручную закрывает внутренний reader, если он был создан.
-// Обычно достаточно отмены контекста, но иногда полезно вызвать явное закрытие.
+// В отличие от Shutdown не дожидается обработки прочитанных сообщений.
 func (c *KafkaConsumer) Close() error {
 	if c.reader != nil {
 		return c.reader.Close()
 	}
 	return nil
 }
+
+// PollAge возвращает, сколько цикл чтения не обращался к брокеру. Пока цикл ждёт сообщений
+// внутри FetchMessage, возраст нулевой: пустой топик — не признак зависания. Возраст растёт,
+// если цикл стоит на переполненной очереди воркера (обработчик завис) или завершился.
+// До подписки возвращает 0.
+func (c *KafkaConsumer) PollAge() time.Duration {
+	last := c.lastPoll.Load()
+	if last == 0 || c.polling.Load() {
+		return 0
+	}
+	return time.Since(time.Unix(0, last))
+}
+
+// fetch вызывает FetchMessage, отмечая время обращения для PollAge.
+func (c *KafkaConsumer) fetch(ctx context.Context) (Message, error) {
+	c.polling.Store(true)
+	defer func() {
+		c.lastPoll.Store(time.Now().UnixNano())
+		c.polling.Store(false)
+	}()
+
+	return c.reader.FetchMessage(ctx)
+}
+
+// lagLoop раз в lagInterval публикует отставание ридера, пока не начнётся остановка.
+func (c *KafkaConsumer) lagLoop(ctx context.Context, lr LagReader) {
+	ticker := time.NewTicker(lagInterval)
+	defer ticker.Stop()
+
+	for {
+		c.metrics.lag.Set(float64(lr.Lag()))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchLoop читает сообщения и раскладывает их по очередям воркеров по хешу ключа.
+func (c *KafkaConsumer) fetchLoop(ctx context.Context, queues []chan Message, tracker *offsetTracker) {
+	c.lastPoll.Store(time.Now().UnixNano())
+
+	for {
+		m, err := c.fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				logrus.Info("KafkaConsumer: context cancelled, draining...")
+				return
+			}
+			if errors.Is(err, io.EOF) {
+				logrus.Info("KafkaConsumer: reader closed, stopping...")
+				return
+			}
+			logrus.Errorf("KafkaConsumer fetch error: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		tracker.fetched(m)
+
+		select {
+		case queues[workerIndex(m.Key, len(queues))] <- m:
+		case <-ctx.Done():
+			// Сообщение не попало в обработку и не будет закоммичено — его доставят повторно.
+			logrus.Info("KafkaConsumer: context cancelled, draining...")
+			return
+		}
+	}
+}
+
+// work набирает пачки из очереди воркера и передаёт их обработчику. Обработанными
+// (и доступными для коммита) отмечаются только пачки, которые обработчик принял без ошибки.
+// Если пачка брошена при остановке, воркер больше ничего не обрабатывает: следующие сообщения
+// тех же ключей нельзя обработать раньше неё, их прочитают заново вместе с ней после перезапуска.
+func (c *KafkaConsumer) work(
+	ctx context.Context,
+	queue <-chan Message,
+	handler BatchHandler,
+	tracker *offsetTracker,
+	commitNow chan<- struct{},
+	stop <-chan struct{},
+) {
+	for m := range queue {
+		batch := c.collect(queue, m)
+
+		if !c.handle(ctx, batch, handler, stop) {
+			return
+		}
+
+		tracker.done(batch...)
+
+		select {
+		case commitNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// handle вызывает обработчик, пока пачка не обработается: между попытками — пауза от retryBackoff,
+// удваивающаяся до retryMaxBackoff. Когда начинается остановка, повторы прекращаются и handle
+// возвращает false — пачка остаётся незакоммиченной и будет прочитана заново после перезапуска.
+func (c *KafkaConsumer) handle(ctx context.Context, batch []Message, handler BatchHandler, stop <-chan struct{}) bool {
+	backoff := c.retryBackoff
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := handler(ctx, batch)
+		c.metrics.handled(start, err)
+		if err == nil {
+			return true
+		}
+
+		first := batch[0]
+		logrus.Errorf("KafkaConsumer batch handler error: topic=%s partition=%d offset=%d size=%d attempt=%d: %v",
+			first.Topic, first.Partition, first.Offset, len(batch), attempt, err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-stop:
+			timer.Stop()
+			logrus.Warnf("KafkaConsumer: stopping, batch at topic=%s partition=%d offset=%d is left uncommitted",
+				first.Topic, first.Partition, first.Offset)
+			return false
+		case <-timer.C:
+		}
+
+		c.metrics.batchRetries.Inc()
+		backoff = min(backoff*2, c.retryMaxBackoff)
+	}
+}
+
+// collect дополняет пачку сообщениями из очереди, пока не наберётся batchSize
+// или не истечёт batchWait.
+func (c *KafkaConsumer) collect(queue <-chan Message, first Message) []Message {
+	batch := []Message{first}
+	if c.batchSize <= 1 {
+		return batch
+	}
+
+	timer := time.NewTimer(c.batchWait)
+	defer timer.Stop()
+
+	for len(batch) < c.batchSize {
+		select {
+		case m, ok := <-queue:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, m)
+		case <-timer.C:
+			return batch
+		}
+	}
+
+	return batch
+}
+
+// commitLoop коммитит обработанные offset: раз в commitInterval или,
+// если интервал не задан, сразу после каждой обработанной пачки.
+func (c *KafkaConsumer) commitLoop(ctx context.Context, tracker *offsetTracker, commitNow <-chan struct{}, stop <-chan struct{}) {
+	if c.commitInterval <= 0 {
+		for {
+			select {
+			case <-commitNow:
+				c.commit(ctx, tracker)
+			case <-stop:
+				return
+			}
+		}
+	}
+
+	ticker := time.NewTicker(c.commitInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.commit(ctx, tracker)
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c *KafkaConsumer) commit(ctx context.Context, tracker *offsetTracker) {
+	msgs := tracker.committable()
+	if len(msgs) == 0 {
+		return
+	}
+
+	if err := c.reader.CommitMessages(ctx, msgs...); err != nil {
+		logrus.Errorf("KafkaConsumer commit error: %v", err)
+		c.metrics.commitFailed.Inc()
+		return
+	}
+
+	tracker.committed(msgs)
+}
+
+func workerIndex(key []byte, n int) int {
+	if n <= 1 {
+		return 0
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+
+	return int(h.Sum32() % uint32(n))
+}
+
+// offsetTracker следит за тем, какие прочитанные сообщения уже обработаны,
+// и отдаёт для коммита максимальный offset, до которого обработаны все сообщения партиции.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[partitionKey]*partitionOffsets
+}
+
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+type partitionOffsets struct {
+	// inflight — прочитанные, но ещё не обработанные по порядку offset.
+	inflight []int64
+	// processed — обработанные offset из inflight.
+	processed map[int64]bool
+	// ready — последний offset непрерывного префикса обработанных сообщений.
+	ready int64
+	// committed — последний успешно закоммиченный offset.
+	committed int64
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[partitionKey]*partitionOffsets)}
+}
+
+func (t *offsetTracker) fetched(m Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := partitionKey{topic: m.Topic, partition: m.Partition}
+	p, ok := t.partitions[key]
+	if !ok {
+		p = &partitionOffsets{processed: make(map[int64]bool), ready: -1, committed: -1}
+		t.partitions[key] = p
+	}
+	p.inflight = append(p.inflight, m.Offset)
+}
+
+func (t *offsetTracker) done(msgs ...Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, m := range msgs {
+		if p, ok := t.partitions[partitionKey{topic: m.Topic, partition: m.Partition}]; ok {
+			p.processed[m.Offset] = true
+		}
+	}
+}
+
+// committable возвращает по одному сообщению на партицию — последнее из непрерывного
+// префикса обработанных сообщений, если оно ещё не закоммичено.
+// Reader коммитит offset этого сообщения + 1.
+func (t *offsetTracker) committable() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []Message
+	for key, p := range t.partitions {
+		n := 0
+		for _, off := range p.inflight {
+			if !p.processed[off] {
+				break
+			}
+			delete(p.processed, off)
+			p.ready = off
+			n++
+		}
+		p.inflight = p.inflight[n:]
+
+		if p.ready > p.committed {
+			out = append(out, Message{Topic: key.topic, Partition: key.partition, Offset: p.ready})
+		}
+	}
+
+	return out
+}
+
+// committed отмечает offset как закоммиченные. Если коммит не удался, метод не вызывается,
+// и те же offset будут отданы committable при следующей попытке.
+func (t *offsetTracker) committed(msgs []Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, m := range msgs {
+		p := t.partitions[partitionKey{topic: m.Topic, partition: m.Partition}]
+		if m.Offset > p.committed {
+			p.committed = m.Offset
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka/localbroker"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaConsumer_ConcurrentKeepsKeyOrder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	b := localbroker.New(localbroker.Partitions(4))
+	defer b.Close()
+
+	const keys, perKey = 10, 20
+	for i := 0; i < perKey; i++ {
+		for k := 0; k < keys; k++ {
+			require.NoError(t, b.WriteMessages(ctx, kafka.Message{
+				Topic: "orders",
+				Key:   []byte(fmt.Sprintf("key-%d", k)),
+				Value: []byte(strconv.Itoa(i)),
+			}))
+		}
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string][]int)
+		all  = make(chan struct{})
+		n    int
+	)
+
+	c := kafka.NewConsumer(nil,
+		kafka.ConsumerBroker(b),
+		kafka.ConsumerConcurrency(4),
+		kafka.ConsumerCommitInterval(10*time.Millisecond),
+	)
+	require.NoError(t, c.Subscribe(ctx, "orders", "g", func(_ context.Context, key, value []byte) error {
+		v, _ := strconv.Atoi(string(value))
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		seen[string(key)] = append(seen[string(key)], v)
+		if n++; n == keys*perKey {
+			close(all)
+		}
+		return nil
+	}))
+
+	select {
+	case <-all:
+	case <-ctx.Done():
+		t.Fatal("not all messages were processed")
+	}
+
+	require.NoError(t, c.Shutdown(ctx))
+
+	for key, values := range seen {
+		for i, v := range values {
+			require.Equal(t, i, v, "messages of %s processed out of order", key)
+		}
+	}
+
+	// Всё обработанное закоммичено: новый участник группы ничего не получает.
+	r := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "g"})
+	defer r.Close()
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer waitCancel()
+	_, err := r.FetchMessage(waitCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestKafkaConsumer_ShutdownDrainsInFlight(t *testing.T) {
+	b := localbroker.New()
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, b.WriteMessages(ctx,
+		kafka.Message{Topic: "orders", Key: []byte("k"), Value: []byte("1")},
+		kafka.Message{Topic: "orders", Key: []byte("k"), Value: []byte("2")},
+	))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var processed atomic.Int32
+
+	c := kafka.NewConsumer(nil, kafka.ConsumerBroker(b), kafka.ConsumerBatch(10, 50*time.Millisecond))
+	require.NoError(t, c.SubscribeBatch(ctx, "orders", "g", func(ctx context.Context, msgs []kafka.Message) error {
+		close(started)
+		<-release
+		require.NoError(t, ctx.Err(), "handler context must survive shutdown")
+		processed.Add(int32(len(msgs)))
+		return nil
+	}))
+
+	<-started
+	cancel()
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- c.Shutdown(context.Background())
+	}()
+
+	select {
+	case <-shutdownErr:
+		t.Fatal("Shutdown returned before in-flight batch finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-shutdownErr)
+	require.Equal(t, int32(2), processed.Load())
+
+	r := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "g"})
+	defer r.Close()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer waitCancel()
+	_, err := r.FetchMessage(waitCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestKafkaConsumer_PollAge(t *testing.T) {
+	b := localbroker.New()
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	release := make(chan struct{})
+	c := kafka.NewConsumer(nil, kafka.ConsumerBroker(b))
+	require.NoError(t, c.SubscribeBatch(ctx, "orders", "g", func(ctx context.Context, msgs []kafka.Message) error {
+		<-release
+		return nil
+	}))
+
+	// Пустой топик: цикл ждёт внутри FetchMessage и не считается зависшим.
+	time.Sleep(20 * time.Millisecond)
+	require.Zero(t, c.PollAge())
+
+	// Обработчик завис: очередь воркера переполняется, и цикл чтения перестаёт обращаться к брокеру.
+	for i := 0; i < 100; i++ {
+		require.NoError(t, b.WriteMessages(ctx, kafka.Message{Topic: "orders", Key: []byte("k"), Value: []byte("v")}))
+	}
+	require.Eventually(t, func() bool { return c.PollAge() > 20*time.Millisecond }, time.Second, 5*time.Millisecond)
+
+	close(release)
+	require.NoError(t, c.Shutdown(context.Background()))
+}
+
+func TestKafkaConsumer_FailingHandlerDoesNotCommit(t *testing.T) {
+	b := localbroker.New()
+	defer b.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, b.WriteMessages(ctx,
+		kafka.Message{Topic: "orders", Key: []byte("k"), Value: []byte("1")},
+		kafka.Message{Topic: "orders", Key: []byte("k"), Value: []byte("2")},
+	))
+
+	var (
+		attempts    atomic.Int32
+		handledNext atomic.Bool
+	)
+	c := kafka.NewConsumer(nil,
+		kafka.ConsumerBroker(b),
+		kafka.ConsumerRetryBackoff(time.Millisecond, 2*time.Millisecond),
+	)
+	require.NoError(t, c.Subscribe(ctx, "orders", "g", func(_ context.Context, _, value []byte) error {
+		if string(value) == "1" {
+			attempts.Add(1)
+			return errors.New("postgres is down")
+		}
+		handledNext.Store(true)
+		return nil
+	}))
+
+	// Пачка повторяется, а не пропускается.
+	require.Eventually(t, func() bool { return attempts.Load() >= 3 }, time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, c.Shutdown(context.Background()))
+
+	// Брошенная при остановке пачка не пропускается: следующие сообщения того же ключа
+	// не обрабатываются раньше неё.
+	require.False(t, handledNext.Load())
+
+	// Offset не продвинулся: новый участник группы получает то же сообщение.
+	r := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "g"})
+	defer r.Close()
+
+	fetchCtx, fetchCancel := context.WithTimeout(context.Background(), time.Second)
+	defer fetchCancel()
+	m, err := r.FetchMessage(fetchCtx)
+	require.NoError(t, err)
+	require.Equal(t, "1", string(m.Value))
+}
+
+func TestKafkaConsumer_RetriesUntilHandlerSucceeds(t *testing.T) {
+	b := localbroker.New()
+	defer b.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, b.WriteMessages(ctx, kafka.Message{Topic: "orders", Key: []byte("k"), Value: []byte("1")}))
+
+	var attempts atomic.Int32
+	handled := make(chan struct{})
+	c := kafka.NewConsumer(nil,
+		kafka.ConsumerBroker(b),
+		kafka.ConsumerRetryBackoff(time.Millisecond, 2*time.Millisecond),
+	)
+	require.NoError(t, c.Subscribe(ctx, "orders", "g", func(context.Context, []byte, []byte) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("postgres is down")
+		}
+		close(handled)
+		return nil
+	}))
+
+	select {
+	case <-handled:
+	case <-ctx.Done():
+		t.Fatal("batch was not retried")
+	}
+	require.NoError(t, c.Shutdown(ctx))
+	require.Equal(t, int32(3), attempts.Load())
+
+	r := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "g"})
+	defer r.Close()
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer waitCancel()
+	_, err := r.FetchMessage(waitCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
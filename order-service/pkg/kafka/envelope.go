@@ -2,8 +2,10 @@ package kafka
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
 	"github.com/google/uuid"
 )
 
@@ -11,14 +13,124 @@ import (
 // Это позволяет:
 //   - иметь общий EventID для трейсинга;
 //   - хранить время возникновения события отдельно от времени публикации;
-//   - иметь тип события (EventType) и произвольный payload (Data).
+//   - иметь тип события (EventType), версию схемы payload (SchemaVersion) и сам payload (Data).
 type Envelope struct {
 	// EventID — идентификатор события (генерируется при публикации).
 	EventID uuid.UUID `json:"eventId"`
-	// EventType — строковый тип события (например, "OrderCreated").
+	// EventType — строковый тип события (например, "order.created").
 	EventType string `json:"eventType"`
+	// SchemaVersion — версия JSON Schema, которой соответствует Data (см. pkg/schema).
+	SchemaVersion int `json:"schemaVersion"`
 	// OccurredAt — момент времени, когда событие произошло в доменной модели.
-	OccurredAt time.Time `json:"occuredAt"`
+	OccurredAt time.Time `json:"occurredAt"`
 	// Data — сырое тело события в виде JSON (конкретный payload доменного события).
 	Data json.RawMessage `json:"data"`
 }
+
+// legacySchemaVersion — версия, которая считается у сообщений, опубликованных
+// до появления поля schemaVersion.
+const legacySchemaVersion = 1
+
+// UnmarshalJSON дополнительно понимает устаревшее поле "occuredAt",
+// с которым публиковались сообщения до исправления опечатки.
+func (e *Envelope) UnmarshalJSON(data []byte) error {
+	type envelope Envelope
+
+	aux := struct {
+		*envelope
+		LegacyOccurredAt *time.Time `json:"occuredAt"`
+	}{envelope: (*envelope)(e)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if e.OccurredAt.IsZero() && aux.LegacyOccurredAt != nil {
+		e.OccurredAt = *aux.LegacyOccurredAt
+	}
+
+	return nil
+}
+
+// EncodeEnvelope сериализует payload, проверяет его по последней версии схемы eventType
+// и возвращает готовое к публикации сообщение в формате Envelope.
+// Если schemas == nil, валидация пропускается, а schemaVersion не заполняется.
+func EncodeEnvelope(
+	schemas *schema.Registry,
+	eventID uuid.UUID,
+	eventType string,
+	occurredAt time.Time,
+	payload any,
+) ([]byte, error) {
+	var raw json.RawMessage
+	switch v := payload.(type) {
+	case json.RawMessage:
+		raw = v
+	case []byte:
+		raw = json.RawMessage(v)
+	default:
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		raw = json.RawMessage(data)
+	}
+
+	var version int
+	if schemas != nil {
+		latest, err := schemas.Latest(eventType)
+		if err != nil {
+			return nil, err
+		}
+		if err := schemas.Validate(eventType, latest, raw); err != nil {
+			return nil, err
+		}
+		version = latest
+	}
+
+	envelope := Envelope{
+		EventID:       eventID,
+		EventType:     eventType,
+		SchemaVersion: version,
+		OccurredAt:    occurredAt,
+		Data:          raw,
+	}
+
+	return json.Marshal(&envelope)
+}
+
+// DecodeEnvelope разбирает сообщение Kafka и приводит payload к актуальной версии схемы.
+//
+// Поведение:
+//   - сообщения без schemaVersion считаются версией 1;
+//   - payload проверяется по схеме своей версии, затем апкастится до последней версии;
+//   - если schemas == nil, валидация и апкаст пропускаются.
+func DecodeEnvelope(data []byte, schemas *schema.Registry) (Envelope, error) {
+	var env Envelope
+
+	if err := json.Unmarshal(data, &env); err != nil {
+		return Envelope{}, fmt.Errorf("invalid envelope: %w", err)
+	}
+
+	if env.Data == nil {
+		return Envelope{}, fmt.Errorf("invalid payload for %s: empty data", env.EventType)
+	}
+
+	if env.SchemaVersion == 0 {
+		env.SchemaVersion = legacySchemaVersion
+	}
+
+	if schemas == nil {
+		return env, nil
+	}
+
+	upcasted, version, err := schemas.Upcast(env.EventType, env.SchemaVersion, env.Data)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("invalid payload for %s: %w", env.EventType, err)
+	}
+
+	env.Data = upcasted
+	env.SchemaVersion = version
+
+	return env, nil
+}
@@ -0,0 +1,368 @@
+// Package localbroker содержит реализации kafka.Broker, работающие в одном процессе:
+// in-memory брокер (New) и брокер с файловым append-log (Open).
+//
+// Оба брокера поддерживают партиции, consumer group с распределением партиций
+// между участниками, закоммиченные offset и повторную доставку незакоммиченных
+// сообщений после перебалансировки — этого достаточно, чтобы прогнать сагу
+// order → payment → analytics в go test без Kafka.
+package localbroker
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+)
+
+const defaultPartitions = 1
+
+var ErrNoTopic = errors.New("localbroker: message topic is empty")
+
+// Option -.
+type Option func(*Broker)
+
+// Partitions задаёт число партиций для новых топиков.
+// Сообщения с одинаковым ключом всегда попадают в одну партицию.
+func Partitions(n int) Option {
+	return func(b *Broker) {
+		if n > 0 {
+			b.partitions = n
+		}
+	}
+}
+
+// Broker — брокер сообщений в памяти процесса.
+// Реализует kafka.Broker и безопасен для конкурентного использования.
+type Broker struct {
+	mu         sync.Mutex
+	partitions int
+	// topics — лог каждой партиции топика; offset сообщения равен его индексу.
+	topics map[string][][]kafka.Message
+	groups map[groupKey]*group
+	// changed закрывается и пересоздаётся при любом изменении, которого могут ждать ридеры.
+	changed chan struct{}
+	// rr — счётчик для распределения сообщений без ключа.
+	rr     int
+	closed bool
+	// store — файловое хранилище; nil для in-memory брокера.
+	store *fileStore
+}
+
+type groupKey struct {
+	topic   string
+	groupID string
+}
+
+// group — состояние consumer group на одном топике.
+type group struct {
+	committed  map[int]int64
+	members    []*reader
+	generation int
+}
+
+// New создаёт пустой in-memory брокер.
+func New(opts ...Option) *Broker {
+	b := &Broker{
+		partitions: defaultPartitions,
+		topics:     make(map[string][][]kafka.Message),
+		groups:     make(map[groupKey]*group),
+		changed:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// WriteMessages добавляет сообщения в конец логов партиций.
+// Партиция выбирается по хешу ключа, сообщения без ключа распределяются по кругу.
+func (b *Broker) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return io.ErrClosedPipe
+	}
+
+	now := time.Now().UTC()
+	for _, m := range msgs {
+		if m.Topic == "" {
+			return ErrNoTopic
+		}
+
+		logs := b.topic(m.Topic)
+		m.Partition = b.partition(m.Key, len(logs))
+		m.Offset = int64(len(logs[m.Partition]))
+		if m.Time.IsZero() {
+			m.Time = now
+		}
+
+		if b.store != nil {
+			if err := b.store.append(m); err != nil {
+				return err
+			}
+		}
+
+		logs[m.Partition] = append(logs[m.Partition], m)
+	}
+
+	b.notify()
+
+	return nil
+}
+
+// NewReader подключает нового участника к consumer group cfg.GroupID на топике cfg.Topic.
+// Партиции топика перераспределяются между всеми участниками группы.
+// Таймауты из cfg (MaxWait, SessionTimeout, HeartbeatInterval) не используются:
+// участник состоит в группе, пока не вызван Close.
+func (b *Broker) NewReader(cfg kafka.ReaderConfig) kafka.Reader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r := &reader{
+		broker:     b,
+		key:        groupKey{topic: cfg.Topic, groupID: cfg.GroupID},
+		generation: -1,
+	}
+
+	b.topic(cfg.Topic)
+	g := b.group(r.key)
+	g.members = append(g.members, r)
+	g.generation++
+	b.notify()
+
+	return r
+}
+
+// Close закрывает брокер: все ридеры получают io.EOF, запись больше невозможна.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	b.notify()
+
+	if b.store != nil {
+		return b.store.close()
+	}
+
+	return nil
+}
+
+// Messages возвращает копию всех сообщений топика в порядке партиций и offset.
+// Удобно для проверок в тестах.
+func (b *Broker) Messages(topic string) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out []kafka.Message
+	for _, log := range b.topics[topic] {
+		out = append(out, log...)
+	}
+
+	return out
+}
+
+// topic возвращает логи партиций топика, создавая топик при первом обращении.
+func (b *Broker) topic(name string) [][]kafka.Message {
+	logs, ok := b.topics[name]
+	if !ok {
+		logs = make([][]kafka.Message, b.partitions)
+		b.topics[name] = logs
+	}
+	return logs
+}
+
+func (b *Broker) group(key groupKey) *group {
+	g, ok := b.groups[key]
+	if !ok {
+		g = &group{committed: make(map[int]int64)}
+		b.groups[key] = g
+	}
+	return g
+}
+
+func (b *Broker) partition(key []byte, n int) int {
+	if len(key) == 0 {
+		b.rr++
+		return b.rr % n
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+
+	return int(h.Sum32() % uint32(n))
+}
+
+// notify будит всех ридеров, ожидающих изменений. Вызывается под b.mu.
+func (b *Broker) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// commit сохраняет offset следующего сообщения для каждой партиции. Вызывается под b.mu.
+func (b *Broker) commit(key groupKey, msgs []kafka.Message) error {
+	g := b.group(key)
+
+	for _, m := range msgs {
+		if next := m.Offset + 1; next > g.committed[m.Partition] {
+			g.committed[m.Partition] = next
+		}
+	}
+
+	if b.store != nil {
+		return b.store.saveOffsets(b.groups)
+	}
+
+	return nil
+}
+
+func (b *Broker) leave(r *reader) {
+	g := b.group(r.key)
+
+	for i, m := range g.members {
+		if m == r {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+			break
+		}
+	}
+	g.generation++
+	b.notify()
+}
+
+// reader — участник consumer group.
+type reader struct {
+	broker *Broker
+	key    groupKey
+	// generation — поколение группы, для которого посчитаны positions.
+	generation int
+	// positions — следующий offset для каждой назначенной ридеру партиции.
+	positions map[int]int64
+	// assigned — назначенные партиции в порядке обхода.
+	assigned []int
+	cursor   int
+	closed   bool
+}
+
+func (r *reader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	b := r.broker
+
+	for {
+		b.mu.Lock()
+
+		if r.closed || b.closed {
+			b.mu.Unlock()
+			return kafka.Message{}, io.EOF
+		}
+
+		g := b.group(r.key)
+		if r.generation != g.generation {
+			r.rebalance(g)
+		}
+
+		if m, ok := r.next(); ok {
+			b.mu.Unlock()
+			return m, nil
+		}
+
+		wait := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+func (r *reader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.broker.mu.Lock()
+	defer r.broker.mu.Unlock()
+
+	if r.closed || r.broker.closed {
+		return io.EOF
+	}
+
+	return r.broker.commit(r.key, msgs)
+}
+
+func (r *reader) Close() error {
+	r.broker.mu.Lock()
+	defer r.broker.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.broker.leave(r)
+
+	return nil
+}
+
+// Lag возвращает число непрочитанных сообщений в назначенных ридеру партициях.
+func (r *reader) Lag() int64 {
+	r.broker.mu.Lock()
+	defer r.broker.mu.Unlock()
+
+	logs := r.broker.topics[r.key.topic]
+
+	var lag int64
+	for _, p := range r.assigned {
+		lag += int64(len(logs[p])) - r.positions[p]
+	}
+
+	return lag
+}
+
+// rebalance пересчитывает назначенные партиции и начинает их чтение
+// с закоммиченных offset. Вызывается под b.mu.
+func (r *reader) rebalance(g *group) {
+	r.generation = g.generation
+	r.positions = make(map[int]int64)
+	r.assigned = r.assigned[:0]
+	r.cursor = 0
+
+	for p := range r.broker.topics[r.key.topic] {
+		if g.members[p%len(g.members)] == r {
+			r.assigned = append(r.assigned, p)
+			r.positions[p] = g.committed[p]
+		}
+	}
+}
+
+// next возвращает следующее доступное сообщение, обходя партиции по кругу. Вызывается под b.mu.
+func (r *reader) next() (kafka.Message, bool) {
+	logs := r.broker.topics[r.key.topic]
+
+	for i := 0; i < len(r.assigned); i++ {
+		p := r.assigned[(r.cursor+i)%len(r.assigned)]
+		pos := r.positions[p]
+
+		if pos < int64(len(logs[p])) {
+			r.positions[p] = pos + 1
+			r.cursor = (r.cursor + i + 1) % len(r.assigned)
+
+			return logs[p][pos], true
+		}
+	}
+
+	return kafka.Message{}, false
+}
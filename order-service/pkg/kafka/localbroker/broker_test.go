@@ -0,0 +1,202 @@
+package localbroker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka/localbroker"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func write(t *testing.T, b kafka.Broker, topic string, values ...string) {
+	t.Helper()
+
+	for _, v := range values {
+		require.NoError(t, b.WriteMessages(context.Background(), kafka.Message{Topic: topic, Key: []byte("k"), Value: []byte(v)}))
+	}
+}
+
+func fetch(t *testing.T, r kafka.Reader) kafka.Message {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	m, err := r.FetchMessage(ctx)
+	require.NoError(t, err)
+
+	return m
+}
+
+func TestBroker_GroupsAndRedelivery(t *testing.T) {
+	ctx := context.Background()
+	b := localbroker.New()
+	defer b.Close()
+
+	write(t, b, "orders", "a", "b", "c")
+
+	// Каждая группа читает топик независимо.
+	other := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "analytics"})
+	require.Equal(t, "a", string(fetch(t, other).Value))
+	require.NoError(t, other.Close())
+
+	r := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "payment"})
+	m := fetch(t, r)
+	require.Equal(t, "a", string(m.Value))
+	require.NoError(t, r.CommitMessages(ctx, m))
+	require.Equal(t, "b", string(fetch(t, r).Value))
+	require.NoError(t, r.Close())
+
+	// "b" не закоммичено — новый участник группы получит его повторно.
+	r = b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "payment"})
+	defer r.Close()
+	require.Equal(t, "b", string(fetch(t, r).Value))
+	require.Equal(t, "c", string(fetch(t, r).Value))
+
+	// Нет новых сообщений — FetchMessage ждёт до отмены контекста.
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err := r.FetchMessage(waitCtx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBroker_ReaderLag(t *testing.T) {
+	b := localbroker.New()
+	defer b.Close()
+
+	write(t, b, "orders", "a", "b", "c")
+
+	r := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "payment"})
+	defer r.Close()
+	fetch(t, r)
+
+	lr, ok := r.(kafka.LagReader)
+	require.True(t, ok)
+	require.Equal(t, int64(2), lr.Lag())
+
+	// Lag растёт с новыми сообщениями, даже если ридер больше ничего не читает.
+	write(t, b, "orders", "d")
+	require.Equal(t, int64(3), lr.Lag())
+}
+
+func TestBroker_PartitionsAreSplitBetweenMembers(t *testing.T) {
+	b := localbroker.New(localbroker.Partitions(4))
+	defer b.Close()
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, b.WriteMessages(context.Background(), kafka.Message{Topic: "orders", Key: []byte(uuid.NewString())}))
+	}
+
+	r1 := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "g"})
+	r2 := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "g"})
+	defer r1.Close()
+	defer r2.Close()
+
+	p1, n1 := drain(r1)
+	p2, n2 := drain(r2)
+
+	require.Equal(t, 20, n1+n2)
+	for p := range p1 {
+		require.NotContains(t, p2, p, "partition %d is assigned to both members", p)
+	}
+}
+
+// drain читает всё доступное ридеру и возвращает прочитанные партиции и число сообщений.
+func drain(r kafka.Reader) (map[int]bool, int) {
+	partitions := make(map[int]bool)
+	n := 0
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		m, err := r.FetchMessage(ctx)
+		cancel()
+		if err != nil {
+			return partitions, n
+		}
+		partitions[m.Partition] = true
+		n++
+	}
+}
+
+func TestFileBroker_Reopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	b, err := localbroker.Open(dir, localbroker.Partitions(2))
+	require.NoError(t, err)
+
+	write(t, b, "orders", "a", "b")
+
+	r := b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "g"})
+	require.NoError(t, r.CommitMessages(ctx, fetch(t, r)))
+	require.NoError(t, b.Close())
+
+	b, err = localbroker.Open(dir)
+	require.NoError(t, err)
+	defer b.Close()
+
+	require.Len(t, b.Messages("orders"), 2)
+
+	r = b.NewReader(kafka.ReaderConfig{Topic: "orders", GroupID: "g"})
+	defer r.Close()
+	require.Equal(t, "b", string(fetch(t, r).Value))
+}
+
+// TestSaga прогоняет order.created → payment.success → order.paid через in-memory брокер
+// теми же KafkaPublisher, KafkaConsumer и Dispatcher, что используются в сервисах.
+func TestSaga(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	b := localbroker.New(localbroker.Partitions(3))
+	defer b.Close()
+
+	pub := kafka.NewKafkaPublisher(nil, kafka.PublisherBroker(b))
+
+	// payment-service: оплачивает созданный заказ.
+	payment := kafka.NewDispatcher(schema.Default())
+	kafka.Handle(payment, func(ctx context.Context, _ kafka.Envelope, ev events.OrderCreated) error {
+		return kafka.Publish(ctx, pub, "payment.events", events.PaymentSucceeded{
+			PaymentID: uuid.New(),
+			OrderID:   ev.OrderID,
+			Amount:    ev.TotalPrice,
+		})
+	})
+
+	// order-service: помечает заказ оплаченным.
+	order := kafka.NewDispatcher(schema.Default())
+	kafka.Handle(order, func(ctx context.Context, _ kafka.Envelope, ev events.PaymentSucceeded) error {
+		return kafka.Publish(ctx, pub, "order.events", events.OrderPaid{OrderID: ev.OrderID, PaymentID: ev.PaymentID})
+	})
+
+	// analytics-service: фиксирует оплату.
+	paid := make(chan events.OrderPaid, 1)
+	analytics := kafka.NewDispatcher(schema.Default())
+	kafka.Handle(analytics, func(_ context.Context, _ kafka.Envelope, ev events.OrderPaid) error {
+		paid <- ev
+		return nil
+	})
+
+	require.NoError(t, kafka.NewConsumer(nil, kafka.ConsumerBroker(b)).Subscribe(ctx, "order.events", "payment", payment.Dispatch))
+	require.NoError(t, kafka.NewConsumer(nil, kafka.ConsumerBroker(b)).Subscribe(ctx, "payment.events", "order", order.Dispatch))
+	require.NoError(t, kafka.NewConsumer(nil, kafka.ConsumerBroker(b)).Subscribe(ctx, "order.events", "analytics", analytics.Dispatch))
+
+	orderID := uuid.New()
+	require.NoError(t, kafka.Publish(ctx, pub, "order.events", events.OrderCreated{
+		OrderID:    orderID,
+		UserID:     uuid.New(),
+		TotalPrice: 990,
+	}))
+
+	select {
+	case ev := <-paid:
+		require.Equal(t, orderID, ev.OrderID)
+	case <-ctx.Done():
+		t.Fatal("saga did not complete")
+	}
+}
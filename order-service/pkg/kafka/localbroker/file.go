@@ -0,0 +1,215 @@
+package localbroker
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+)
+
+const (
+	logSuffix   = ".log"
+	offsetsFile = "offsets.json"
+)
+
+// record — строка файла <topic>.log.
+type record struct {
+	Partition int       `json:"partition"`
+	Offset    int64     `json:"offset"`
+	Key       []byte    `json:"key,omitempty"`
+	Value     []byte    `json:"value"`
+	Time      time.Time `json:"time"`
+}
+
+// committedOffset — строка файла offsets.json.
+type committedOffset struct {
+	Topic     string `json:"topic"`
+	GroupID   string `json:"groupId"`
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+// fileStore хранит сообщения в append-only файлах (по одному NDJSON-файлу на топик)
+// и закоммиченные offset групп в offsets.json.
+type fileStore struct {
+	dir   string
+	files map[string]*os.File
+}
+
+// Open создаёт брокер, сохраняющий сообщения и offset в каталоге dir.
+// Если каталог уже содержит данные, они загружаются: ридеры продолжат чтение
+// с закоммиченных offset, как после перезапуска Kafka-консьюмеров.
+func Open(dir string, opts ...Option) (*Broker, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("localbroker - Open - mkdir: %w", err)
+	}
+
+	b := New(opts...)
+	b.store = &fileStore{dir: dir, files: make(map[string]*os.File)}
+
+	if err := b.load(); err != nil {
+		_ = b.store.close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// load восстанавливает логи топиков и offset групп из каталога хранилища.
+func (b *Broker) load() error {
+	paths, err := filepath.Glob(filepath.Join(b.store.dir, "*"+logSuffix))
+	if err != nil {
+		return fmt.Errorf("localbroker - load - glob: %w", err)
+	}
+
+	for _, path := range paths {
+		topic := strings.TrimSuffix(filepath.Base(path), logSuffix)
+		if err := b.loadTopic(topic, path); err != nil {
+			return err
+		}
+	}
+
+	raw, err := os.ReadFile(filepath.Join(b.store.dir, offsetsFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("localbroker - load - read offsets: %w", err)
+	}
+
+	var offsets []committedOffset
+	if err := json.Unmarshal(raw, &offsets); err != nil {
+		return fmt.Errorf("localbroker - load - parse offsets: %w", err)
+	}
+
+	for _, o := range offsets {
+		b.group(groupKey{topic: o.Topic, groupID: o.GroupID}).committed[o.Partition] = o.Offset
+	}
+
+	return nil
+}
+
+func (b *Broker) loadTopic(topic, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("localbroker - loadTopic - %s: %w", topic, err)
+	}
+	defer f.Close()
+
+	logs := b.topic(topic)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return fmt.Errorf("localbroker - loadTopic - %s: corrupted record: %w", topic, err)
+		}
+
+		// Топик мог быть записан с большим числом партиций, чем задано сейчас.
+		for rec.Partition >= len(logs) {
+			logs = append(logs, nil)
+		}
+
+		if rec.Offset != int64(len(logs[rec.Partition])) {
+			return fmt.Errorf("localbroker - loadTopic - %s: partition %d: expected offset %d, got %d",
+				topic, rec.Partition, len(logs[rec.Partition]), rec.Offset)
+		}
+
+		logs[rec.Partition] = append(logs[rec.Partition], kafka.Message{
+			Topic:     topic,
+			Partition: rec.Partition,
+			Offset:    rec.Offset,
+			Key:       rec.Key,
+			Value:     rec.Value,
+			Time:      rec.Time,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("localbroker - loadTopic - %s: %w", topic, err)
+	}
+
+	b.topics[topic] = logs
+
+	return nil
+}
+
+// append дописывает сообщение в файл его топика.
+func (s *fileStore) append(m kafka.Message) error {
+	f, ok := s.files[m.Topic]
+	if !ok {
+		var err error
+		f, err = os.OpenFile(filepath.Join(s.dir, m.Topic+logSuffix), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("localbroker - append - open %s: %w", m.Topic, err)
+		}
+		s.files[m.Topic] = f
+	}
+
+	line, err := json.Marshal(record{
+		Partition: m.Partition,
+		Offset:    m.Offset,
+		Key:       m.Key,
+		Value:     m.Value,
+		Time:      m.Time,
+	})
+	if err != nil {
+		return fmt.Errorf("localbroker - append - marshal: %w", err)
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("localbroker - append - write %s: %w", m.Topic, err)
+	}
+
+	return nil
+}
+
+// saveOffsets атомарно перезаписывает offsets.json (через временный файл и rename).
+func (s *fileStore) saveOffsets(groups map[groupKey]*group) error {
+	offsets := make([]committedOffset, 0, len(groups))
+	for key, g := range groups {
+		for p, off := range g.committed {
+			offsets = append(offsets, committedOffset{
+				Topic:     key.topic,
+				GroupID:   key.groupID,
+				Partition: p,
+				Offset:    off,
+			})
+		}
+	}
+
+	raw, err := json.Marshal(offsets)
+	if err != nil {
+		return fmt.Errorf("localbroker - saveOffsets - marshal: %w", err)
+	}
+
+	tmp := filepath.Join(s.dir, offsetsFile+".tmp")
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("localbroker - saveOffsets - write: %w", err)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(s.dir, offsetsFile)); err != nil {
+		return fmt.Errorf("localbroker - saveOffsets - rename: %w", err)
+	}
+
+	return nil
+}
+
+func (s *fileStore) close() error {
+	var firstErr error
+	for topic, f := range s.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("localbroker - close - %s: %w", topic, err)
+		}
+	}
+	s.files = map[string]*os.File{}
+
+	return firstErr
+}
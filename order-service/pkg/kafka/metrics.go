@@ -0,0 +1,118 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Метрики консьюмеров и диспетчера регистрируются в prometheus.DefaultRegisterer
+// и отдаются сервисом на /metrics.
+var (
+	consumerLag = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kafka_consumer_lag",
+			Help: "Number of messages in the partitions assigned to the consumer that have not been read yet",
+		},
+		[]string{"topic", "group"},
+	)
+
+	consumerBatchDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kafka_consumer_handler_duration_seconds",
+			Help:    "Time spent in the consumer handler per batch",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"topic", "group"},
+	)
+
+	consumerBatchErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_handler_errors_total",
+			Help: "Number of batches the consumer handler failed to process",
+		},
+		[]string{"topic", "group"},
+	)
+
+	consumerBatchRetries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_handler_retries_total",
+			Help: "Number of times a failed batch was handed to the consumer handler again",
+		},
+		[]string{"topic", "group"},
+	)
+
+	consumerCommitFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_consumer_commit_failures_total",
+			Help: "Number of failed offset commits",
+		},
+		[]string{"topic", "group"},
+	)
+
+	consumerLastProcessed = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kafka_consumer_last_processed_timestamp_seconds",
+			Help: "Unix time when the consumer last finished processing a batch",
+		},
+		[]string{"topic", "group"},
+	)
+
+	eventHandlerDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kafka_event_handler_duration_seconds",
+			Help:    "Time spent in the Dispatcher handler per event type",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"event_type"},
+	)
+
+	eventHandlerErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kafka_event_handler_errors_total",
+			Help: "Number of events the Dispatcher handler failed to process, by event type",
+		},
+		[]string{"event_type"},
+	)
+
+	eventDecodeErrors = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kafka_event_decode_errors_total",
+			Help: "Number of messages skipped because the envelope or payload is invalid",
+		},
+	)
+)
+
+// consumerMetrics — метрики одной подписки (topic, group).
+type consumerMetrics struct {
+	topic         string
+	group         string
+	batchDuration prometheus.Observer
+	batchErrors   prometheus.Counter
+	batchRetries  prometheus.Counter
+	commitFailed  prometheus.Counter
+	lastProcessed prometheus.Gauge
+	lag           prometheus.Gauge
+}
+
+func newConsumerMetrics(topic, group string) *consumerMetrics {
+	return &consumerMetrics{
+		topic:         topic,
+		group:         group,
+		batchDuration: consumerBatchDuration.WithLabelValues(topic, group),
+		batchErrors:   consumerBatchErrors.WithLabelValues(topic, group),
+		batchRetries:  consumerBatchRetries.WithLabelValues(topic, group),
+		commitFailed:  consumerCommitFailures.WithLabelValues(topic, group),
+		lastProcessed: consumerLastProcessed.WithLabelValues(topic, group),
+		lag:           consumerLag.WithLabelValues(topic, group),
+	}
+}
+
+func (m *consumerMetrics) handled(start time.Time, err error) {
+	m.batchDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.batchErrors.Inc()
+	}
+	m.lastProcessed.SetToCurrentTime()
+}
@@ -0,0 +1,35 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumerMetrics(t *testing.T) {
+	m := newConsumerMetrics("metrics.test", "group")
+
+	m.handled(time.Now(), nil)
+	m.handled(time.Now(), errors.New("boom"))
+	require.Equal(t, 1.0, testutil.ToFloat64(consumerBatchErrors.WithLabelValues("metrics.test", "group")))
+	require.NotZero(t, testutil.ToFloat64(consumerLastProcessed.WithLabelValues("metrics.test", "group")))
+}
+
+type fixedLag int64
+
+func (l fixedLag) Lag() int64 { return int64(l) }
+
+func TestKafkaConsumer_lagLoop(t *testing.T) {
+	c := &KafkaConsumer{metrics: newConsumerMetrics("lag.test", "group")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Значение публикуется сразу при запуске, не дожидаясь первого тика.
+	c.lagLoop(ctx, fixedLag(7))
+	require.Equal(t, 7.0, testutil.ToFloat64(consumerLag.WithLabelValues("lag.test", "group")))
+}
@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
+)
+
+// PublisherOption -.
+type PublisherOption func(*KafkaPublisher)
+
+// PublisherSchemas задаёт реестр схем для валидации payload перед публикацией.
+// nil отключает валидацию.
+func PublisherSchemas(r *schema.Registry) PublisherOption {
+	return func(p *KafkaPublisher) {
+		p.schemas = r
+	}
+}
+
+// PublisherBroker задаёт брокер, в который публикуются события (по умолчанию — Kafka).
+func PublisherBroker(b Broker) PublisherOption {
+	return func(p *KafkaPublisher) {
+		p.broker = b
+	}
+}
+
+// BrokerOption -.
+type BrokerOption func(*kafkaBroker)
+
+// BrokerProducer задаёт acks, размер и таймаут пачки и сжатие записи в Kafka.
+// Mode и TransactionalID здесь не учитываются — для них есть NewProducerBroker.
+func BrokerProducer(cfg ProducerConfig) BrokerOption {
+	return func(b *kafkaBroker) {
+		b.producer = cfg
+	}
+}
+
+// ConsumerOption -.
+type ConsumerOption func(*KafkaConsumer)
+
+// ConsumerBroker задаёт брокер, из которого читаются события (по умолчанию — Kafka).
+func ConsumerBroker(b Broker) ConsumerOption {
+	return func(c *KafkaConsumer) {
+		c.broker = b
+	}
+}
+
+// ConsumerConcurrency задаёт число воркеров. Сообщения с одинаковым ключом
+// всегда обрабатываются одним воркером, поэтому порядок по ключу сохраняется.
+// Порядок событий одного агрегата сохраняется, только если продюсер ключует их id агрегата
+// (так публикует outbox.Worker).
+func ConsumerConcurrency(n int) ConsumerOption {
+	return func(c *KafkaConsumer) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// ConsumerBatch задаёт максимальный размер пачки для SubscribeBatch и время её набора.
+func ConsumerBatch(size int, wait time.Duration) ConsumerOption {
+	return func(c *KafkaConsumer) {
+		if size > 0 {
+			c.batchSize = size
+		}
+		c.batchWait = wait
+	}
+}
+
+// ConsumerMaxWait задаёт максимальное время ожидания новых сообщений брокером.
+func ConsumerMaxWait(d time.Duration) ConsumerOption {
+	return func(c *KafkaConsumer) {
+		c.maxWait = d
+	}
+}
+
+// ConsumerSessionTimeout задаёт таймаут сессии участника consumer group.
+func ConsumerSessionTimeout(d time.Duration) ConsumerOption {
+	return func(c *KafkaConsumer) {
+		c.sessionTimeout = d
+	}
+}
+
+// ConsumerHeartbeatInterval задаёт интервал heartbeat участника consumer group.
+func ConsumerHeartbeatInterval(d time.Duration) ConsumerOption {
+	return func(c *KafkaConsumer) {
+		c.heartbeatInterval = d
+	}
+}
+
+// ConsumerCommitInterval задаёт, как часто коммитятся обработанные offset.
+// 0 — коммит сразу после каждой обработанной пачки.
+func ConsumerCommitInterval(d time.Duration) ConsumerOption {
+	return func(c *KafkaConsumer) {
+		c.commitInterval = d
+	}
+}
+
+// ConsumerRetryBackoff задаёт паузу перед повтором пачки, на которой упал обработчик.
+// Пауза удваивается с каждой попыткой, но не превышает max.
+func ConsumerRetryBackoff(initial, max time.Duration) ConsumerOption {
+	return func(c *KafkaConsumer) {
+		if initial > 0 {
+			c.retryBackoff = initial
+		}
+		if max > 0 {
+			c.retryMaxBackoff = max
+		}
+		if c.retryMaxBackoff < c.retryBackoff {
+			c.retryMaxBackoff = c.retryBackoff
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// Режимы продюсера (ProducerConfig.Mode).
+const (
+	// ProducerModeDefault — обычная запись через kafka-go, без гарантий от дублей при ретраях.
+	ProducerModeDefault = ""
+	// ProducerModeIdempotent — идемпотентный продюсер: ретраи записи не создают дублей в партиции.
+	// Требует acks=all.
+	ProducerModeIdempotent = "idempotent"
+	// ProducerModeTransactional — каждый вызов WriteMessages — отдельная транзакция Kafka:
+	// консьюмеры с read_committed видят либо все сообщения пачки, либо ни одного.
+	ProducerModeTransactional = "transactional"
+)
+
+// ProducerConfig — настройки записи в Kafka.
+type ProducerConfig struct {
+	// RequiredAcks: 0 — без подтверждения, 1 — лидер партиции, -1 — все in-sync реплики.
+	RequiredAcks int
+	// BatchSize — сколько сообщений kafka-go отправляет одним запросом (только ProducerModeDefault).
+	BatchSize int
+	// BatchTimeout — сколько продюсер ждёт наполнения пачки перед отправкой.
+	BatchTimeout time.Duration
+	// Compression — кодек сжатия: none, gzip, snappy, lz4, zstd. Пустой — без сжатия.
+	Compression string
+	// Mode — ProducerModeDefault, ProducerModeIdempotent или ProducerModeTransactional.
+	Mode string
+	// TransactionalID — идентификатор транзакционного продюсера, уникальный для реплики.
+	// Обязателен в ProducerModeTransactional.
+	TransactionalID string
+}
+
+// Validate проверяет сочетание настроек продюсера.
+func (c ProducerConfig) Validate() error {
+	switch c.RequiredAcks {
+	case 0, 1, -1:
+	default:
+		return fmt.Errorf("kafka - ProducerConfig: required_acks must be 0, 1 or -1, got %d", c.RequiredAcks)
+	}
+
+	if _, ok := compressionCodecs[c.Compression]; !ok {
+		return fmt.Errorf("kafka - ProducerConfig: unknown compression %q", c.Compression)
+	}
+
+	switch c.Mode {
+	case ProducerModeDefault:
+	case ProducerModeIdempotent, ProducerModeTransactional:
+		if c.RequiredAcks != -1 {
+			return fmt.Errorf("kafka - ProducerConfig: %s mode requires required_acks -1", c.Mode)
+		}
+		if c.Mode == ProducerModeTransactional && c.TransactionalID == "" {
+			return errors.New("kafka - ProducerConfig: transactional mode requires transactional_id")
+		}
+	default:
+		return fmt.Errorf("kafka - ProducerConfig: unknown mode %q", c.Mode)
+	}
+
+	return nil
+}
+
+var compressionCodecs = map[string]struct {
+	kafkaGo kafka.Compression
+	franz   kgo.CompressionCodec
+}{
+	"":       {0, kgo.NoCompression()},
+	"none":   {0, kgo.NoCompression()},
+	"gzip":   {kafka.Gzip, kgo.GzipCompression()},
+	"snappy": {kafka.Snappy, kgo.SnappyCompression()},
+	"lz4":    {kafka.Lz4, kgo.Lz4Compression()},
+	"zstd":   {kafka.Zstd, kgo.ZstdCompression()},
+}
+
+// newWriter строит kafka-go writer по настройкам продюсера.
+func (c ProducerConfig) newWriter(brokers []string) *kafka.Writer {
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Async:        false,
+		BatchTimeout: 10 * time.Millisecond,
+		RequiredAcks: kafka.RequiredAcks(c.RequiredAcks),
+		Compression:  compressionCodecs[c.Compression].kafkaGo,
+	}
+	if c.BatchSize > 0 {
+		w.BatchSize = c.BatchSize
+	}
+	if c.BatchTimeout > 0 {
+		w.BatchTimeout = c.BatchTimeout
+	}
+
+	return w
+}
+
+// franzBroker — Broker с идемпотентным или транзакционным продюсером на franz-go
+// (kafka-go ни то, ни другое не поддерживает). Чтение делегируется kafka-go.
+type franzBroker struct {
+	*kafkaBroker
+	client        *kgo.Client
+	transactional bool
+	// mu сериализует транзакции: у продюсера одновременно может быть открыта только одна.
+	mu sync.Mutex
+}
+
+// NewProducerBroker создаёт Broker с продюсером по настройкам cfg.
+// В ProducerModeDefault это NewKafkaBroker, в остальных режимах — продюсер franz-go.
+func NewProducerBroker(brokers []string, cfg ProducerConfig) (Broker, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Mode == ProducerModeDefault {
+		return NewKafkaBroker(brokers, BrokerProducer(cfg)), nil
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(brokers...),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.ProducerBatchCompression(compressionCodecs[cfg.Compression].franz),
+	}
+	if cfg.BatchTimeout > 0 {
+		opts = append(opts, kgo.ProducerLinger(cfg.BatchTimeout))
+	}
+	if cfg.Mode == ProducerModeTransactional {
+		opts = append(opts, kgo.TransactionalID(cfg.TransactionalID))
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("kafka - NewProducerBroker - kgo.NewClient: %w", err)
+	}
+
+	return &franzBroker{
+		kafkaBroker:   &kafkaBroker{brokers: brokers},
+		client:        client,
+		transactional: cfg.Mode == ProducerModeTransactional,
+	}, nil
+}
+
+func (b *franzBroker) WriteMessages(ctx context.Context, msgs ...Message) error {
+	records := make([]*kgo.Record, 0, len(msgs))
+	for _, m := range msgs {
+		records = append(records, &kgo.Record{
+			Topic:     m.Topic,
+			Key:       m.Key,
+			Value:     m.Value,
+			Timestamp: m.Time,
+		})
+	}
+
+	if !b.transactional {
+		return b.client.ProduceSync(ctx, records...).FirstErr()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.client.BeginTransaction(); err != nil {
+		return fmt.Errorf("kafka - franzBroker - begin transaction: %w", err)
+	}
+
+	if err := b.client.ProduceSync(ctx, records...).FirstErr(); err != nil {
+		// Неотправленные записи выбрасываются, уже записанные будут отменены вместе с транзакцией.
+		if abortErr := b.client.AbortBufferedRecords(ctx); abortErr != nil {
+			return errors.Join(err, abortErr)
+		}
+		if abortErr := b.client.EndTransaction(ctx, kgo.TryAbort); abortErr != nil {
+			return errors.Join(err, abortErr)
+		}
+		return err
+	}
+
+	if err := b.client.EndTransaction(ctx, kgo.TryCommit); err != nil {
+		return fmt.Errorf("kafka - franzBroker - commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (b *franzBroker) Close() error {
+	b.client.Close()
+	return nil
+}
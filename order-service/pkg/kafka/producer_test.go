@@ -0,0 +1,100 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProducerConfig_Validate(t *testing.T) {
+	require.NoError(t, ProducerConfig{RequiredAcks: 1, Compression: "snappy"}.Validate())
+	require.NoError(t, ProducerConfig{RequiredAcks: -1, Mode: ProducerModeIdempotent}.Validate())
+	require.NoError(t, ProducerConfig{RequiredAcks: -1, Mode: ProducerModeTransactional, TransactionalID: "order-1"}.Validate())
+
+	require.Error(t, ProducerConfig{RequiredAcks: 2}.Validate())
+	require.Error(t, ProducerConfig{Compression: "brotli"}.Validate())
+	require.Error(t, ProducerConfig{RequiredAcks: 1, Mode: ProducerModeIdempotent}.Validate())
+	require.Error(t, ProducerConfig{RequiredAcks: -1, Mode: ProducerModeTransactional}.Validate())
+	require.Error(t, ProducerConfig{Mode: "exactly-once"}.Validate())
+}
+
+// recordingBroker запоминает записанные пачки и возвращает заданную ошибку.
+type recordingBroker struct {
+	Broker
+	writes [][]Message
+	err    error
+}
+
+func (b *recordingBroker) WriteMessages(_ context.Context, msgs ...Message) error {
+	b.writes = append(b.writes, msgs)
+	return b.err
+}
+
+func TestKafkaPublisher_PublishBatch(t *testing.T) {
+	ctx := context.Background()
+
+	valid := func() Publication {
+		return Publication{
+			Topic:      "order.events",
+			EventID:    uuid.New(),
+			EventType:  events.TypeOrderPrepared,
+			OccurredAt: time.Now().UTC(),
+			Payload:    events.OrderPrepared{OrderID: uuid.New()},
+		}
+	}
+	invalid := Publication{Topic: "order.events", EventID: uuid.New(), EventType: events.TypeOrderPrepared, Payload: map[string]int{"x": 1}}
+
+	t.Run("one write for the whole batch", func(t *testing.T) {
+		b := &recordingBroker{}
+		p := NewKafkaPublisher(nil, PublisherBroker(b), PublisherSchemas(schema.Default()))
+
+		batch := []Publication{valid(), valid(), valid()}
+		batch[1].Key = "order-42"
+
+		require.NoError(t, p.PublishBatch(ctx, batch))
+		require.Len(t, b.writes, 1)
+		require.Len(t, b.writes[0], 3)
+		require.Equal(t, []byte("order-42"), b.writes[0][1].Key)
+	})
+
+	t.Run("invalid payload fails only its event", func(t *testing.T) {
+		b := &recordingBroker{}
+		p := NewKafkaPublisher(nil, PublisherBroker(b))
+
+		err := p.PublishBatch(ctx, []Publication{valid(), invalid, valid()})
+
+		var batchErr *BatchError
+		require.ErrorAs(t, err, &batchErr)
+		require.NoError(t, batchErr.Errs[0])
+		require.Error(t, batchErr.Errs[1])
+		require.NoError(t, batchErr.Errs[2])
+		require.Len(t, b.writes[0], 2)
+	})
+
+	t.Run("per-message write errors", func(t *testing.T) {
+		b := &recordingBroker{err: kafka.WriteErrors{nil, errors.New("leader not available")}}
+		p := NewKafkaPublisher(nil, PublisherBroker(b))
+
+		err := p.PublishBatch(ctx, []Publication{valid(), valid()})
+
+		var batchErr *BatchError
+		require.ErrorAs(t, err, &batchErr)
+		require.NoError(t, batchErr.Errs[0])
+		require.Error(t, batchErr.Errs[1])
+	})
+
+	t.Run("whole batch error", func(t *testing.T) {
+		b := &recordingBroker{err: errors.New("transaction aborted")}
+		p := NewKafkaPublisher(nil, PublisherBroker(b))
+
+		err := p.PublishBatch(ctx, []Publication{valid(), valid()})
+		require.EqualError(t, err, "transaction aborted")
+	})
+}
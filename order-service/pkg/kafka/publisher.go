@@ -2,66 +2,163 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
 	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
 )
 
-// KafkaPublisher — обёртка над kafka-go Writer для публикации событий в формате Envelope.
+// KafkaPublisher публикует события в формате Envelope через Broker.
 type KafkaPublisher struct {
-	writer *kafka.Writer
+	broker Broker
+	// schemas — реестр схем, по которому проверяется payload перед публикацией.
+	schemas *schema.Registry
 }
 
 // NewKafkaPublisher создаёт синхронный Kafka‑паблишер с минимальными настройками,
 // используя переданный список брокеров.
-func NewKafkaPublisher(brokers []string) *KafkaPublisher {
-	return &KafkaPublisher{
-		writer: &kafka.Writer{
-			Addr:         kafka.TCP(brokers...),
-			Async:        false,
-			BatchTimeout: 10 * time.Millisecond,
-		},
+// По умолчанию payload проверяется по реестру schema.Default().
+// Опция PublisherBroker позволяет публиковать не в Kafka, а, например, в in-memory брокер.
+func NewKafkaPublisher(brokers []string, opts ...PublisherOption) *KafkaPublisher {
+	p := &KafkaPublisher{
+		schemas: schema.Default(),
 	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if p.broker == nil {
+		p.broker = NewKafkaBroker(brokers)
+	}
+
+	return p
 }
 
-// Publish сериализует произвольный payload в JSON, заворачивает его в Envelope
-// и публикует в Kafka в указанный topic.
+// Close дописывает буферизованные сообщения и закрывает соединения с брокером.
+func (p *KafkaPublisher) Close() error {
+	return p.broker.Close()
+}
+
+// Publish сериализует произвольный payload в JSON, проверяет его по последней версии
+// схемы события, заворачивает в Envelope и публикует в Kafka в указанный topic.
+// Payload, не прошедший валидацию, не публикуется.
 //
-// Ключ сообщения (Key) — это eventType, чтобы события одного типа лежали последовательно в партициях.
+// Ключ сообщения (Key) — eventType: у разового события нет агрегата, по которому его упорядочивать.
 func (p *KafkaPublisher) Publish(ctx context.Context, topic string, eventType string, payload any) error {
-	var raw json.RawMessage
-	switch v := payload.(type) {
-	case json.RawMessage:
-		raw = v
-	case []byte:
-		raw = json.RawMessage(v)
-	default:
-		data, err := json.Marshal(payload)
+	return p.PublishEvent(ctx, topic, eventType, uuid.New(), eventType, time.Now().UTC(), payload)
+}
+
+// PublishEvent работает как Publish, но ключ сообщения, eventId и occurredAt задаются вызывающим.
+// Outbox передаёт сюда id агрегата как ключ — события одного заказа попадают в одну партицию
+// и обрабатываются консьюмером по порядку, — а также id и время создания записи, поэтому повторная
+// публикация той же записи (ретрай или replay) даёт то же eventId и дедуплицируется консьюмерами.
+func (p *KafkaPublisher) PublishEvent(
+	ctx context.Context,
+	topic string,
+	key string,
+	eventID uuid.UUID,
+	eventType string,
+	occurredAt time.Time,
+	payload any,
+) error {
+	raw, err := EncodeEnvelope(p.schemas, eventID, eventType, occurredAt, payload)
+	if err != nil {
+		return err
+	}
+
+	msg := Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: raw,
+	}
+
+	return p.broker.WriteMessages(ctx, msg)
+}
+
+// Publication — одно событие пачки PublishBatch.
+type Publication struct {
+	Topic string
+	// Key — ключ сообщения, см. PublishEvent.
+	Key        string
+	EventID    uuid.UUID
+	EventType  string
+	OccurredAt time.Time
+	Payload    any
+}
+
+// BatchError — результат частично неудавшейся PublishBatch: Errs[i] — ошибка публикации
+// batch[i], nil — событие опубликовано.
+type BatchError struct {
+	Errs []error
+}
+
+func (e *BatchError) Error() string {
+	failed := 0
+	var first error
+	for _, err := range e.Errs {
 		if err != nil {
-			return err
+			failed++
+			if first == nil {
+				first = err
+			}
 		}
-		raw = json.RawMessage(data)
 	}
+	return fmt.Sprintf("kafka - PublishBatch: %d of %d events failed, first: %v", failed, len(e.Errs), first)
+}
 
-	envelope := Envelope{
-		EventID:    uuid.New(),
-		EventType:  eventType,
-		OccurredAt: time.Now().UTC(),
-		Data:       raw,
+// PublishBatch публикует пачку событий одним вызовом WriteMessages.
+// Событие с невалидным payload в пачку не попадает и отмечается в BatchError.
+// Если брокер вернул ошибку по отдельным сообщениям (kafka.WriteErrors), она раскладывается
+// по событиям; любая другая ошибка записи относится ко всей пачке и возвращается как есть —
+// так ведёт себя транзакционный брокер, у которого пачка либо видна целиком, либо нет.
+func (p *KafkaPublisher) PublishBatch(ctx context.Context, batch []Publication) error {
+	errs := make([]error, len(batch))
+	msgs := make([]Message, 0, len(batch))
+	// index[i] — позиция в batch сообщения msgs[i].
+	index := make([]int, 0, len(batch))
+
+	for i, pub := range batch {
+		raw, err := EncodeEnvelope(p.schemas, pub.EventID, pub.EventType, pub.OccurredAt, pub.Payload)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		msgs = append(msgs, Message{Topic: pub.Topic, Key: []byte(pub.Key), Value: raw})
+		index = append(index, i)
 	}
 
-	raw, err := json.Marshal(&envelope)
-	if err != nil {
-		return err
+	failed := len(batch) - len(msgs)
+
+	if len(msgs) > 0 {
+		err := p.broker.WriteMessages(ctx, msgs...)
+
+		var writeErrs kafka.WriteErrors
+		switch {
+		case err == nil:
+		case errors.As(err, &writeErrs) && len(writeErrs) == len(msgs):
+			for i, werr := range writeErrs {
+				if werr != nil {
+					errs[index[i]] = werr
+					failed++
+				}
+			}
+		case failed == 0:
+			return err
+		default:
+			for _, i := range index {
+				errs[i] = err
+			}
+			failed = len(batch)
+		}
 	}
 
-	msg := kafka.Message{
-		Topic: topic,
-		Key:   []byte(eventType),
-		Value: raw,
+	if failed > 0 {
+		return &BatchError{Errs: errs}
 	}
 
-	return p.writer.WriteMessages(ctx, msg)
+	return nil
 }
@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
+	"github.com/sirupsen/logrus"
+)
+
+// Publish публикует типизированное событие в topic.
+// Тип события берётся из ev.EventType(), поэтому перепутать тип и payload нельзя.
+func Publish[T events.Event](ctx context.Context, p *KafkaPublisher, topic string, ev T) error {
+	return p.Publish(ctx, topic, ev.EventType(), ev)
+}
+
+// Handler — типизированный обработчик события T.
+type Handler[T events.Event] func(ctx context.Context, env Envelope, ev T) error
+
+// Dispatcher разбирает Envelope и вызывает обработчик, зарегистрированный для его eventType.
+// Метод Dispatch совместим с KafkaConsumer.Subscribe.
+type Dispatcher struct {
+	schemas  *schema.Registry
+	handlers map[string]func(ctx context.Context, env Envelope) error
+}
+
+// NewDispatcher создаёт пустой диспетчер. Payload проверяется и апкастится по schemas;
+// nil отключает валидацию.
+func NewDispatcher(schemas *schema.Registry) *Dispatcher {
+	return &Dispatcher{
+		schemas:  schemas,
+		handlers: make(map[string]func(ctx context.Context, env Envelope) error),
+	}
+}
+
+// Handle регистрирует обработчик события T. Повторная регистрация заменяет предыдущий обработчик.
+func Handle[T events.Event](d *Dispatcher, fn Handler[T]) {
+	var zero T
+	eventType := zero.EventType()
+
+	d.handlers[eventType] = func(ctx context.Context, env Envelope) error {
+		var ev T
+		if err := json.Unmarshal(env.Data, &ev); err != nil {
+			// Payload уже прошёл схему, поэтому повтор не поможет — пропускаем сообщение.
+			logrus.Errorf("Dispatcher: invalid payload for %s: %v", eventType, err)
+			eventDecodeErrors.Inc()
+			return nil
+		}
+		return fn(ctx, env, ev)
+	}
+}
+
+// Dispatch обрабатывает одно сообщение Kafka.
+//
+// Поведение:
+//   - битый envelope или payload, не прошедший схему, логируется и пропускается (offset коммитится);
+//   - события без зарегистрированного обработчика пропускаются;
+//   - ошибка обработчика возвращается консьюмеру и учитывается в kafka_event_handler_errors_total.
+func (d *Dispatcher) Dispatch(ctx context.Context, key, value []byte) error {
+	env, err := DecodeEnvelope(value, d.schemas)
+	if err != nil {
+		logrus.Errorf("Dispatcher: failed to parse event: %v", err)
+		eventDecodeErrors.Inc()
+		return nil
+	}
+
+	handler, ok := d.handlers[env.EventType]
+	if !ok {
+		logrus.Debugf("Dispatcher: no handler for event type %s", env.EventType)
+		return nil
+	}
+
+	start := time.Now()
+	err = handler(ctx, env)
+	eventHandlerDuration.WithLabelValues(env.EventType).Observe(time.Since(start).Seconds())
+	if err != nil {
+		eventHandlerErrors.WithLabelValues(env.EventType).Inc()
+	}
+
+	return err
+}
+
+// DispatchBatch обрабатывает пачку сообщений по одному через Dispatch.
+// Позволяет использовать диспетчер как kafka.BatchHandler (например, для replay).
+// Возвращает первую ошибку обработчика, остальные сообщения пачки всё равно обрабатываются.
+func (d *Dispatcher) DispatchBatch(ctx context.Context, msgs []Message) error {
+	var firstErr error
+	for _, m := range msgs {
+		if err := d.Dispatch(ctx, m.Key, m.Value); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
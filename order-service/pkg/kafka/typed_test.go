@@ -0,0 +1,63 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func envelopeBytes(t *testing.T, eventType string, payload any) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	raw, err := json.Marshal(Envelope{
+		EventID:       uuid.New(),
+		EventType:     eventType,
+		SchemaVersion: 1,
+		OccurredAt:    time.Now().UTC(),
+		Data:          data,
+	})
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	orderID := uuid.New()
+
+	d := NewDispatcher(schema.Default())
+
+	var got events.KitchenReady
+	Handle(d, func(_ context.Context, env Envelope, ev events.KitchenReady) error {
+		require.Equal(t, events.TypeKitchenReady, env.EventType)
+		got = ev
+		return nil
+	})
+
+	handlerErr := errors.New("boom")
+	Handle(d, func(context.Context, Envelope, events.KitchenAccepted) error {
+		return handlerErr
+	})
+
+	require.NoError(t, d.Dispatch(ctx, nil, envelopeBytes(t, events.TypeKitchenReady, events.KitchenReady{OrderID: orderID})))
+	require.Equal(t, orderID, got.OrderID)
+
+	// Ошибка обработчика пробрасывается консьюмеру.
+	err := d.Dispatch(ctx, nil, envelopeBytes(t, events.TypeKitchenAccepted, events.KitchenAccepted{OrderID: orderID}))
+	require.ErrorIs(t, err, handlerErr)
+
+	// Событие без обработчика и невалидный payload пропускаются.
+	require.NoError(t, d.Dispatch(ctx, nil, envelopeBytes(t, events.TypeOrderPaid, events.OrderPaid{OrderID: orderID, PaymentID: uuid.New()})))
+	require.NoError(t, d.Dispatch(ctx, nil, envelopeBytes(t, events.TypeKitchenReady, map[string]any{"orderId": 42})))
+	require.NoError(t, d.Dispatch(ctx, nil, []byte("not json")))
+}
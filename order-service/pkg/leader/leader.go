@@ -0,0 +1,175 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTTL           = 15 * time.Second
+	defaultRetryInterval = 5 * time.Second
+	releaseTimeout       = 5 * time.Second
+)
+
+var (
+	// ErrLocked — блокировку держит другой владелец.
+	ErrLocked = errors.New("leader: lock is held by another owner")
+	// ErrLockLost — блокировка потеряна: истекла аренда или оборвалось соединение.
+	ErrLockLost = errors.New("leader: lock lost")
+)
+
+var isLeader = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "leader_is_leader",
+		Help: "1 if this replica currently holds the leadership for the job",
+	},
+	[]string{"name"},
+)
+
+// Lock — захваченная именованная блокировка.
+type Lock interface {
+	// Token — fencing token: у каждого следующего захвата блокировки он больше, чем у предыдущего.
+	// Его передают во внешние системы, чтобы они отклоняли запись от лидера, который уже сменился.
+	Token() int64
+	// Renew продлевает аренду. ErrLockLost — блокировку уже держит кто-то другой.
+	Renew(ctx context.Context) error
+	Release(ctx context.Context) error
+}
+
+// Locker захватывает именованные блокировки.
+type Locker interface {
+	// TryLock захватывает блокировку name на ttl без ожидания. ErrLocked — она занята.
+	TryLock(ctx context.Context, name string, ttl time.Duration) (Lock, error)
+}
+
+// Elector запускает задачи только на той реплике, которая держит блокировку задачи.
+type Elector struct {
+	locker        Locker
+	ttl           time.Duration
+	renewInterval time.Duration
+	retryInterval time.Duration
+}
+
+// New создаёт Elector поверх locker (NewPostgresLocker или redis.NewLocker).
+func New(locker Locker, opts ...Option) *Elector {
+	e := &Elector{
+		locker:        locker,
+		ttl:           defaultTTL,
+		retryInterval: defaultRetryInterval,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.renewInterval <= 0 || e.renewInterval >= e.ttl {
+		e.renewInterval = e.ttl / 3
+	}
+
+	return e
+}
+
+// RunAsLeader блокируется, пока не завершится ctx, и выполняет fn, когда реплика становится лидером name.
+// Контекст fn отменяется, если лидерство потеряно; после этого реплика снова участвует в выборах.
+// Fencing token текущего лидерства доступен в fn через Token(ctx).
+//
+// Если fn вернула ошибку, RunAsLeader отпускает блокировку и возвращает её; nil при живом ctx
+// означает, что задача закончена, и RunAsLeader тоже возвращает nil.
+func (e *Elector) RunAsLeader(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	for {
+		lock, err := e.locker.TryLock(ctx, name, e.ttl)
+		switch {
+		case err == nil:
+			done, err := e.lead(ctx, name, lock, fn)
+			if err != nil {
+				return fmt.Errorf("leader - RunAsLeader %s: %w", name, err)
+			}
+			if done {
+				return nil
+			}
+		case errors.Is(err, ErrLocked):
+		case ctx.Err() == nil:
+			log.Warnf("Elector.RunAsLeader: %s: try lock: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(e.retryInterval):
+		}
+	}
+}
+
+// lead выполняет fn, продлевая блокировку. done — fn завершилась сама или завершился ctx.
+func (e *Elector) lead(ctx context.Context, name string, lock Lock, fn func(ctx context.Context) error) (done bool, err error) {
+	log.Infof("Elector: became leader of %s, token %d", name, lock.Token())
+	isLeader.WithLabelValues(name).Set(1)
+	defer isLeader.WithLabelValues(name).Set(0)
+
+	defer func() {
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+		defer cancel()
+		if err := lock.Release(releaseCtx); err != nil {
+			log.Warnf("Elector: %s: release lock: %v", name, err)
+		}
+	}()
+
+	leaderCtx, cancel := context.WithCancel(withToken(ctx, lock.Token()))
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- fn(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(e.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-result:
+			if ctx.Err() != nil {
+				return true, nil
+			}
+			return true, err
+		case <-ticker.C:
+			if err := e.renew(ctx, lock); err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
+				log.Warnf("Elector: lost leadership of %s: %v", name, err)
+				cancel()
+				<-result
+				return false, nil
+			}
+		}
+	}
+}
+
+// renew продлевает блокировку с таймаутом ttl - renewInterval: к этому моменту аренда, продлённая
+// прошлым тиком, истекает, и зависший Renew (медленная БД или Redis) не должен оставлять реплику
+// лидером после того, как блокировку мог захватить кто-то другой. Таймаут считается потерей лидерства.
+func (e *Elector) renew(ctx context.Context, lock Lock) error {
+	renewCtx, cancel := context.WithTimeout(ctx, e.ttl-e.renewInterval)
+	defer cancel()
+
+	return lock.Renew(renewCtx)
+}
+
+type tokenKey struct{}
+
+func withToken(ctx context.Context, token int64) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+// Token возвращает fencing token лидерства, в рамках которого выполняется fn из RunAsLeader.
+func Token(ctx context.Context) (int64, bool) {
+	token, ok := ctx.Value(tokenKey{}).(int64)
+	return token, ok
+}
@@ -0,0 +1,156 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// memLocker — блокировки в памяти; lose отбирает блокировку у текущего владельца.
+type memLocker struct {
+	mu     sync.Mutex
+	held   map[string]*memLock
+	tokens int64
+	// hang — Renew зависает до отмены контекста.
+	hang bool
+}
+
+type memLock struct {
+	l     *memLocker
+	name  string
+	token int64
+}
+
+func newMemLocker() *memLocker {
+	return &memLocker{held: map[string]*memLock{}}
+}
+
+func (l *memLocker) TryLock(_ context.Context, name string, _ time.Duration) (Lock, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.held[name] != nil {
+		return nil, ErrLocked
+	}
+	l.tokens++
+	lock := &memLock{l: l, name: name, token: l.tokens}
+	l.held[name] = lock
+	return lock, nil
+}
+
+func (l *memLocker) lose(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, name)
+}
+
+func (m *memLock) Token() int64 { return m.token }
+
+func (m *memLock) Renew(ctx context.Context) error {
+	m.l.mu.Lock()
+	hang := m.l.hang
+	m.l.mu.Unlock()
+	if hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	m.l.mu.Lock()
+	defer m.l.mu.Unlock()
+	if m.l.held[m.name] != m {
+		return ErrLockLost
+	}
+	return nil
+}
+
+func (m *memLock) Release(context.Context) error {
+	m.l.mu.Lock()
+	defer m.l.mu.Unlock()
+	if m.l.held[m.name] == m {
+		delete(m.l.held, m.name)
+	}
+	return nil
+}
+
+func TestElector_RunAsLeader(t *testing.T) {
+	locker := newMemLocker()
+	e := New(locker, TTL(30*time.Millisecond), RetryInterval(5*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tokens := make(chan int64, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RunAsLeader(ctx, "job", func(ctx context.Context) error {
+			token, ok := Token(ctx)
+			require.True(t, ok)
+			tokens <- token
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+
+	require.Equal(t, int64(1), <-tokens)
+
+	// Вторая реплика не становится лидером, пока блокировка занята.
+	_, err := locker.TryLock(ctx, "job", time.Second)
+	require.ErrorIs(t, err, ErrLocked)
+
+	// Потеря блокировки отменяет fn, и реплика снова становится лидером с новым token.
+	locker.lose("job")
+	require.Equal(t, int64(2), <-tokens)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	_, err = locker.TryLock(context.Background(), "job", time.Second)
+	require.NoError(t, err, "lock must be released on shutdown")
+}
+
+func TestElector_RunAsLeader_fnError(t *testing.T) {
+	locker := newMemLocker()
+	e := New(locker)
+
+	errJob := errors.New("job failed")
+	err := e.RunAsLeader(context.Background(), "job", func(context.Context) error {
+		return errJob
+	})
+	require.ErrorIs(t, err, errJob)
+
+	_, err = locker.TryLock(context.Background(), "job", time.Second)
+	require.NoError(t, err)
+}
+
+func TestElector_RunAsLeader_renewTimeout(t *testing.T) {
+	locker := newMemLocker()
+	locker.hang = true
+	e := New(locker, TTL(30*time.Millisecond), RetryInterval(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lost := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RunAsLeader(ctx, "job", func(ctx context.Context) error {
+			<-ctx.Done()
+			close(lost)
+			return nil
+		})
+	}()
+
+	// Зависший Renew обрывается по таймауту, и реплика перестаёт считать себя лидером.
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("leadership was not dropped after renew timeout")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,32 @@
+package leader
+
+import "time"
+
+// Option -.
+type Option func(*Elector)
+
+// TTL задаёт срок аренды блокировки. Для Redis лидер, переставший продлевать аренду,
+// теряет лидерство не позже чем через TTL; блокировка Postgres живёт, пока живо соединение.
+func TTL(ttl time.Duration) Option {
+	return func(e *Elector) {
+		if ttl > 0 {
+			e.ttl = ttl
+		}
+	}
+}
+
+// RenewInterval задаёт период продления аренды. По умолчанию — треть TTL.
+func RenewInterval(d time.Duration) Option {
+	return func(e *Elector) {
+		e.renewInterval = d
+	}
+}
+
+// RetryInterval задаёт, как часто реплика пытается стать лидером, пока блокировка занята.
+func RetryInterval(d time.Duration) Option {
+	return func(e *Elector) {
+		if d > 0 {
+			e.retryInterval = d
+		}
+	}
+}
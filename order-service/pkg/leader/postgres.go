@@ -0,0 +1,86 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresLocker — блокировки на session-level advisory locks PostgreSQL.
+// Каждая захваченная блокировка занимает соединение пула primary, пока не будет отпущена:
+// при обрыве соединения Postgres снимает блокировку сам, и лидером становится другая реплика.
+//
+// Fencing token — txid_current() сразу после захвата: номера транзакций в кластере только растут,
+// поэтому следующий владелец блокировки получит больший token. Отдельная схема не нужна.
+type PostgresLocker struct {
+	pg *postgres.Postgres
+}
+
+func NewPostgresLocker(pg *postgres.Postgres) *PostgresLocker {
+	return &PostgresLocker{pg: pg}
+}
+
+// TryLock захватывает advisory lock с ключом hashtextextended(name). ttl не используется.
+func (l *PostgresLocker) TryLock(ctx context.Context, name string, _ time.Duration) (Lock, error) {
+	ctx = postgres.WithOperation(ctx, "PostgresLocker.TryLock")
+
+	conn, err := l.pg.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("leader - PostgresLocker.TryLock - acquire: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, name).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("leader - PostgresLocker.TryLock: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, ErrLocked
+	}
+
+	lock := &postgresLock{conn: conn, name: name}
+
+	if err := conn.QueryRow(ctx, `SELECT txid_current()`).Scan(&lock.token); err != nil {
+		_ = lock.Release(context.WithoutCancel(ctx))
+		return nil, fmt.Errorf("leader - PostgresLocker.TryLock - token: %w", err)
+	}
+
+	return lock, nil
+}
+
+type postgresLock struct {
+	conn  *pgxpool.Conn
+	name  string
+	token int64
+}
+
+func (l *postgresLock) Token() int64 {
+	return l.token
+}
+
+// Renew проверяет, что сессия с блокировкой жива: пока она жива, блокировку никто не снимет.
+func (l *postgresLock) Renew(ctx context.Context) error {
+	ctx = postgres.WithOperation(ctx, "PostgresLocker.Renew")
+
+	if err := l.conn.Ping(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrLockLost, err)
+	}
+	return nil
+}
+
+// Release снимает блокировку и возвращает соединение в пул. Если снять не удалось,
+// соединение закрывается — вместе с сессией Postgres снимет и блокировку.
+func (l *postgresLock) Release(ctx context.Context) error {
+	ctx = postgres.WithOperation(ctx, "PostgresLocker.Release")
+	defer l.conn.Release()
+
+	if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, l.name); err != nil {
+		_ = l.conn.Conn().Close(ctx)
+		return fmt.Errorf("leader - PostgresLocker.Release: %w", err)
+	}
+	return nil
+}
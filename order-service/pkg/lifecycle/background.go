@@ -0,0 +1,94 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+)
+
+// Background — фоновый цикл компонента, который можно остановить с ожиданием.
+// Нулевое значение готово к использованию; после Shutdown цикл можно запустить снова.
+type Background struct {
+	mu     sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+	cancel context.CancelFunc
+}
+
+// Go запускает fn в горутине. Канал stop закрывается в Shutdown: fn должна перестать брать
+// новую работу и вернуться. Контекст fn отменяется вместе с ctx или если Shutdown не дождался fn.
+func (b *Background) Go(ctx context.Context, fn func(ctx context.Context, stop <-chan struct{})) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop, done := make(chan struct{}), make(chan struct{})
+
+	b.mu.Lock()
+	b.stop, b.done, b.cancel = stop, done, cancel
+	b.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		defer cancel()
+		fn(ctx, stop)
+	}()
+}
+
+// Shutdown закрывает stop и ждёт завершения fn. Когда истекает ctx, контекст fn отменяется,
+// чтобы прервать текущую работу, и Shutdown возвращает ошибку ctx.
+func (b *Background) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	stop, done, cancel := b.stop, b.done, b.cancel
+	if done == nil {
+		b.mu.Unlock()
+		return nil
+	}
+	select {
+	case <-stop:
+	default:
+		close(stop)
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Go возвращает Hook для блокирующей функции fn: OnStart запускает её в горутине,
+// OnStop отменяет её контекст и ждёт возврата.
+func Go(name string, fn func(ctx context.Context)) Hook {
+	var b Background
+
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			b.Go(ctx, func(ctx context.Context, stop <-chan struct{}) {
+				ctx, cancel := WithStop(ctx, stop)
+				defer cancel()
+
+				fn(ctx)
+			})
+			return nil
+		},
+		OnStop: b.Shutdown,
+	}
+}
+
+// WithStop возвращает контекст, который отменяется при закрытии stop, — для циклов,
+// которым нечего дообрабатывать при остановке.
+func WithStop(ctx context.Context, stop <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,167 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultShutdownTimeout = 25 * time.Second
+
+// Hook — компонент приложения: HTTP-сервер, консьюмер, фоновый воркер, пул соединений.
+type Hook struct {
+	Name string
+	// OnStart запускает компонент и не блокируется. ctx живёт, пока не остановлены все компоненты.
+	OnStart func(ctx context.Context) error
+	// OnStop прекращает приём новой работы и дожидается завершения текущей, но не дольше ctx.
+	OnStop func(ctx context.Context) error
+}
+
+// Manager запускает компоненты в порядке регистрации и останавливает в обратном.
+// Регистрируйте их в порядке зависимостей: сначала пулы соединений, затем воркеры и консьюмеры,
+// последним — HTTP-сервер. Тогда при остановке сначала прекращается приём запросов и сообщений,
+// затем дообрабатывается начатая работа, и только потом закрываются пулы.
+type Manager struct {
+	hooks           []Hook
+	started         int
+	shutdownTimeout time.Duration
+
+	// ctx передаётся в OnStart и отменяется после остановки всех компонентов.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	failed   chan error
+	failOnce sync.Once
+}
+
+func New(opts ...Option) *Manager {
+	m := &Manager{
+		shutdownTimeout: defaultShutdownTimeout,
+		failed:          make(chan error, 1),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+
+	return m
+}
+
+// Append регистрирует компоненты. OnStart и OnStop могут быть nil.
+func (m *Manager) Append(hooks ...Hook) {
+	m.hooks = append(m.hooks, hooks...)
+}
+
+// Start запускает компоненты по порядку. Если компонент не запустился, уже запущенные
+// останавливаются в обратном порядке, и Start возвращает ошибку запуска.
+func (m *Manager) Start() error {
+	for _, h := range m.hooks {
+		if h.OnStart != nil {
+			log.Infof("Lifecycle: starting %s", h.Name)
+			if err := h.OnStart(m.ctx); err != nil {
+				err = fmt.Errorf("lifecycle - Start %s: %w", h.Name, err)
+				stopCtx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+				defer cancel()
+				return errors.Join(err, m.Stop(stopCtx))
+			}
+		}
+		m.started++
+	}
+
+	return nil
+}
+
+// Stop останавливает запущенные компоненты в обратном порядке. Все OnStop делят бюджет ctx:
+// компонент, не уложившийся в него, получает отменённый контекст, но следующие всё равно
+// останавливаются — пулы закрываются в любом случае.
+func (m *Manager) Stop(ctx context.Context) error {
+	var errs []error
+
+	for i := m.started - 1; i >= 0; i-- {
+		h := m.hooks[i]
+		if h.OnStop == nil {
+			continue
+		}
+
+		start := time.Now()
+		if err := h.OnStop(ctx); err != nil {
+			log.Errorf("Lifecycle: stop %s: %v", h.Name, err)
+			errs = append(errs, fmt.Errorf("lifecycle - Stop %s: %w", h.Name, err))
+			continue
+		}
+		log.Infof("Lifecycle: stopped %s in %v", h.Name, time.Since(start).Round(time.Millisecond))
+	}
+	m.started = 0
+	m.cancel()
+
+	return errors.Join(errs...)
+}
+
+// Fail сообщает о неустранимой ошибке компонента (например, HTTP-сервер не смог слушать порт):
+// Run начинает остановку приложения. Учитывается только первая ошибка.
+func (m *Manager) Fail(name string, err error) {
+	m.failOnce.Do(func() {
+		m.failed <- fmt.Errorf("%s: %w", name, err)
+	})
+}
+
+// Watch вызывает Fail, если из errc придёт ошибка. Удобно для каналов вида httpserver.Notify:
+// http.ErrServerClosed после штатной остановки ошибкой не считается.
+func (m *Manager) Watch(name string, errc <-chan error) {
+	go func() {
+		select {
+		case err, ok := <-errc:
+			if ok && err != nil && !errors.Is(err, http.ErrServerClosed) {
+				m.Fail(name, err)
+			}
+		case <-m.ctx.Done():
+		}
+	}()
+}
+
+// Run запускает компоненты, ждёт сигнала из interrupt или ошибки компонента и останавливает
+// приложение в пределах ShutdownTimeout.
+func (m *Manager) Run(interrupt <-chan os.Signal) error {
+	if err := m.Start(); err != nil {
+		return err
+	}
+
+	var runErr error
+	select {
+	case s := <-interrupt:
+		log.Infof("Lifecycle: signal %v, shutting down", s)
+	case runErr = <-m.failed:
+		log.Errorf("Lifecycle: %v, shutting down", runErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.shutdownTimeout)
+	defer cancel()
+
+	return errors.Join(runErr, m.Stop(ctx))
+}
+
+// Runner — компонент с неблокирующим Run и ожидающим Shutdown (outbox.Worker, outbox.Listener и т.п.).
+type Runner interface {
+	Run(ctx context.Context)
+	Shutdown(ctx context.Context) error
+}
+
+// Component возвращает Hook для Runner.
+func Component(name string, r Runner) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			r.Run(ctx)
+			return nil
+		},
+		OnStop: r.Shutdown,
+	}
+}
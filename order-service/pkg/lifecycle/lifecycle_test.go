@@ -0,0 +1,104 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Run(t *testing.T) {
+	var events []string
+	hook := func(name string) Hook {
+		return Hook{
+			Name: name,
+			OnStart: func(context.Context) error {
+				events = append(events, "start "+name)
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				events = append(events, "stop "+name)
+				return nil
+			},
+		}
+	}
+
+	m := New()
+	m.Append(hook("postgres"), hook("worker"), hook("http"))
+
+	interrupt := make(chan os.Signal, 1)
+	interrupt <- syscall.SIGTERM
+	require.NoError(t, m.Run(interrupt))
+
+	require.Equal(t, []string{
+		"start postgres", "start worker", "start http",
+		"stop http", "stop worker", "stop postgres",
+	}, events)
+}
+
+func TestManager_StartFailure(t *testing.T) {
+	var stopped []string
+	errBoom := errors.New("boom")
+
+	m := New()
+	m.Append(
+		Hook{Name: "postgres", OnStop: func(context.Context) error {
+			stopped = append(stopped, "postgres")
+			return nil
+		}},
+		Hook{Name: "consumer", OnStart: func(context.Context) error { return errBoom }},
+		Hook{Name: "http", OnStop: func(context.Context) error {
+			stopped = append(stopped, "http")
+			return nil
+		}},
+	)
+
+	require.ErrorIs(t, m.Start(), errBoom)
+	require.Equal(t, []string{"postgres"}, stopped)
+}
+
+func TestManager_Fail(t *testing.T) {
+	errListen := errors.New("address already in use")
+	errc := make(chan error, 1)
+
+	m := New()
+	m.Append(Hook{Name: "http", OnStart: func(context.Context) error {
+		m.Watch("http", errc)
+		errc <- errListen
+		return nil
+	}})
+
+	require.ErrorIs(t, m.Run(make(chan os.Signal)), errListen)
+}
+
+func TestBackground_Shutdown(t *testing.T) {
+	var b Background
+	finished := make(chan struct{})
+
+	b.Go(context.Background(), func(ctx context.Context, stop <-chan struct{}) {
+		<-stop
+		// Дообработка текущей работы.
+		time.Sleep(10 * time.Millisecond)
+		close(finished)
+	})
+
+	require.NoError(t, b.Shutdown(context.Background()))
+	select {
+	case <-finished:
+	default:
+		t.Fatal("Shutdown returned before the loop finished")
+	}
+
+	// Не уложившийся в бюджет цикл получает отменённый контекст.
+	b.Go(context.Background(), func(ctx context.Context, _ <-chan struct{}) {
+		<-ctx.Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, b.Shutdown(ctx), context.DeadlineExceeded)
+}
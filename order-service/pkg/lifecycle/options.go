@@ -0,0 +1,15 @@
+package lifecycle
+
+import "time"
+
+// Option -.
+type Option func(*Manager)
+
+// ShutdownTimeout задаёт общий бюджет остановки всех компонентов в Run.
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(m *Manager) {
+		if timeout > 0 {
+			m.shutdownTimeout = timeout
+		}
+	}
+}
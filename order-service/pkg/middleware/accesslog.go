@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// AccessLog пишет по строке на запрос: метод, шаблон маршрута, статус, время обработки и размеры.
+// Ошибку обработчика он отдаёт в HTTPErrorHandler сам, чтобы залогировать итоговый статус.
+// 5xx пишутся с уровнем error, 4xx — warn, остальные — info.
+func AccessLog(skip map[string]bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			req, res := c.Request(), c.Response()
+			if skip[req.URL.Path] {
+				return nil
+			}
+
+			entry := log.WithFields(log.Fields{
+				"request_id": RequestIDFromContext(req.Context()),
+				"method":     req.Method,
+				"route":      c.Path(),
+				"uri":        req.RequestURI,
+				"remote":     c.RealIP(),
+				"status":     res.Status,
+				"latency":    time.Since(start).String(),
+				"bytes_in":   req.ContentLength,
+				"bytes_out":  res.Size,
+			})
+
+			switch {
+			case res.Status >= http.StatusInternalServerError:
+				entry.Error("request completed")
+			case res.Status >= http.StatusBadRequest:
+				entry.Warn("request completed")
+			default:
+				entry.Info("request completed")
+			}
+
+			return nil
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echomw "github.com/labstack/echo/v4/middleware"
+)
+
+const (
+	defaultBodyLimit      = "1M"
+	defaultRequestTimeout = 4 * time.Second
+)
+
+// stack — настройки стандартного набора middleware.
+type stack struct {
+	bodyLimit      string
+	requestTimeout time.Duration
+	routeTimeouts  map[string]time.Duration
+	skipLog        map[string]bool
+}
+
+// Install подключает к e стандартный набор middleware в таком порядке:
+// request ID, access log, метрики, recovery, ограничение тела запроса, таймаут.
+// Access log и метрики видят итоговый статус, в том числе после паники и таймаута.
+func Install(e *echo.Echo, opts ...Option) {
+	s := &stack{
+		bodyLimit:      defaultBodyLimit,
+		requestTimeout: defaultRequestTimeout,
+		skipLog:        map[string]bool{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	e.Use(
+		RequestID(),
+		AccessLog(s.skipLog),
+		Metrics(),
+		Recover(),
+		echomw.BodyLimit(s.bodyLimit),
+		Timeout(s.requestTimeout, s.routeTimeouts),
+	)
+}
+
+// routeKey — ключ маршрута для настроек по маршрутам: "METHOD /path/:param".
+func routeKey(c echo.Context) string {
+	return c.Request().Method + " " + c.Path()
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEcho(opts ...Option) *echo.Echo {
+	e := echo.New()
+	Install(e, opts...)
+	return e
+}
+
+func serve(e *echo.Echo, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequestID_PropagatesHeader(t *testing.T) {
+	e := newEcho()
+	var got string
+	e.GET("/ping", func(c echo.Context) error {
+		got = RequestIDFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(HeaderRequestID, "abc-123")
+	rec := serve(e, req)
+
+	assert.Equal(t, "abc-123", got)
+	assert.Equal(t, "abc-123", rec.Header().Get(HeaderRequestID))
+}
+
+func TestRequestID_GeneratesWhenMissing(t *testing.T) {
+	e := newEcho()
+	e.GET("/ping", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	rec := serve(e, httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	assert.NotEmpty(t, rec.Header().Get(HeaderRequestID))
+}
+
+func TestRecover_Returns500(t *testing.T) {
+	e := newEcho()
+	e.GET("/panic/:id", func(c echo.Context) error { panic("boom") })
+
+	before := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/panic/:id", "500"))
+	rec := serve(e, httptest.NewRequest(http.MethodGet, "/panic/1", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/panic/:id", "500")))
+}
+
+func TestMetrics_UnmatchedRoute(t *testing.T) {
+	e := newEcho()
+	e.GET("/known", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	before := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404"))
+	rec := serve(e, httptest.NewRequest(http.MethodGet, "/random/path", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+}
+
+func TestBodyLimit(t *testing.T) {
+	e := newEcho(BodyLimit("1K"))
+	e.POST("/orders", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(strings.Repeat("x", 2048)))
+	rec := serve(e, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestTimeout_PerRoute(t *testing.T) {
+	e := newEcho(
+		RequestTimeout(time.Second),
+		RouteTimeouts(map[string]time.Duration{"GET /slow": 20 * time.Millisecond}),
+	)
+	var deadline time.Duration
+	handler := func(c echo.Context) error {
+		dl, ok := c.Request().Context().Deadline()
+		require.True(t, ok)
+		deadline = time.Until(dl)
+
+		<-c.Request().Context().Done()
+		return c.Request().Context().Err()
+	}
+	e.GET("/slow", handler)
+
+	rec := serve(e, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.LessOrEqual(t, deadline, 20*time.Millisecond)
+}
+
+func TestTimeout_Default(t *testing.T) {
+	e := newEcho(RequestTimeout(time.Second))
+	e.GET("/fast", func(c echo.Context) error {
+		dl, ok := c.Request().Context().Deadline()
+		require.True(t, ok)
+		assert.Greater(t, time.Until(dl), 500*time.Millisecond)
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := serve(e, httptest.NewRequest(http.MethodGet, "/fast", nil).WithContext(context.Background()))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
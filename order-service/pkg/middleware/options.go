@@ -0,0 +1,42 @@
+package middleware
+
+import "time"
+
+// Option -.
+type Option func(*stack)
+
+// BodyLimit ограничивает размер тела запроса ("512K", "1M"); больше — 413 Request Entity Too Large.
+func BodyLimit(limit string) Option {
+	return func(s *stack) {
+		if limit != "" {
+			s.bodyLimit = limit
+		}
+	}
+}
+
+// RequestTimeout задаёт таймаут обработки запроса по умолчанию.
+func RequestTimeout(d time.Duration) Option {
+	return func(s *stack) {
+		if d > 0 {
+			s.requestTimeout = d
+		}
+	}
+}
+
+// RouteTimeouts переопределяет таймаут для отдельных маршрутов. Ключ — метод и шаблон маршрута:
+// "POST /admin/outbox/republish".
+func RouteTimeouts(timeouts map[string]time.Duration) Option {
+	return func(s *stack) {
+		s.routeTimeouts = timeouts
+	}
+}
+
+// SkipLog отключает access log для путей, которые дёргаются по расписанию (/health, /metrics).
+// Метрики по ним пишутся как обычно.
+func SkipLog(paths ...string) Option {
+	return func(s *stack) {
+		for _, p := range paths {
+			s.skipLog[p] = true
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// Recover перехватывает панику обработчика, пишет её в лог со стеком и отвечает 500.
+// http.ErrAbortHandler пробрасывается дальше: им net/http обрывает ответ намеренно.
+func Recover() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				log.WithFields(log.Fields{
+					"request_id": RequestIDFromContext(c.Request().Context()),
+					"method":     c.Request().Method,
+					"route":      c.Path(),
+					"stack":      string(debug.Stack()),
+				}).Errorf("Recover: panic: %v", r)
+
+				err = echo.NewHTTPError(http.StatusInternalServerError).
+					SetInternal(fmt.Errorf("panic: %v", r))
+			}()
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderRequestID — заголовок, в котором gateway передаёт ID запроса во внутренние сервисы.
+const HeaderRequestID = echo.HeaderXRequestID
+
+// maxRequestIDLen — более длинный ID из заголовка не принимается, вместо него генерируется свой.
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// RequestID берёт ID запроса из заголовка X-Request-ID или генерирует новый, возвращает его
+// в ответе и кладёт в контекст запроса: его можно получить через RequestIDFromContext.
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+
+			id := req.Header.Get(HeaderRequestID)
+			if id == "" || len(id) > maxRequestIDLen {
+				id = uuid.NewString()
+				req.Header.Set(HeaderRequestID, id)
+			}
+
+			c.Response().Header().Set(HeaderRequestID, id)
+			c.SetRequest(req.WithContext(WithRequestID(req.Context(), id)))
+
+			return next(c)
+		}
+	}
+}
+
+// WithRequestID возвращает контекст с ID запроса — например, чтобы передать его дальше
+// при обработке события, порождённого запросом.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext возвращает ID запроса, положенный middleware RequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Timeout ограничивает время обработки запроса дедлайном контекста: запросы в Postgres, Redis
+// и Kafka, выполняемые с контекстом запроса, прерываются по его истечении. Если обработчик
+// вернул ошибку после дедлайна, клиент получает 503 Service Unavailable.
+//
+// Таймаут маршрута берётся из routes по ключу "METHOD /path", иначе — d.
+// Он должен укладываться в WriteTimeout HTTP-сервера, иначе соединение оборвётся раньше.
+func Timeout(d time.Duration, routes map[string]time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			timeout := d
+			if rt, ok := routes[routeKey(c)]; ok {
+				timeout = rt
+			}
+			if timeout <= 0 {
+				return next(c)
+			}
+
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := next(c)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Response().Committed {
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "request timeout").SetInternal(err)
+			}
+			return err
+		}
+	}
+}
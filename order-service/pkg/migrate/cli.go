@@ -0,0 +1,99 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
+	"github.com/pressly/goose/v3"
+)
+
+// Usage — справка по подкоманде migrate.
+const Usage = `usage: migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down           roll back the latest migration
+  redo           roll back and re-apply the latest migration
+  status         print the state of every migration
+  create <name>  create a new SQL migration in the source directory`
+
+// Command — CLI миграций сервиса. Каждый сервис передаёт свои встроенные миграции
+// и способ подключения к БД и вызывает Run с аргументами после "migrate".
+type Command struct {
+	// Migrations — встроенные файлы миграций сервиса.
+	Migrations fs.FS
+	// Dir — каталог с исходниками миграций, в который create кладёт новый файл.
+	Dir string
+	// Connect подключается к БД сервиса; для create не вызывается.
+	Connect func(ctx context.Context) (*postgres.Postgres, error)
+	// Out — куда печатается status, по умолчанию os.Stdout.
+	Out io.Writer
+	// AfterUp, если задан, вызывается после успешного up — для схем со своими миграциями
+	// (например, outbox.Store.Migrate).
+	AfterUp func(ctx context.Context, pg *postgres.Postgres) error
+}
+
+func (c *Command) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(Usage)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return errors.New("usage: migrate create <name>")
+		}
+		if err := goose.Create(nil, c.Dir, args[1], "sql"); err != nil {
+			return fmt.Errorf("migrate - Command.Run - create: %w", err)
+		}
+		return nil
+	}
+
+	var run func(*Migrator, context.Context) error
+	switch args[0] {
+	case "up":
+		run = (*Migrator).Up
+	case "down":
+		run = (*Migrator).Down
+	case "redo":
+		run = (*Migrator).Redo
+	case "status":
+		run = func(m *Migrator, ctx context.Context) error {
+			out := c.Out
+			if out == nil {
+				out = os.Stdout
+			}
+			return m.Status(ctx, out)
+		}
+	default:
+		return fmt.Errorf("migrate: unknown command %q\n\n%s", args[0], Usage)
+	}
+
+	pg, err := c.Connect(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate - Command.Run - connect: %w", err)
+	}
+	defer pg.Close()
+
+	m, err := New(pg.Pool, c.Migrations)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := run(m, ctx); err != nil {
+		return err
+	}
+
+	if args[0] == "up" && c.AfterUp != nil {
+		if err := c.AfterUp(ctx, pg); err != nil {
+			return fmt.Errorf("migrate - Command.Run - after up: %w", err)
+		}
+	}
+
+	return nil
+}
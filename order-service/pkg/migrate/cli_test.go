@@ -0,0 +1,40 @@
+package migrate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand_Create(t *testing.T) {
+	dir := t.TempDir()
+	cmd := Command{
+		Dir: dir,
+		Connect: func(context.Context) (*postgres.Postgres, error) {
+			t.Fatal("create must not connect to the database")
+			return nil, nil
+		},
+	}
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"create", "add_index"}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*_add_index.sql"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	src, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(src), "-- +goose Up")
+}
+
+func TestCommand_Errors(t *testing.T) {
+	cmd := Command{}
+
+	require.Error(t, cmd.Run(context.Background(), nil))
+	require.ErrorContains(t, cmd.Run(context.Background(), []string{"sideways"}), `unknown command "sideways"`)
+	require.Error(t, cmd.Run(context.Background(), []string{"create"}))
+}
@@ -0,0 +1,154 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"text/tabwriter"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrSchemaBehind — в БД применены не все миграции сервиса.
+var ErrSchemaBehind = errors.New("database schema is behind migrations")
+
+// Migrator применяет встроенные SQL-миграции goose сервиса.
+type Migrator struct {
+	db       *sql.DB
+	provider *goose.Provider
+
+	// table — таблица версий goose.
+	table string
+}
+
+// Option -.
+type Option func(*Migrator)
+
+// TableName задаёт таблицу версий goose. Нужна, когда в одной БД живут независимые наборы
+// миграций (например, схема outbox.Store рядом с миграциями сервиса).
+func TableName(name string) Option {
+	return func(m *Migrator) {
+		if name != "" {
+			m.table = name
+		}
+	}
+}
+
+// New создаёт Migrator для миграций fsys (файлы *.sql в корне fsys).
+// Версии хранятся в стандартной таблице goose_db_version, если не задана TableName.
+func New(pool *pgxpool.Pool, fsys fs.FS, opts ...Option) (*Migrator, error) {
+	m := &Migrator{table: goose.DefaultTablename}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.db = stdlib.OpenDBFromPool(pool)
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, m.db, fsys,
+		goose.WithTableName(m.table),
+		goose.WithDisableGlobalRegistry(true),
+	)
+	if err != nil {
+		_ = m.db.Close()
+		return nil, fmt.Errorf("migrate - New - goose.NewProvider: %w", err)
+	}
+	m.provider = provider
+
+	return m, nil
+}
+
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+// Up применяет все неприменённые миграции.
+func (m *Migrator) Up(ctx context.Context) error {
+	results, err := m.provider.Up(ctx)
+	logResults(results)
+	if err != nil {
+		return fmt.Errorf("migrate - Up: %w", err)
+	}
+	return nil
+}
+
+// Down откатывает последнюю применённую миграцию.
+func (m *Migrator) Down(ctx context.Context) error {
+	result, err := m.provider.Down(ctx)
+	logResults([]*goose.MigrationResult{result})
+	if err != nil {
+		return fmt.Errorf("migrate - Down: %w", err)
+	}
+	return nil
+}
+
+// Redo откатывает и заново применяет последнюю миграцию.
+func (m *Migrator) Redo(ctx context.Context) error {
+	if err := m.Down(ctx); err != nil {
+		return err
+	}
+
+	result, err := m.provider.UpByOne(ctx)
+	logResults([]*goose.MigrationResult{result})
+	if err != nil {
+		return fmt.Errorf("migrate - Redo: %w", err)
+	}
+	return nil
+}
+
+// Status печатает состояние каждой миграции.
+func (m *Migrator) Status(ctx context.Context, w io.Writer) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate - Status: %w", err)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+	for _, s := range statuses {
+		appliedAt := "-"
+		if !s.AppliedAt.IsZero() {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", s.Source.Version, s.State, appliedAt, s.Source.Path)
+	}
+
+	return tw.Flush()
+}
+
+// Check возвращает ErrSchemaBehind, если в БД применены не все миграции.
+// Сервис вызывает его при старте, чтобы не работать со старой схемой.
+func (m *Migrator) Check(ctx context.Context) error {
+	pending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate - Check: %w", err)
+	}
+	if !pending {
+		return nil
+	}
+
+	current, target, err := m.provider.GetVersions(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate - Check: %w", err)
+	}
+	return fmt.Errorf("%w: current version %d, latest %d; run `migrate up`", ErrSchemaBehind, current, target)
+}
+
+func logResults(results []*goose.MigrationResult) {
+	for _, r := range results {
+		if r == nil || r.Source == nil {
+			continue
+		}
+		if r.Error != nil {
+			logrus.Errorf("Migrate: %s %s failed: %v", r.Direction, r.Source.Path, r.Error)
+			continue
+		}
+		logrus.Infof("Migrate: %s %s (%v)", r.Direction, r.Source.Path, r.Duration)
+	}
+}
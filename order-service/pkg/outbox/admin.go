@@ -0,0 +1,212 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Методы для админского API: просмотр outbox и ручное управление событиями.
+
+// ErrEventNotFound — записи с таким id в outbox нет.
+var ErrEventNotFound = errors.New("outbox event not found")
+
+// Filter — выборка записей для админских операций. Пустые поля выборку не ограничивают.
+type Filter struct {
+	IDs           []uuid.UUID
+	Status        string
+	AggregateType string
+	AggregateID   *uuid.UUID
+	From          *time.Time
+	To            *time.Time
+}
+
+// Record — запись outbox со служебными полями. EventType и Payload — в том виде,
+// в каком они хранятся в таблице.
+type Record struct {
+	ID            uuid.UUID       `db:"id"`
+	AggregateType string          `db:"aggregate_type"`
+	AggregateID   uuid.UUID       `db:"aggregate_id"`
+	EventType     string          `db:"event_type"`
+	Payload       json.RawMessage `db:"payload"`
+	StatusID      int             `db:"status_id"`
+	Status        string          `db:"status_name"`
+	CreatedAt     time.Time       `db:"created_at"`
+	ProcessedAt   *time.Time      `db:"processed_at"`
+	Attempts      int             `db:"attempts"`
+	LastError     *string         `db:"last_error"`
+	NextAttemptAt *time.Time      `db:"next_attempt_at"`
+}
+
+const recordColumns = `
+	o.id, o.aggregate_type, o.aggregate_id, o.event_type, o.payload,
+	o.status_id,
+	s.name AS status_name,
+	o.created_at, o.processed_at,
+	o.attempts, o.last_error, o.next_attempt_at`
+
+// List возвращает страницу записей выборки (новые первыми) и общее число записей в ней.
+func (s *Store) List(ctx context.Context, filter Filter, limit, offset int) ([]Record, int, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.List")
+
+	where := s.filterCond(filter)
+
+	countQuery, countArgs, _ := s.pg.Builder.
+		Select("count(*)").
+		From(ident(s.table) + " o").
+		Where(where).
+		ToSql()
+
+	var total int
+	if err := s.pg.GetTxManager(ctx).QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("outbox - Store.List - count: %w", err)
+	}
+
+	query, args, _ := s.pg.Builder.
+		Select(recordColumns).
+		From(ident(s.table) + " o").
+		Join(ident(s.status) + " s ON s.id = o.status_id").
+		Where(where).
+		OrderBy("o.created_at DESC").
+		Limit(uint64(limit)).
+		Offset(uint64(offset)).
+		ToSql()
+
+	rows, err := s.pg.GetTxManager(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("outbox - Store.List: %w", err)
+	}
+
+	records, err := pgx.CollectRows(rows, pgx.RowToStructByName[Record])
+	if err != nil {
+		return nil, 0, fmt.Errorf("outbox - Store.List - scan: %w", err)
+	}
+
+	return records, total, nil
+}
+
+// GetByID возвращает запись по id или ErrEventNotFound.
+func (s *Store) GetByID(ctx context.Context, id uuid.UUID) (Record, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.GetByID")
+
+	query, args, _ := s.pg.Builder.
+		Select(recordColumns).
+		From(ident(s.table)+" o").
+		Join(ident(s.status)+" s ON s.id = o.status_id").
+		Where("o.id = ?", id).
+		ToSql()
+
+	rows, err := s.pg.GetTxManager(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return Record{}, fmt.Errorf("outbox - Store.GetByID: %w", err)
+	}
+
+	record, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[Record])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Record{}, ErrEventNotFound
+		}
+		return Record{}, fmt.Errorf("outbox - Store.GetByID - scan: %w", err)
+	}
+
+	return record, nil
+}
+
+// Retry возвращает в pending failed- и dead-события выборки со сброшенным счётчиком попыток.
+func (s *Store) Retry(ctx context.Context, filter Filter) (int64, error) {
+	return s.resetToPending(ctx, "Retry", filter, StatusFailed, StatusDead)
+}
+
+// Republish заново ставит в очередь уже отправленные, пропущенные или упавшие события выборки.
+// Событие публикуется с прежним eventId, поэтому идемпотентные консьюмеры его отбросят.
+func (s *Store) Republish(ctx context.Context, filter Filter) (int64, error) {
+	return s.resetToPending(ctx, "Republish", filter, StatusProcessed, StatusSkipped, StatusFailed, StatusDead)
+}
+
+// Skip исключает из публикации неотправленные события выборки.
+func (s *Store) Skip(ctx context.Context, filter Filter) (int64, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.Skip")
+
+	query, args, _ := s.pg.Builder.
+		Update(ident(s.table)+" o").
+		Set("status_id", squirrel.Expr(s.sql("(SELECT id FROM {status} WHERE name = ?)"), StatusSkipped)).
+		Set("next_attempt_at", nil).
+		Set("locked_by", nil).
+		Set("locked_until", nil).
+		Where(s.filterCond(filter)).
+		Where(s.statusIn(StatusPending, StatusFailed, StatusDead)).
+		ToSql()
+
+	tag, err := s.pg.GetTxManager(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("outbox - Store.Skip: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (s *Store) resetToPending(ctx context.Context, op string, filter Filter, from ...string) (int64, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore."+op)
+
+	query, args, _ := s.pg.Builder.
+		Update(ident(s.table)+" o").
+		Set("status_id", squirrel.Expr(s.sql("(SELECT id FROM {status} WHERE name = ?)"), StatusPending)).
+		Set("attempts", 0).
+		Set("next_attempt_at", nil).
+		Set("processed_at", nil).
+		Set("locked_by", nil).
+		Set("locked_until", nil).
+		Where(s.filterCond(filter)).
+		Where(s.statusIn(from...)).
+		ToSql()
+
+	tag, err := s.pg.GetTxManager(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("outbox - Store.%s: %w", op, err)
+	}
+
+	// Будим воркеры: триггер таблицы срабатывает только на INSERT. Ошибка NOTIFY не отменяет
+	// сброса — воркер подберёт записи при очередном опросе.
+	if tag.RowsAffected() > 0 {
+		_, _ = s.pg.GetTxManager(ctx).Exec(ctx, "SELECT pg_notify($1, '')", s.Channel())
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// filterCond строит условие выборки по колонкам таблицы outbox с псевдонимом o.
+func (s *Store) filterCond(f Filter) squirrel.And {
+	cond := squirrel.And{}
+
+	if len(f.IDs) > 0 {
+		cond = append(cond, squirrel.Eq{"o.id": f.IDs})
+	}
+	if f.Status != "" {
+		cond = append(cond, squirrel.Expr(s.sql("o.status_id = (SELECT id FROM {status} WHERE name = ?)"), f.Status))
+	}
+	if f.AggregateType != "" {
+		cond = append(cond, squirrel.Eq{"o.aggregate_type": f.AggregateType})
+	}
+	if f.AggregateID != nil {
+		cond = append(cond, squirrel.Eq{"o.aggregate_id": *f.AggregateID})
+	}
+	if f.From != nil {
+		cond = append(cond, squirrel.GtOrEq{"o.created_at": *f.From})
+	}
+	if f.To != nil {
+		cond = append(cond, squirrel.Lt{"o.created_at": *f.To})
+	}
+
+	return cond
+}
+
+func (s *Store) statusIn(statuses ...string) squirrel.Sqlizer {
+	return squirrel.Expr(s.sql("o.status_id IN (SELECT id FROM {status} WHERE name = ANY(?))"), statuses)
+}
@@ -2,32 +2,63 @@ package outbox
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
 	"github.com/google/uuid"
 )
 
+// ErrLeaseLost — аренда события истекла или перешла к другому воркеру: отметка не применена,
+// событие обработает новый владелец аренды.
+var ErrLeaseLost = errors.New("outbox lease lost")
+
 // Repository описывает контракт хранилища для outbox‑записей.
 // Конкретная реализация обычно использует таблицу в PostgreSQL.
 //
 // Типичный жизненный цикл записи:
 //   1. Сервис внутри бизнес‑транзакции добавляет запись со статусом "pending".
-//   2. Worker периодически вызывает FetchPending и забирает партию таких записей.
+//   2. Worker периодически вызывает ClaimPending и захватывает партию таких записей в аренду.
 //   3. После успешной отправки в брокер вызывается MarkProcessed.
-//   4. При ошибке отправки вызывается MarkFailed, а затем периодически RequeueFailed.
+//   4. При ошибке отправки вызывается MarkFailed с временем следующей попытки,
+//      а RequeueFailed возвращает в pending записи, время попытки которых наступило.
+//   5. Когда попытки исчерпаны, вызывается MarkDead — запись больше не отправляется.
+//   6. Processed-записи старше заданного возраста переносит в архив Retention (см. Archiver).
 type Repository interface {
-	// FetchPending возвращает неотправленные события (pending) ограниченным батчем.
-	FetchPending(ctx context.Context, limit int) ([]Event, error)
+	// ClaimPending одной операцией захватывает до limit pending-событий, не захваченных
+	// другими воркерами (или с истёкшей арендой): записывает owner и время окончания аренды.
+	// События возвращаются в порядке создания.
+	ClaimPending(ctx context.Context, owner string, limit int, lease time.Duration) ([]Event, error)
 	// MarkProcessed помечает список событий как успешно обработанные.
-	MarkProcessed(ctx context.Context, ids []uuid.UUID) error
-	// MarkFailed помечает событие как неудавшееся, сохраняя текст ошибки.
-	MarkFailed(ctx context.Context, id uuid.UUID, errorText string) error
-	// RequeueFailed переводит часть "упавших" событий обратно в pending для повторной отправки.
+	//
+	// MarkProcessed, MarkFailed и MarkDead меняют только события, которые owner держит
+	// в действующей аренде. Если хотя бы одно событие не изменено, возвращается ErrLeaseLost.
+	MarkProcessed(ctx context.Context, owner string, ids []uuid.UUID) error
+	// MarkFailed помечает событие как неудавшееся: увеличивает счётчик попыток,
+	// сохраняет текст ошибки и время, не раньше которого событие можно отправить снова.
+	MarkFailed(ctx context.Context, owner string, id uuid.UUID, errorText string, nextAttemptAt time.Time) error
+	// MarkDead переводит событие в терминальный статус dead: попытки исчерпаны.
+	MarkDead(ctx context.Context, owner string, id uuid.UUID, errorText string) error
+	// RequeueFailed переводит failed-события, у которых наступило время следующей попытки,
+	// обратно в pending.
 	RequeueFailed(ctx context.Context, limit int) ([]Event, error)
+	// Stats возвращает число записей по статусам и время создания самой старой pending-записи.
+	Stats(ctx context.Context) (Stats, error)
 }
 
 // Publisher описывает транспорт для отправки событий наружу (Kafka, NATS и т.п.).
 // В этом проекте реализацией является KafkaPublisher.
 type Publisher interface {
-	// Publish отправляет произвольный payload в указанный topic с заданным типом события.
-	Publish(ctx context.Context, topic string, eventType string, payload any) error
+	// PublishEvent отправляет payload в указанный topic с заданным типом события.
+	// key — ключ сообщения (id агрегата): события одного агрегата попадают в одну партицию по порядку.
+	// eventID и occurredAt берутся из outbox-записи, чтобы повторная отправка была идемпотентной.
+	PublishEvent(ctx context.Context, topic, key string, eventID uuid.UUID, eventType string, occurredAt time.Time, payload any) error
+}
+
+// BatchPublisher — Publisher, который умеет отправить пачку событий одной записью в брокер
+// (KafkaPublisher). Если паблишер Worker реализует этот интерфейс, вся захваченная пачка
+// публикуется одним вызовом. Частичный отказ возвращается как *kafka.BatchError,
+// любая другая ошибка относится ко всей пачке.
+type BatchPublisher interface {
+	PublishBatch(ctx context.Context, batch []kafka.Publication) error
 }
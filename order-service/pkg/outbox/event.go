@@ -1,13 +1,35 @@
 package outbox
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Event — минимальное представление записи в outbox‑таблице.
 //   - ID — идентификатор записи в outbox (обычно UUID из БД);
+//   - AggregateType — тип агрегата (например, "order"), используется маршрутизацией (см. Router);
+//   - AggregateID — id агрегата, ключ сообщения в Kafka: события одного агрегата публикуются по порядку;
 //   - EventType — тип доменного события (например, "OrderCreated");
-//   - Payload — сериализованное тело события (JSON, protobuf и т.п.).
+//   - Payload — сериализованное тело события (JSON, protobuf и т.п.);
+//   - OccurredAt — время создания записи, оно же время возникновения события;
+//   - Attempts — сколько раз публикация уже завершилась ошибкой.
 type Event struct {
-	ID        uuid.UUID
-	EventType string
-	Payload   []byte
+	ID            uuid.UUID
+	AggregateType string
+	AggregateID   uuid.UUID
+	EventType     string
+	Payload       []byte
+	OccurredAt    time.Time
+	Attempts      int
+}
+
+// Stats — состояние outbox-таблицы для метрик.
+//   - OldestPending — created_at самой старой pending-записи; нулевое время, если таких нет.
+type Stats struct {
+	Pending       int64
+	Failed        int64
+	Processed     int64
+	Dead          int64
+	OldestPending time.Time
 }
@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/lifecycle"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultChannel — канал Postgres NOTIFY, в который триггер outbox сообщает о новых записях.
+const DefaultChannel = "outbox"
+
+const listenerReconnectDelay = time.Second
+
+// Listener слушает Postgres LISTEN/NOTIFY и будит Worker при появлении новых outbox-записей
+// (см. опцию WakeUp). Сигналы схлопываются: пока Worker не забрал предыдущий сигнал,
+// новые не накапливаются.
+type Listener struct {
+	pool    *pgxpool.Pool
+	channel string
+	c       chan struct{}
+
+	bg lifecycle.Background
+}
+
+// NewListener создаёт Listener для канала channel. Слушать начинает после вызова Run.
+func NewListener(pool *pgxpool.Pool, channel string) *Listener {
+	return &Listener{
+		pool:    pool,
+		channel: channel,
+		c:       make(chan struct{}, 1),
+	}
+}
+
+// C возвращает канал сигналов для опции WakeUp.
+func (l *Listener) C() <-chan struct{} {
+	return l.c
+}
+
+// Run держит выделенное соединение с LISTEN в отдельной горутине и немедленно возвращает управление.
+// При обрыве соединения переподключается и посылает сигнал, т.к. уведомления могли быть потеряны.
+func (l *Listener) Run(ctx context.Context) {
+	l.bg.Go(ctx, func(ctx context.Context, stop <-chan struct{}) {
+		ctx, cancel := lifecycle.WithStop(ctx, stop)
+		defer cancel()
+
+		for ctx.Err() == nil {
+			if err := l.listen(ctx); err != nil && ctx.Err() == nil {
+				logrus.Errorf("OutboxListener: %v, reconnecting", err)
+				l.notify()
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(listenerReconnectDelay):
+				}
+			}
+		}
+		logrus.Info("OutboxListener: shutting down")
+	})
+}
+
+// Shutdown закрывает LISTEN-соединение и дожидается остановки Listener.
+func (l *Listener) Shutdown(ctx context.Context) error {
+	return l.bg.Shutdown(ctx)
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	pooled, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// Соединение в состоянии LISTEN нельзя возвращать в пул — забираем его и закрываем сами.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		if _, err := conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		l.notify()
+	}
+}
+
+func (l *Listener) notify() {
+	select {
+	case l.c <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	eventsByStatus = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "outbox_events",
+			Help: "Number of outbox records by status",
+		},
+		[]string{"topic", "status"},
+	)
+
+	oldestPendingAge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "outbox_oldest_pending_age_seconds",
+			Help: "Age of the oldest pending outbox record, 0 if there are none",
+		},
+		[]string{"topic"},
+	)
+
+	publishedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_published_total",
+			Help: "Number of outbox events published to the broker",
+		},
+		[]string{"topic"},
+	)
+
+	deadTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_dead_total",
+			Help: "Number of outbox events moved to the dead status after exhausting retries",
+		},
+		[]string{"topic"},
+	)
+
+	unroutableTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_unroutable_total",
+			Help: "Number of outbox events with no matching route, moved to the dead status",
+		},
+		[]string{"topic"},
+	)
+
+	archivedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_archived_total",
+			Help: "Number of processed outbox records moved to the archive by the retention job",
+		},
+		[]string{"topic"},
+	)
+
+	leaseLostTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_lease_lost_total",
+			Help: "Number of outbox status updates skipped because the worker no longer held the lease",
+		},
+		[]string{"topic"},
+	)
+
+	publishFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_publish_failures_total",
+			Help: "Number of failed attempts to publish an outbox event",
+		},
+		[]string{"topic"},
+	)
+)
@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/migrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationData — имена, которые подставляются в шаблоны миграций.
+type migrationData struct {
+	Table   string
+	Status  string
+	Archive string
+	Notify  string
+}
+
+// Migrate создаёт или обновляет схему Store встроенными миграциями goose.
+// Версии хранятся в отдельной таблице <table>_goose_db_version, поэтому миграции
+// не пересекаются с собственными миграциями сервиса.
+func (s *Store) Migrate(ctx context.Context) error {
+	m, err := s.migrator()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(ctx); err != nil {
+		return fmt.Errorf("outbox - Store.Migrate: %w", err)
+	}
+
+	return nil
+}
+
+// Check возвращает migrate.ErrSchemaBehind, если к схеме Store применены не все встроенные миграции.
+// Сервис вызывает его при запуске вместе с проверкой собственных миграций.
+func (s *Store) Check(ctx context.Context) error {
+	m, err := s.migrator()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Check(ctx); err != nil {
+		return fmt.Errorf("outbox - Store.Check: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) migrator() (*migrate.Migrator, error) {
+	fsys, err := s.renderMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.New(s.pg.Pool, fsys, migrate.TableName(s.table+"_goose_db_version"))
+	if err != nil {
+		return nil, fmt.Errorf("outbox - Store.migrator: %w", err)
+	}
+
+	return m, nil
+}
+
+// renderMigrations подставляет имена таблиц Store во встроенные миграции.
+func (s *Store) renderMigrations() (fs.FS, error) {
+	data := migrationData{
+		Table:   s.table,
+		Status:  s.status,
+		Archive: s.archive,
+		Notify:  s.table + "_notify",
+	}
+
+	funcs := template.FuncMap{
+		"ident":   ident,
+		"literal": literal,
+	}
+
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("outbox - Store.Migrate - read migrations: %w", err)
+	}
+
+	fsys := migrationFS{}
+	for _, e := range entries {
+		src, err := migrationFiles.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("outbox - Store.Migrate - read %s: %w", e.Name(), err)
+		}
+
+		tmpl, err := template.New(e.Name()).Funcs(funcs).Parse(string(src))
+		if err != nil {
+			return nil, fmt.Errorf("outbox - Store.Migrate - parse %s: %w", e.Name(), err)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("outbox - Store.Migrate - render %s: %w", e.Name(), err)
+		}
+
+		fsys[e.Name()] = buf.Bytes()
+	}
+
+	return fsys, nil
+}
+
+// migrationFS — отрендеренные миграции в памяти, плоский каталог: имя файла → содержимое.
+// goose находит их через fs.Glob и читает через Open.
+type migrationFS map[string][]byte
+
+func (m migrationFS) Open(name string) (fs.File, error) {
+	data, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &migrationFile{Reader: bytes.NewReader(data), info: migrationInfo{name: name, size: int64(len(data))}}, nil
+}
+
+func (m migrationFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(m))
+	for file, data := range m {
+		entries = append(entries, fs.FileInfoToDirEntry(migrationInfo{name: file, size: int64(len(data))}))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+
+	return entries, nil
+}
+
+type migrationFile struct {
+	*bytes.Reader
+	info migrationInfo
+}
+
+func (f *migrationFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *migrationFile) Close() error               { return nil }
+
+type migrationInfo struct {
+	name string
+	size int64
+}
+
+func (i migrationInfo) Name() string       { return i.name }
+func (i migrationInfo) Size() int64        { return i.size }
+func (i migrationInfo) Mode() fs.FileMode  { return 0o444 }
+func (i migrationInfo) ModTime() time.Time { return time.Time{} }
+func (i migrationInfo) IsDir() bool        { return false }
+func (i migrationInfo) Sys() any           { return nil }
+
+func literal(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,81 @@
+-- +goose Up
+-- Схема outbox для Store. Файл — шаблон text/template: имена таблиц, индексов и канала
+-- подставляет Store.Migrate. Все операции идемпотентны, поэтому миграция принимает и таблицы,
+-- созданные раньше собственными миграциями сервисов.
+
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS {{ident .Status}} (
+    id SMALLSERIAL PRIMARY KEY,
+    name VARCHAR(32) NOT NULL UNIQUE
+);
+
+INSERT INTO {{ident .Status}} (name) VALUES
+('pending'), ('processed'), ('failed'), ('dead'), ('skipped')
+ON CONFLICT (name) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS {{ident .Table}} (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    event_type VARCHAR(128) NOT NULL,
+    payload JSONB NOT NULL,
+    status_id SMALLINT NOT NULL REFERENCES {{ident .Status}}(id) DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMPTZ NULL
+);
+
+ALTER TABLE {{ident .Table}}
+    ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_error TEXT NULL,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ NULL,
+    ADD COLUMN IF NOT EXISTS locked_by TEXT NULL,
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_status_id" .Table)}} ON {{ident .Table}} (status_id);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_created_at" .Table)}} ON {{ident .Table}} (created_at);
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_next_attempt_at" .Table)}} ON {{ident .Table}} (next_attempt_at)
+    WHERE next_attempt_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_processed_at" .Table)}} ON {{ident .Table}} (processed_at)
+    WHERE processed_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS {{ident .Archive}} (
+    id UUID PRIMARY KEY,
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    event_type VARCHAR(128) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    processed_at TIMESTAMPTZ NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS {{ident (printf "idx_%s_created_at" .Archive)}} ON {{ident .Archive}} (created_at);
+
+-- Новые записи будят outbox-воркеры через LISTEN, не дожидаясь опроса.
+CREATE OR REPLACE FUNCTION {{ident .Notify}}() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify({{literal .Table}}, NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS {{ident .Notify}} ON {{ident .Table}};
+CREATE TRIGGER {{ident .Notify}}
+    AFTER INSERT ON {{ident .Table}}
+    FOR EACH ROW EXECUTE FUNCTION {{ident .Notify}}();
+
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TRIGGER IF EXISTS {{ident .Notify}} ON {{ident .Table}};
+DROP FUNCTION IF EXISTS {{ident .Notify}}();
+DROP TABLE IF EXISTS {{ident .Archive}};
+DROP TABLE IF EXISTS {{ident .Table}};
+DROP TABLE IF EXISTS {{ident .Status}};
+
+-- +goose StatementEnd
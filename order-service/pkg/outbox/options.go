@@ -0,0 +1,82 @@
+package outbox
+
+import "time"
+
+// Option -.
+type Option func(*Worker)
+
+// StatsInterval задаёт, как часто Worker обновляет метрики состояния outbox-таблицы.
+func StatsInterval(d time.Duration) Option {
+	return func(w *Worker) {
+		if d > 0 {
+			w.statsInterval = d
+		}
+	}
+}
+
+// MaxAttempts задаёт число попыток публикации, после которого событие переводится в dead.
+func MaxAttempts(n int) Option {
+	return func(w *Worker) {
+		if n > 0 {
+			w.maxAttempts = n
+		}
+	}
+}
+
+// RetryBackoff задаёт экспоненциальную задержку между попытками:
+// initial после первой ошибки, затем вдвое больше, но не больше maxDelay.
+func RetryBackoff(initial, maxDelay time.Duration) Option {
+	return func(w *Worker) {
+		if initial > 0 {
+			w.retryBackoff = initial
+		}
+		if maxDelay > 0 {
+			w.retryMaxBackoff = maxDelay
+		}
+	}
+}
+
+// OnDead задаёт обработчик событий, переведённых в dead (алерт, уведомление и т.п.).
+func OnDead(h DeadHandler) Option {
+	return func(w *Worker) {
+		w.onDead = h
+	}
+}
+
+// WorkerID задаёт идентификатор реплики, под которым воркер захватывает события.
+// По умолчанию — "<hostname>-<pid>".
+func WorkerID(id string) Option {
+	return func(w *Worker) {
+		if id != "" {
+			w.workerID = id
+		}
+	}
+}
+
+// Lease задаёт время аренды захваченных событий. Если воркер не отметил событие за это время,
+// его сможет захватить другой воркер.
+func Lease(d time.Duration) Option {
+	return func(w *Worker) {
+		if d > 0 {
+			w.lease = d
+		}
+	}
+}
+
+// WakeUp задаёт канал сигналов о новых событиях (см. Listener).
+// По сигналу воркер публикует события сразу, не дожидаясь interval.
+func WakeUp(c <-chan struct{}) Option {
+	return func(w *Worker) {
+		w.wakeup = c
+	}
+}
+
+// Routes задаёт таблицу маршрутизации: каждое событие публикуется в топики подходящих маршрутов.
+// Без этой опции все события публикуются в topic, переданный в NewWorker.
+func Routes(r *Router) Option {
+	return func(w *Worker) {
+		if r != nil {
+			w.router = r
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/lifecycle"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRetentionInterval  = time.Hour
+	defaultRetentionBatchSize = 1000
+)
+
+// Archiver описывает хранилище, из которого Retention переносит старые записи.
+type Archiver interface {
+	// ArchiveProcessed переносит в архив до limit processed-записей, обработанных раньше before,
+	// и удаляет их из outbox. Возвращает число удалённых из outbox записей.
+	ArchiveProcessed(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Retention периодически переносит processed-записи старше maxAge из outbox в архив,
+// чтобы таблица outbox не росла бесконечно. Переносит пачками по batchSize,
+// пока не останется подходящих записей.
+type Retention struct {
+	archiver Archiver
+	// maxAge — сколько processed-записи хранятся в outbox после публикации.
+	maxAge time.Duration
+	// interval — как часто запускается очистка.
+	interval time.Duration
+	// batchSize — сколько записей переносится одной операцией.
+	batchSize int
+	// topic — метка метрики outbox_archived_total.
+	topic string
+
+	bg lifecycle.Background
+}
+
+// RetentionOption -.
+type RetentionOption func(*Retention)
+
+// RetentionInterval задаёт период запуска очистки. По умолчанию — раз в час.
+func RetentionInterval(d time.Duration) RetentionOption {
+	return func(r *Retention) {
+		if d > 0 {
+			r.interval = d
+		}
+	}
+}
+
+// RetentionBatchSize задаёт число записей, переносимых одной операцией.
+func RetentionBatchSize(n int) RetentionOption {
+	return func(r *Retention) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+// RetentionTopic задаёт метку topic для метрики outbox_archived_total — обычно топик воркера.
+func RetentionTopic(topic string) RetentionOption {
+	return func(r *Retention) {
+		r.topic = topic
+	}
+}
+
+// NewRetention создаёт задачу очистки outbox от processed-записей старше maxAge.
+func NewRetention(archiver Archiver, maxAge time.Duration, opts ...RetentionOption) *Retention {
+	r := &Retention{
+		archiver:  archiver,
+		maxAge:    maxAge,
+		interval:  defaultRetentionInterval,
+		batchSize: defaultRetentionBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Run запускает очистку в отдельной горутине: сразу и затем каждые interval.
+// Останавливается по ctx.Done().
+func (r *Retention) Run(ctx context.Context) {
+	r.bg.Go(ctx, func(ctx context.Context, stop <-chan struct{}) {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				logrus.Errorf("OutboxRetention: %v", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				logrus.Info("OutboxRetention: shutting down")
+				return
+			case <-stop:
+				logrus.Info("OutboxRetention: shutting down")
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Shutdown прекращает очистку после текущей пачки и дожидается её завершения.
+func (r *Retention) Shutdown(ctx context.Context) error {
+	return r.bg.Shutdown(ctx)
+}
+
+// RunOnce переносит в архив все processed-записи старше maxAge и возвращает их число.
+// При ошибке возвращает число записей, перенесённых до неё.
+func (r *Retention) RunOnce(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-r.maxAge)
+
+	var total int64
+	for ctx.Err() == nil {
+		n, err := r.archiver.ArchiveProcessed(ctx, before, r.batchSize)
+		total += n
+		archivedTotal.WithLabelValues(r.topic).Add(float64(n))
+
+		if err != nil {
+			return total, err
+		}
+		if n < int64(r.batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		logrus.Infof("OutboxRetention: archived %d processed events older than %s", total, before.Format(time.RFC3339))
+	}
+
+	return total, nil
+}
@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeArchiver struct {
+	left   int64
+	calls  int
+	before time.Time
+	err    error
+}
+
+func (a *fakeArchiver) ArchiveProcessed(_ context.Context, before time.Time, limit int) (int64, error) {
+	a.calls++
+	a.before = before
+	if a.err != nil {
+		return 0, a.err
+	}
+
+	n := min(a.left, int64(limit))
+	a.left -= n
+	return n, nil
+}
+
+func TestRetentionRunOnce(t *testing.T) {
+	t.Run("archives in batches until a partial batch", func(t *testing.T) {
+		a := &fakeArchiver{left: 25}
+		r := NewRetention(a, time.Hour, RetentionBatchSize(10))
+
+		n, err := r.RunOnce(context.Background())
+		require.NoError(t, err)
+		require.EqualValues(t, 25, n)
+		require.Equal(t, 3, a.calls)
+		require.WithinDuration(t, time.Now().Add(-time.Hour), a.before, time.Minute)
+	})
+
+	t.Run("returns archiver error", func(t *testing.T) {
+		a := &fakeArchiver{err: errors.New("db down")}
+		r := NewRetention(a, time.Hour)
+
+		_, err := r.RunOnce(context.Background())
+		require.Error(t, err)
+	})
+}
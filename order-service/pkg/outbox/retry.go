@@ -0,0 +1,30 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	defaultMaxAttempts     = 10
+	defaultRetryBackoff    = time.Second
+	defaultRetryMaxBackoff = 10 * time.Minute
+)
+
+// DeadHandler вызывается, когда событие исчерпало попытки и переведено в статус dead.
+// err — ошибка последней попытки публикации.
+type DeadHandler func(ctx context.Context, e Event, err error)
+
+// retryDelay возвращает задержку перед попыткой номер attempt+1:
+// retryBackoff * 2^(attempt-1), но не больше retryMaxBackoff.
+func (w *Worker) retryDelay(attempt int) time.Duration {
+	delay := w.retryBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= w.retryMaxBackoff {
+			return w.retryMaxBackoff
+		}
+	}
+
+	return min(delay, w.retryMaxBackoff)
+}
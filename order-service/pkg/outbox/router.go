@@ -0,0 +1,120 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+// ErrUnroutable — ни один маршрут таблицы маршрутизации не подходит событию.
+var ErrUnroutable = errors.New("no route for outbox event")
+
+// Route — строка таблицы маршрутизации: события, подходящие под шаблоны AggregateType и EventType,
+// публикуются в Topic. Шаблоны — в синтаксисе path.Match ("order", "order.*", "*");
+// пустой шаблон подходит под любое значение.
+type Route struct {
+	AggregateType string
+	EventType     string
+	Topic         string
+	// Transform — имя преобразователя из набора, переданного в NewRouter. Пустое — событие
+	// публикуется как есть.
+	Transform string
+}
+
+// Transformer преобразует событие перед публикацией в топик маршрута: может поменять
+// тип события и payload (например, собрать снимок состояния вместо доменного события).
+// ID и OccurredAt менять не следует — по ним консьюмеры дедуплицируют повторы, AggregateID —
+// по нему события агрегата упорядочиваются в Kafka.
+type Transformer func(e Event) (Event, error)
+
+// Target — событие, готовое к публикации в конкретный топик.
+type Target struct {
+	Topic string
+	Event Event
+}
+
+// Router сопоставляет outbox-события топикам по таблице маршрутизации.
+// Событие публикуется во все подходящие маршруты в порядке таблицы.
+type Router struct {
+	routes []route
+}
+
+type route struct {
+	Route
+	transform Transformer
+}
+
+// NewRouter проверяет таблицу маршрутизации: у каждого маршрута должен быть топик,
+// корректные шаблоны и известный преобразователь.
+func NewRouter(routes []Route, transformers map[string]Transformer) (*Router, error) {
+	if len(routes) == 0 {
+		return nil, errors.New("outbox - NewRouter: routing table is empty")
+	}
+
+	r := &Router{routes: make([]route, 0, len(routes))}
+
+	for i, rt := range routes {
+		if rt.Topic == "" {
+			return nil, fmt.Errorf("outbox - NewRouter - route %d: topic is required", i)
+		}
+		for _, pattern := range []string{rt.AggregateType, rt.EventType} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("outbox - NewRouter - route %d: pattern %q: %w", i, pattern, err)
+			}
+		}
+
+		var transform Transformer
+		if rt.Transform != "" {
+			var ok bool
+			if transform, ok = transformers[rt.Transform]; !ok {
+				return nil, fmt.Errorf("outbox - NewRouter - route %d: unknown transform %q", i, rt.Transform)
+			}
+		}
+
+		r.routes = append(r.routes, route{Route: rt, transform: transform})
+	}
+
+	return r, nil
+}
+
+// SingleTopic — маршрутизация всех событий в один топик (поведение Worker без опции Routes).
+func SingleTopic(topic string) *Router {
+	return &Router{routes: []route{{Route: Route{Topic: topic}}}}
+}
+
+// Resolve возвращает публикации события по всем подходящим маршрутам.
+// Если ни один маршрут не подошёл, возвращает ErrUnroutable; ошибка преобразователя
+// возвращается обёрнутой как есть.
+func (r *Router) Resolve(e Event) ([]Target, error) {
+	var targets []Target
+
+	for _, rt := range r.routes {
+		if !match(rt.AggregateType, e.AggregateType) || !match(rt.EventType, e.EventType) {
+			continue
+		}
+
+		out := e
+		if rt.transform != nil {
+			var err error
+			if out, err = rt.transform(e); err != nil {
+				return nil, fmt.Errorf("outbox - Router.Resolve - transform %q: %w", rt.Transform, err)
+			}
+		}
+
+		targets = append(targets, Target{Topic: rt.Topic, Event: out})
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: aggregate=%q type=%q", ErrUnroutable, e.AggregateType, e.EventType)
+	}
+
+	return targets, nil
+}
+
+func match(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
@@ -0,0 +1,135 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Resolve(t *testing.T) {
+	snapshot := func(e Event) (Event, error) {
+		e.EventType = "order.state"
+		e.Payload = []byte(`{}`)
+		return e, nil
+	}
+
+	r, err := NewRouter([]Route{
+		{AggregateType: "order", EventType: "order.*", Topic: "order.events"},
+		{AggregateType: "order", EventType: "order.paid", Topic: "order.state", Transform: "snapshot"},
+		{EventType: "payment.*", Topic: "payment.events"},
+	}, map[string]Transformer{"snapshot": snapshot})
+	require.NoError(t, err)
+
+	t.Run("single route", func(t *testing.T) {
+		targets, err := r.Resolve(Event{AggregateType: "order", EventType: "order.created"})
+		require.NoError(t, err)
+		require.Len(t, targets, 1)
+		require.Equal(t, "order.events", targets[0].Topic)
+	})
+
+	t.Run("fan-out with transform", func(t *testing.T) {
+		e := Event{ID: uuid.New(), AggregateType: "order", EventType: "order.paid", Payload: []byte(`{"orderId":"x"}`)}
+
+		targets, err := r.Resolve(e)
+		require.NoError(t, err)
+		require.Len(t, targets, 2)
+		require.Equal(t, e, targets[0].Event)
+		require.Equal(t, "order.state", targets[1].Topic)
+		require.Equal(t, "order.state", targets[1].Event.EventType)
+		require.Equal(t, e.ID, targets[1].Event.ID)
+	})
+
+	t.Run("empty pattern matches any aggregate", func(t *testing.T) {
+		targets, err := r.Resolve(Event{AggregateType: "refund", EventType: "payment.failed"})
+		require.NoError(t, err)
+		require.Equal(t, "payment.events", targets[0].Topic)
+	})
+
+	t.Run("unroutable", func(t *testing.T) {
+		_, err := r.Resolve(Event{AggregateType: "kitchen", EventType: "kitchen.ready"})
+		require.ErrorIs(t, err, ErrUnroutable)
+	})
+}
+
+func TestNewRouter_Validation(t *testing.T) {
+	_, err := NewRouter(nil, nil)
+	require.Error(t, err)
+
+	_, err = NewRouter([]Route{{EventType: "order.*"}}, nil)
+	require.Error(t, err, "topic is required")
+
+	_, err = NewRouter([]Route{{EventType: "order.[", Topic: "t"}}, nil)
+	require.Error(t, err, "bad pattern")
+
+	_, err = NewRouter([]Route{{Topic: "t", Transform: "missing"}}, nil)
+	require.Error(t, err, "unknown transform")
+}
+
+type recordingPublisher struct {
+	topics []string
+	keys   []string
+}
+
+func (p *recordingPublisher) PublishEvent(_ context.Context, topic, key string, _ uuid.UUID, _ string, _ time.Time, _ any) error {
+	p.topics = append(p.topics, topic)
+	p.keys = append(p.keys, key)
+	return nil
+}
+
+func TestWorker_RoutesEvents(t *testing.T) {
+	routed := Event{ID: uuid.New(), AggregateType: "order", AggregateID: uuid.New(), EventType: "order.created"}
+	unroutable := Event{ID: uuid.New(), AggregateType: "kitchen", EventType: "kitchen.ready"}
+
+	repo := &fakeRepo{pending: []Event{routed, unroutable}, failed: make(map[uuid.UUID]time.Time)}
+	pub := &recordingPublisher{}
+
+	router, err := NewRouter([]Route{
+		{AggregateType: "order", Topic: "order.events"},
+		{EventType: "order.created", Topic: "notifications"},
+	}, nil)
+	require.NoError(t, err)
+
+	w := NewWorker(repo, pub, "order.events", 10, 10, time.Second, time.Second, Routes(router))
+	w.processBatch(context.Background())
+
+	require.Equal(t, []string{"order.events", "notifications"}, pub.topics)
+	// Ключ сообщения — id агрегата, а не тип события: события заказа упорядочены в партиции.
+	require.Equal(t, []string{routed.AggregateID.String(), routed.AggregateID.String()}, pub.keys)
+	require.Equal(t, []uuid.UUID{routed.ID}, repo.processedIDs)
+	require.Equal(t, []uuid.UUID{unroutable.ID}, repo.dead)
+}
+
+func TestWorker_TransformErrorIsRetried(t *testing.T) {
+	transformFails := Event{ID: uuid.New(), AggregateType: "order", EventType: "order.paid"}
+	unroutable := Event{ID: uuid.New(), AggregateType: "kitchen", EventType: "kitchen.ready"}
+
+	repo := &fakeRepo{pending: []Event{transformFails, unroutable}, failed: make(map[uuid.UUID]time.Time)}
+
+	router, err := NewRouter([]Route{
+		{AggregateType: "order", Topic: "order.state", Transform: "snapshot"},
+	}, map[string]Transformer{
+		"snapshot": func(Event) (Event, error) { return Event{}, errors.New("order not loaded") },
+	})
+	require.NoError(t, err)
+
+	w := NewWorker(repo, &recordingPublisher{}, "order.events", 10, 10, time.Second, time.Second,
+		Routes(router), MaxAttempts(3))
+	w.processBatch(context.Background())
+
+	// Ошибка преобразователя — обычная неудачная попытка с backoff, без маршрута — сразу dead.
+	require.Contains(t, repo.failed, transformFails.ID)
+	require.Equal(t, []uuid.UUID{unroutable.ID}, repo.dead)
+	require.Empty(t, repo.processedIDs)
+
+	// Когда попытки исчерпаны, событие с ошибкой преобразователя тоже уходит в dead.
+	exhausted := transformFails
+	exhausted.Attempts = 2
+	repo.pending = []Event{exhausted}
+	w.processBatch(context.Background())
+
+	require.Equal(t, []uuid.UUID{unroutable.ID, exhausted.ID}, repo.dead)
+}
@@ -0,0 +1,435 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Имена статусов в таблице <table>_status.
+const (
+	StatusPending   = "pending"
+	StatusProcessed = "processed"
+	StatusFailed    = "failed"
+	StatusDead      = "dead"
+	StatusSkipped   = "skipped"
+)
+
+const defaultStoreTable = DefaultChannel
+
+// Message — событие, которое сервис кладёт в outbox внутри бизнес-транзакции.
+// EventType хранится как есть; если в нём нет точки, при чтении тип дополняется
+// префиксом агрегата ("created" → "order.created").
+type Message struct {
+	AggregateType string
+	AggregateID   uuid.UUID
+	EventType     string
+	// Payload сериализуется в JSONB.
+	Payload any
+}
+
+// Store — готовая реализация Repository и Archiver поверх PostgreSQL.
+// Таблицы: <table> с событиями, <table>_status со статусами и <table>_archive с архивом;
+// канал NOTIFY совпадает с именем таблицы. Схему создаёт Migrate.
+//
+// Все методы работают в транзакции из контекста (см. postgres.WithinTransaction),
+// поэтому Create, вызванный внутри бизнес-транзакции, фиксируется вместе с ней.
+type Store struct {
+	pg *postgres.Postgres
+
+	table   string
+	status  string
+	archive string
+}
+
+// StoreOption -.
+type StoreOption func(*Store)
+
+// StoreTable задаёт имя таблицы outbox. По умолчанию — "outbox".
+func StoreTable(name string) StoreOption {
+	return func(s *Store) {
+		if name != "" {
+			s.table = name
+		}
+	}
+}
+
+// NewStore создаёт Store. Сервису достаточно передать его в NewWorker и NewRetention
+// и вызывать Create из своих транзакций.
+func NewStore(pg *postgres.Postgres, opts ...StoreOption) *Store {
+	s := &Store{
+		pg:    pg,
+		table: defaultStoreTable,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.status = s.table + "_status"
+	s.archive = s.table + "_archive"
+
+	return s
+}
+
+// Table возвращает имя таблицы outbox.
+func (s *Store) Table() string {
+	return s.table
+}
+
+// Channel возвращает канал NOTIFY, в который триггер таблицы сообщает о новых записях, — для NewListener.
+func (s *Store) Channel() string {
+	return s.table
+}
+
+// Create добавляет pending-событие и возвращает его id.
+func (s *Store) Create(ctx context.Context, m Message) (uuid.UUID, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.Create")
+
+	query, args, _ := s.pg.Builder.
+		Insert(ident(s.table)).
+		Columns("aggregate_type", "aggregate_id", "event_type", "payload").
+		Values(m.AggregateType, m.AggregateID, m.EventType, m.Payload).
+		Suffix("RETURNING id").
+		ToSql()
+
+	var id uuid.UUID
+	if err := s.pg.GetTxManager(ctx).QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		return uuid.Nil, fmt.Errorf("outbox - Store.Create: %w", err)
+	}
+
+	return id, nil
+}
+
+// ClaimPending захватывает pending-события одним UPDATE: свободные записи и записи с истёкшей
+// арендой получают locked_by = owner и locked_until = NOW() + lease.
+func (s *Store) ClaimPending(ctx context.Context, owner string, limit int, lease time.Duration) ([]Event, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.ClaimPending")
+
+	query := s.sql(`
+		WITH claimed AS (
+			UPDATE {table}
+			SET locked_by = $1, locked_until = NOW() + $2::interval
+			WHERE id IN (
+				SELECT o.id
+				FROM {table} o
+				JOIN {status} s ON s.id = o.status_id
+				WHERE s.name = $3
+					AND (o.locked_until IS NULL OR o.locked_until < NOW())
+				ORDER BY o.created_at
+				LIMIT $4
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT id, aggregate_type, aggregate_id, event_type, payload, created_at, attempts
+		FROM claimed
+		ORDER BY created_at;
+	`)
+
+	events, err := s.queryEvents(ctx, query, owner, lease, StatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("outbox - Store.ClaimPending: %w", err)
+	}
+
+	return events, nil
+}
+
+// MarkProcessed помечает события processed, если owner всё ещё держит их аренду.
+func (s *Store) MarkProcessed(ctx context.Context, owner string, ids []uuid.UUID) error {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.MarkProcessed")
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := s.sql(`
+		UPDATE {table}
+		SET status_id = (SELECT id FROM {status} WHERE name = $1),
+			processed_at = NOW(),
+			locked_by = NULL,
+			locked_until = NULL
+		WHERE id = ANY($2)
+			AND locked_by = $3
+			AND locked_until > NOW();
+	`)
+
+	tag, err := s.pg.GetTxManager(ctx).Exec(ctx, query, StatusProcessed, ids, owner)
+	if err != nil {
+		return fmt.Errorf("outbox - Store.MarkProcessed: %w", err)
+	}
+	if n := tag.RowsAffected(); n < int64(len(ids)) {
+		return fmt.Errorf("outbox - Store.MarkProcessed: %d of %d events: %w", int64(len(ids))-n, len(ids), ErrLeaseLost)
+	}
+
+	return nil
+}
+
+// MarkFailed планирует повторную попытку, если owner всё ещё держит аренду события.
+func (s *Store) MarkFailed(ctx context.Context, owner string, id uuid.UUID, errorText string, nextAttemptAt time.Time) error {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.MarkFailed")
+
+	query := s.sql(`
+		UPDATE {table}
+		SET status_id = (SELECT id FROM {status} WHERE name = $1),
+			attempts = attempts + 1,
+			last_error = $2,
+			next_attempt_at = $3,
+			locked_by = NULL,
+			locked_until = NULL
+		WHERE id = $4
+			AND locked_by = $5
+			AND locked_until > NOW();
+	`)
+
+	tag, err := s.pg.GetTxManager(ctx).Exec(ctx, query, StatusFailed, errorText, nextAttemptAt, id, owner)
+	if err != nil {
+		return fmt.Errorf("outbox - Store.MarkFailed: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("outbox - Store.MarkFailed: %w", ErrLeaseLost)
+	}
+
+	return nil
+}
+
+// MarkDead переводит событие в dead, если owner всё ещё держит его аренду.
+func (s *Store) MarkDead(ctx context.Context, owner string, id uuid.UUID, errorText string) error {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.MarkDead")
+
+	query := s.sql(`
+		UPDATE {table}
+		SET status_id = (SELECT id FROM {status} WHERE name = $1),
+			attempts = attempts + 1,
+			last_error = $2,
+			next_attempt_at = NULL,
+			locked_by = NULL,
+			locked_until = NULL
+		WHERE id = $3
+			AND locked_by = $4
+			AND locked_until > NOW();
+	`)
+
+	tag, err := s.pg.GetTxManager(ctx).Exec(ctx, query, StatusDead, errorText, id, owner)
+	if err != nil {
+		return fmt.Errorf("outbox - Store.MarkDead: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("outbox - Store.MarkDead: %w", ErrLeaseLost)
+	}
+
+	return nil
+}
+
+// RequeueFailed одним запросом переводит в pending failed-события, у которых наступил next_attempt_at.
+func (s *Store) RequeueFailed(ctx context.Context, limit int) ([]Event, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.RequeueFailed")
+
+	query := s.sql(`
+		WITH requeued AS (
+			UPDATE {table}
+			SET status_id = (SELECT id FROM {status} WHERE name = $1)
+			WHERE id IN (
+				SELECT o.id
+				FROM {table} o
+				JOIN {status} s ON s.id = o.status_id
+				WHERE s.name = $2
+					AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= NOW())
+				ORDER BY o.next_attempt_at NULLS FIRST, o.created_at
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT id, aggregate_type, aggregate_id, event_type, payload, created_at, attempts
+		FROM requeued;
+	`)
+
+	events, err := s.queryEvents(ctx, query, StatusPending, StatusFailed, limit)
+	if err != nil {
+		return nil, fmt.Errorf("outbox - Store.RequeueFailed: %w", err)
+	}
+
+	return events, nil
+}
+
+func (s *Store) Stats(ctx context.Context) (Stats, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.Stats")
+
+	query := s.sql(`
+		SELECT
+			s.name,
+			count(o.id),
+			min(o.created_at)
+		FROM {status} s
+		LEFT JOIN {table} o ON o.status_id = s.id
+		GROUP BY s.name;
+	`)
+
+	rows, err := s.pg.GetTxManager(ctx).Query(ctx, query)
+	if err != nil {
+		return Stats{}, fmt.Errorf("outbox - Store.Stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats Stats
+	for rows.Next() {
+		var (
+			name   string
+			count  int64
+			oldest *time.Time
+		)
+		if err := rows.Scan(&name, &count, &oldest); err != nil {
+			return Stats{}, fmt.Errorf("outbox - Store.Stats - scan: %w", err)
+		}
+
+		switch name {
+		case StatusPending:
+			stats.Pending = count
+			if oldest != nil {
+				stats.OldestPending = *oldest
+			}
+		case StatusFailed:
+			stats.Failed = count
+		case StatusProcessed:
+			stats.Processed = count
+		case StatusDead:
+			stats.Dead = count
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return Stats{}, fmt.Errorf("outbox - Store.Stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// ArchiveProcessed переносит processed-записи, обработанные раньше before, в архив
+// одной операцией. Записи, захваченные другой транзакцией, пропускаются до следующего запуска.
+func (s *Store) ArchiveProcessed(ctx context.Context, before time.Time, limit int) (int64, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.ArchiveProcessed")
+
+	query := s.sql(`
+		WITH moved AS (
+			DELETE FROM {table}
+			WHERE id IN (
+				SELECT id
+				FROM {table}
+				WHERE status_id = (SELECT id FROM {status} WHERE name = $1)
+				  AND processed_at < $2
+				ORDER BY processed_at
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, aggregate_type, aggregate_id, event_type, payload, created_at, processed_at, attempts
+		), archived AS (
+			INSERT INTO {archive} (id, aggregate_type, aggregate_id, event_type, payload, created_at, processed_at, attempts)
+			SELECT id, aggregate_type, aggregate_id, event_type, payload, created_at, processed_at, attempts
+			FROM moved
+			ON CONFLICT (id) DO NOTHING
+		)
+		SELECT count(*) FROM moved;
+	`)
+
+	var n int64
+	if err := s.pg.GetTxManager(ctx).QueryRow(ctx, query, StatusProcessed, before, limit).Scan(&n); err != nil {
+		return 0, fmt.Errorf("outbox - Store.ArchiveProcessed: %w", err)
+	}
+
+	return n, nil
+}
+
+// QueryProcessed выбирает опубликованные события, созданные в интервале [from, to), из таблицы
+// и её архива в порядке created_at — для повторного прогона (см. replay.OutboxSource).
+// Колонки: id, aggregate_type, aggregate_id, event_type, payload, created_at; event_type — как
+// в таблице (см. FullEventType). Строки читаются потоком вне транзакции, закрывает их вызывающий.
+func (s *Store) QueryProcessed(ctx context.Context, from, to time.Time) (pgx.Rows, error) {
+	ctx = postgres.WithOperation(ctx, "OutboxStore.QueryProcessed")
+
+	query := s.sql(`
+		SELECT id, aggregate_type, aggregate_id, event_type, payload, created_at
+		FROM (
+			SELECT o.id, o.aggregate_type, o.aggregate_id, o.event_type, o.payload, o.created_at
+			FROM {table} o
+			WHERE o.status_id = (SELECT id FROM {status} WHERE name = $3)
+			  AND o.created_at >= $1 AND o.created_at < $2
+			UNION ALL
+			SELECT a.id, a.aggregate_type, a.aggregate_id, a.event_type, a.payload, a.created_at
+			FROM {archive} a
+			WHERE a.created_at >= $1 AND a.created_at < $2
+		) events
+		ORDER BY created_at, id
+	`)
+
+	rows, err := s.pg.Pool.Query(ctx, query, from, to, StatusProcessed)
+	if err != nil {
+		return nil, fmt.Errorf("outbox - Store.QueryProcessed: %w", err)
+	}
+
+	return rows, nil
+}
+
+type storeRow struct {
+	ID            uuid.UUID `db:"id"`
+	AggregateType string    `db:"aggregate_type"`
+	AggregateID   uuid.UUID `db:"aggregate_id"`
+	EventType     string    `db:"event_type"`
+	Payload       []byte    `db:"payload"`
+	CreatedAt     time.Time `db:"created_at"`
+	Attempts      int       `db:"attempts"`
+}
+
+func (s *Store) queryEvents(ctx context.Context, query string, args ...any) ([]Event, error) {
+	rows, err := s.pg.GetTxManager(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	dtoRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[storeRow])
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, len(dtoRows))
+	for i, r := range dtoRows {
+		events[i] = Event{
+			ID:            r.ID,
+			AggregateType: r.AggregateType,
+			AggregateID:   r.AggregateID,
+			EventType:     FullEventType(r.AggregateType, r.EventType),
+			Payload:       r.Payload,
+			OccurredAt:    r.CreatedAt,
+			Attempts:      r.Attempts,
+		}
+	}
+
+	return events, nil
+}
+
+// sql подставляет в запрос экранированные имена таблиц Store.
+func (s *Store) sql(query string) string {
+	return strings.NewReplacer(
+		"{table}", ident(s.table),
+		"{status}", ident(s.status),
+		"{archive}", ident(s.archive),
+	).Replace(query)
+}
+
+// FullEventType возвращает тип события с префиксом агрегата. Тип, уже содержащий точку
+// ("payment.success"), возвращается без изменений.
+func FullEventType(aggregateType, eventType string) string {
+	if strings.Contains(eventType, ".") {
+		return eventType
+	}
+	return aggregateType + "." + eventType
+}
+
+func ident(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_renderMigrations(t *testing.T) {
+	s := NewStore(nil, StoreTable("billing_outbox"))
+
+	fsys, err := s.renderMigrations()
+	require.NoError(t, err)
+
+	src, err := fs.ReadFile(fsys, "00001_outbox.sql")
+	require.NoError(t, err)
+	sql := string(src)
+
+	require.NotContains(t, sql, "{{")
+	require.Contains(t, sql, `CREATE TABLE IF NOT EXISTS "billing_outbox" (`)
+	require.Contains(t, sql, `REFERENCES "billing_outbox_status"(id)`)
+	require.Contains(t, sql, `CREATE TABLE IF NOT EXISTS "billing_outbox_archive" (`)
+	require.Contains(t, sql, `pg_notify('billing_outbox', NEW.id::text)`)
+	require.Contains(t, sql, `CREATE TRIGGER "billing_outbox_notify"`)
+	require.Equal(t, 1, strings.Count(sql, "-- +goose Up"))
+
+	files, err := fs.Glob(fsys, "*.sql")
+	require.NoError(t, err)
+	require.Equal(t, []string{"00001_outbox.sql"}, files)
+	require.Equal(t, "billing_outbox", s.Channel())
+}
+
+func TestStore_sql(t *testing.T) {
+	s := NewStore(nil)
+
+	require.Equal(t, `SELECT 1 FROM "outbox" o JOIN "outbox_status" s`, s.sql("SELECT 1 FROM {table} o JOIN {status} s"))
+	require.Equal(t, DefaultChannel, s.Channel())
+}
+
+func TestStore_filterCond(t *testing.T) {
+	s := NewStore(nil, StoreTable("billing_outbox"))
+	aggregateID := uuid.New()
+
+	sql, args, err := squirrel.And{
+		s.filterCond(Filter{Status: StatusDead, AggregateType: "payment", AggregateID: &aggregateID}),
+		s.statusIn(StatusFailed, StatusDead),
+	}.ToSql()
+	require.NoError(t, err)
+
+	require.Contains(t, sql, `o.status_id = (SELECT id FROM "billing_outbox_status" WHERE name = ?)`)
+	require.Contains(t, sql, `o.status_id IN (SELECT id FROM "billing_outbox_status" WHERE name = ANY(?))`)
+	require.NotContains(t, sql, " outbox_status")
+	require.Equal(t, []any{StatusDead, "payment", aggregateID.String(), []string{StatusFailed, StatusDead}}, args)
+}
+
+func TestFullEventType(t *testing.T) {
+	require.Equal(t, "order.created", FullEventType("order", "created"))
+	require.Equal(t, "payment.success", FullEventType("payment", "payment.success"))
+}
@@ -2,23 +2,40 @@ package outbox
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"time"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/lifecycle"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	defaultStatsInterval = 15 * time.Second
+	defaultLease         = 30 * time.Second
+)
+
 // Worker реализует outbox-паттерн:
-// периодически читает события из таблицы outbox, публикует их в Kafka
+// периодически захватывает события из таблицы outbox, публикует их в Kafka
 // и помечает как обработанные или неудавшиеся.
+//
+// Несколько реплик сервиса могут работать с одной таблицей одновременно:
+// события захватываются в аренду (lease) на время публикации, поэтому одно событие
+// в штатной ситуации публикует только одна реплика. Если реплика упала, не успев отметить
+// событие, после истечения аренды его заберёт другая (доставка at-least-once).
 type Worker struct {
 	// repo — абстракция над хранилищем outbox-событий (обычно таблица БД).
 	repo Repository
 	// publisher — абстракция над транспортом (Kafka-паблишер и т.п.).
 	publisher Publisher
-	// topic — Kafka-топик, в который будут публиковаться события.
+	// topic — основной Kafka-топик воркера: в него публикуются события без опции Routes,
+	// им же помечаются метрики состояния outbox-таблицы.
 	topic string
+	// router — таблица маршрутизации событий по топикам.
+	router *Router
 
 	// batchLimit — сколько событий максимум за один проход processBatch.
 	batchLimit int
@@ -29,12 +46,33 @@ type Worker struct {
 	requeBatchLimit int
 	// requeFailedInterval — период, с которым будут перевыставляться failed-события.
 	requeFailedInterval time.Duration
+
+	// statsInterval — период обновления метрик outbox-таблицы (pending/failed/processed, возраст pending).
+	statsInterval time.Duration
+
+	// maxAttempts — после стольких неудачных публикаций событие переводится в dead.
+	maxAttempts int
+	// retryBackoff и retryMaxBackoff — начальная и максимальная задержка перед повторной попыткой.
+	retryBackoff    time.Duration
+	retryMaxBackoff time.Duration
+	// onDead — необязательный обработчик событий, переведённых в dead.
+	onDead DeadHandler
+
+	// bg — цикл воркера, запущенный Run.
+	bg lifecycle.Background
+
+	// workerID — идентификатор реплики, записывается в locked_by захваченных событий.
+	workerID string
+	// lease — на сколько захватываются события; должно хватать на публикацию всей пачки.
+	lease time.Duration
+	// wakeup — сигналы о новых событиях (например, от Listener); обрабатываются без ожидания interval.
+	wakeup <-chan struct{}
 }
 
 // NewWorker конструирует Worker с заданным репозиторием, паблишером и настройками батчей/интервалов.
 // Worker сам по себе ничего не делает, пока не будет вызван Run.
-func NewWorker(repo Repository, publisher Publisher, topic string, batchLimit, requeBatchLimit int, interval, requeInterval time.Duration) *Worker {
-	return &Worker{
+func NewWorker(repo Repository, publisher Publisher, topic string, batchLimit, requeBatchLimit int, interval, requeInterval time.Duration, opts ...Option) *Worker {
+	w := &Worker{
 		repo:                repo,
 		publisher:           publisher,
 		topic:               topic,
@@ -42,77 +80,282 @@ func NewWorker(repo Repository, publisher Publisher, topic string, batchLimit, r
 		requeBatchLimit:     requeBatchLimit,
 		interval:            interval,
 		requeFailedInterval: requeInterval,
+		statsInterval:       defaultStatsInterval,
+		maxAttempts:         defaultMaxAttempts,
+		retryBackoff:        defaultRetryBackoff,
+		retryMaxBackoff:     defaultRetryMaxBackoff,
+		workerID:            defaultWorkerID(),
+		lease:               defaultLease,
 	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.router == nil {
+		w.router = SingleTopic(topic)
+	}
+
+	return w
 }
 
 // Run запускает основной цикл воркера в отдельной горутине и немедленно возвращает управление.
-// Внутри горутины воркер периодически:
-//   - читает pending-события из outbox (processBatch);
-//   - перевыставляет failed-события (requeueFailed).
+// Внутри горутины воркер периодически и по сигналам wakeup:
+//   - захватывает и публикует pending-события из outbox (processPending);
+//   - перевыставляет failed-события (requeueFailed);
+//   - обновляет метрики состояния outbox-таблицы (updateStats).
 //
 // Остановка:
 //   - когда ctx.Done() будет закрыт, цикл завершится и воркер корректно остановится.
 func (w *Worker) Run(ctx context.Context) {
-	go func() {
+	w.bg.Go(ctx, func(ctx context.Context, stop <-chan struct{}) {
 		ticker := time.NewTicker(w.interval)
 		defer ticker.Stop()
 
 		requeTicker := time.NewTicker(w.requeFailedInterval)
 		defer requeTicker.Stop()
 
+		statsTicker := time.NewTicker(w.statsInterval)
+		defer statsTicker.Stop()
+
+		w.updateStats(ctx)
+
 		for {
 			select {
 			case <-ctx.Done():
 				logrus.Info("OutboxWorker: shutting down")
 				return
+			case <-stop:
+				logrus.Info("OutboxWorker: shutting down")
+				return
 			case <-ticker.C:
-				w.processBatch(ctx)
+				w.processPending(ctx)
+			case <-w.wakeup:
+				w.processPending(ctx)
 			case <-requeTicker.C:
 				w.requeueFailed(ctx)
+			case <-statsTicker.C:
+				w.updateStats(ctx)
 			}
 		}
-	}()
+	})
+}
+
+// Shutdown прекращает захват новых событий и дожидается публикации уже захваченной пачки.
+// Если ctx истёк раньше, публикация прерывается: события вернутся в работу по истечении lease.
+func (w *Worker) Shutdown(ctx context.Context) error {
+	return w.bg.Shutdown(ctx)
+}
+
+// processPending обрабатывает пачки, пока они приходят полными, чтобы накопившиеся события
+// не ждали следующего тика.
+func (w *Worker) processPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		if n := w.processBatch(ctx); n < w.batchLimit {
+			return
+		}
+	}
 }
 
-// processBatch забирает из репозитория pending-события и пытается отправить каждое в Kafka.
-// Успешные события помечаются как processed, провалившиеся — как failed с текстом ошибки.
-func (w *Worker) processBatch(ctx context.Context) {
-	events, err := w.repo.FetchPending(ctx, w.batchLimit)
+// processBatch захватывает pending-события и отправляет их в Kafka: одной записью, если паблишер
+// реализует BatchPublisher, иначе по одному.
+// Успешные события помечаются как processed, провалившиеся — как failed (см. handleFailure).
+// Возвращает число захваченных событий.
+func (w *Worker) processBatch(ctx context.Context) int {
+	events, err := w.repo.ClaimPending(ctx, w.workerID, w.batchLimit, w.lease)
 	if err != nil {
-		logrus.Errorf("OutboxWorker: failed to fetch pending events: %v", err)
-		return
+		logrus.Errorf("OutboxWorker: failed to claim pending events: %v", err)
+		return 0
 	}
 
 	if len(events) == 0 {
 		logrus.Debug("OutboxWorker: no pending events")
-		return
+		return 0
 	}
 
-	processedIDs := make([]uuid.UUID, 0, len(events))
-
+	routed := make([]routedEvent, 0, len(events))
 	for _, e := range events {
-		err := w.publisher.Publish(ctx, w.topic, e.EventType, e.Payload)
+		targets, err := w.router.Resolve(e)
+		if errors.Is(err, ErrUnroutable) {
+			w.handleUnroutable(ctx, e, err)
+			continue
+		}
 		if err != nil {
-			logrus.Errorf("OutboxWorker: failed to publish event %v: %v", e.ID, err)
-			if errMark := w.repo.MarkFailed(ctx, e.ID, fmt.Sprintf("%v", err)); errMark != nil {
-				logrus.Errorf("OutboxWorker: failed to mark event %v as failed: %v", e.ID, errMark)
-			}
+			// Ошибка преобразователя может быть временной: событие повторяется, как при ошибке публикации.
+			logrus.Errorf("OutboxWorker: failed to route event %v: %v", e.ID, err)
+			w.handleFailure(ctx, e, err)
+			continue
+		}
+		routed = append(routed, routedEvent{event: e, targets: targets})
+	}
+
+	var errs []error
+	if bp, ok := w.publisher.(BatchPublisher); ok {
+		errs = w.publishBatch(ctx, bp, routed)
+	} else {
+		errs = make([]error, len(routed))
+		for i, r := range routed {
+			errs[i] = w.publish(ctx, r.event, r.targets)
+		}
+	}
+
+	processedIDs := make([]uuid.UUID, 0, len(routed))
+	for i, r := range routed {
+		if errs[i] != nil {
+			w.handleFailure(ctx, r.event, errs[i])
 			continue
 		}
 
-		logrus.Infof("OutboxWorker: successfully published event %v", e.ID)
-		processedIDs = append(processedIDs, e.ID)
+		logrus.Debugf("OutboxWorker: successfully published event %v", r.event.ID)
+		processedIDs = append(processedIDs, r.event.ID)
 	}
 
 	if len(processedIDs) > 0 {
-		if err := w.repo.MarkProcessed(ctx, processedIDs); err != nil {
-			logrus.Errorf("OutboxWorker: failed to mark events as processed: %v", err)
+		logrus.Infof("OutboxWorker: published %d events", len(processedIDs))
+		if err := w.repo.MarkProcessed(ctx, w.workerID, processedIDs); err != nil {
+			w.logMarkError("failed to mark events as processed", err)
+		}
+	}
+
+	return len(events)
+}
+
+// routedEvent — захваченное событие и его публикации по маршрутам.
+type routedEvent struct {
+	event   Event
+	targets []Target
+}
+
+// publish отправляет событие во все топики маршрутов. При ошибке событие целиком уходит
+// на повтор, поэтому в уже успешные топики оно может прийти ещё раз с тем же eventId.
+func (w *Worker) publish(ctx context.Context, e Event, targets []Target) error {
+	for _, t := range targets {
+		err := w.publisher.PublishEvent(ctx, t.Topic, t.Event.AggregateID.String(), t.Event.ID, t.Event.EventType, t.Event.OccurredAt, t.Event.Payload)
+		if err != nil {
+			logrus.Errorf("OutboxWorker: failed to publish event %v to %s: %v", e.ID, t.Topic, err)
+			publishFailures.WithLabelValues(t.Topic).Inc()
+			return err
+		}
+		publishedTotal.WithLabelValues(t.Topic).Inc()
+	}
+
+	return nil
+}
+
+// publishBatch отправляет публикации всех событий одной записью и возвращает ошибку
+// по каждому событию (nil — опубликовано во все топики маршрутов).
+func (w *Worker) publishBatch(ctx context.Context, bp BatchPublisher, routed []routedEvent) []error {
+	errs := make([]error, len(routed))
+	if len(routed) == 0 {
+		return errs
+	}
+
+	var (
+		batch []kafka.Publication
+		// owner[i] — индекс в routed события, которому принадлежит batch[i].
+		owner []int
+	)
+	for i, r := range routed {
+		for _, t := range r.targets {
+			batch = append(batch, kafka.Publication{
+				Topic:      t.Topic,
+				Key:        t.Event.AggregateID.String(),
+				EventID:    t.Event.ID,
+				EventType:  t.Event.EventType,
+				OccurredAt: t.Event.OccurredAt,
+				Payload:    t.Event.Payload,
+			})
+			owner = append(owner, i)
+		}
+	}
+
+	err := bp.PublishBatch(ctx, batch)
+
+	var batchErr *kafka.BatchError
+	hasBatchErr := errors.As(err, &batchErr) && len(batchErr.Errs) == len(batch)
+	if err != nil && !hasBatchErr {
+		logrus.Errorf("OutboxWorker: failed to publish batch of %d events: %v", len(routed), err)
+	}
+
+	for i, pub := range batch {
+		pubErr := err
+		if hasBatchErr {
+			pubErr = batchErr.Errs[i]
+		}
+
+		if pubErr == nil {
+			publishedTotal.WithLabelValues(pub.Topic).Inc()
+			continue
+		}
+
+		publishFailures.WithLabelValues(pub.Topic).Inc()
+		if hasBatchErr {
+			logrus.Errorf("OutboxWorker: failed to publish event %v to %s: %v", pub.EventID, pub.Topic, pubErr)
+		}
+		if errs[owner[i]] == nil {
+			errs[owner[i]] = pubErr
 		}
 	}
+
+	return errs
+}
+
+// handleUnroutable сразу переводит событие без маршрута в dead: повторы не помогут,
+// пока не исправлена таблица маршрутизации. После исправления событие можно вернуть
+// в очередь через админский API.
+func (w *Worker) handleUnroutable(ctx context.Context, e Event, routeErr error) {
+	logrus.Errorf("OutboxWorker: event %v cannot be routed, marking dead: %v", e.ID, routeErr)
+	unroutableTotal.WithLabelValues(w.topic).Inc()
+
+	if err := w.repo.MarkDead(ctx, w.workerID, e.ID, routeErr.Error()); err != nil {
+		w.logMarkError(fmt.Sprintf("failed to mark event %v as dead", e.ID), err)
+		return
+	}
+
+	deadTotal.WithLabelValues(w.topic).Inc()
+	if w.onDead != nil {
+		w.onDead(ctx, e, routeErr)
+	}
 }
 
-// requeueFailed просит репозиторий перевыставить ограниченное число failed-событий обратно в pending.
-// Конкретная логика (например, увеличение retry-счётчика) реализуется в Repository.
+// handleFailure планирует повторную попытку с экспоненциальной задержкой
+// или, если попытки исчерпаны, переводит событие в dead.
+func (w *Worker) handleFailure(ctx context.Context, e Event, publishErr error) {
+	attempt := e.Attempts + 1
+
+	if attempt >= w.maxAttempts {
+		logrus.Errorf("OutboxWorker: event %v is dead after %d attempts: %v", e.ID, attempt, publishErr)
+
+		if err := w.repo.MarkDead(ctx, w.workerID, e.ID, publishErr.Error()); err != nil {
+			w.logMarkError(fmt.Sprintf("failed to mark event %v as dead", e.ID), err)
+			return
+		}
+
+		deadTotal.WithLabelValues(w.topic).Inc()
+		if w.onDead != nil {
+			w.onDead(ctx, e, publishErr)
+		}
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(w.retryDelay(attempt))
+	if err := w.repo.MarkFailed(ctx, w.workerID, e.ID, publishErr.Error(), nextAttemptAt); err != nil {
+		w.logMarkError(fmt.Sprintf("failed to mark event %v as failed", e.ID), err)
+	}
+}
+
+// logMarkError логирует неудачную отметку события. Потерянная аренда — не сбой воркера:
+// событие уже захватил другой воркер (или его сбросили через админский API), и отметит его он.
+func (w *Worker) logMarkError(msg string, err error) {
+	if errors.Is(err, ErrLeaseLost) {
+		leaseLostTotal.WithLabelValues(w.topic).Inc()
+		logrus.Warnf("OutboxWorker: %s: %v", msg, err)
+		return
+	}
+	logrus.Errorf("OutboxWorker: %s: %v", msg, err)
+}
+
+// requeueFailed просит репозиторий вернуть в pending failed-события, время повторной попытки которых наступило.
 func (w *Worker) requeueFailed(ctx context.Context) {
 	events, err := w.repo.RequeueFailed(ctx, w.requeBatchLimit)
 	if err != nil {
@@ -126,3 +369,33 @@ func (w *Worker) requeueFailed(ctx context.Context) {
 
 	logrus.Infof("OutboxWorker: requeued %d failed events", len(events))
 }
+
+// updateStats обновляет метрики outbox_events и outbox_oldest_pending_age_seconds.
+func (w *Worker) updateStats(ctx context.Context) {
+	stats, err := w.repo.Stats(ctx)
+	if err != nil {
+		logrus.Errorf("OutboxWorker: failed to collect stats: %v", err)
+		return
+	}
+
+	eventsByStatus.WithLabelValues(w.topic, "pending").Set(float64(stats.Pending))
+	eventsByStatus.WithLabelValues(w.topic, "failed").Set(float64(stats.Failed))
+	eventsByStatus.WithLabelValues(w.topic, "processed").Set(float64(stats.Processed))
+	eventsByStatus.WithLabelValues(w.topic, "dead").Set(float64(stats.Dead))
+
+	age := 0.0
+	if !stats.OldestPending.IsZero() {
+		age = time.Since(stats.OldestPending).Seconds()
+	}
+	oldestPendingAge.WithLabelValues(w.topic).Set(age)
+}
+
+// defaultWorkerID строит идентификатор реплики вида "<hostname>-<pid>".
+func defaultWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	Repository
+	pending      []Event
+	failed       map[uuid.UUID]time.Time
+	dead         []uuid.UUID
+	processedIDs []uuid.UUID
+	// owners — владельцы, от имени которых вызывались отметки.
+	owners []string
+	// leaseLost — отметки возвращают ErrLeaseLost и ничего не меняют.
+	leaseLost bool
+}
+
+func (r *fakeRepo) ClaimPending(context.Context, string, int, time.Duration) ([]Event, error) {
+	return r.pending, nil
+}
+
+func (r *fakeRepo) MarkProcessed(_ context.Context, owner string, ids []uuid.UUID) error {
+	r.owners = append(r.owners, owner)
+	if r.leaseLost {
+		return ErrLeaseLost
+	}
+	r.processedIDs = append(r.processedIDs, ids...)
+	return nil
+}
+
+func (r *fakeRepo) MarkFailed(_ context.Context, owner string, id uuid.UUID, _ string, next time.Time) error {
+	r.owners = append(r.owners, owner)
+	if r.leaseLost {
+		return ErrLeaseLost
+	}
+	r.failed[id] = next
+	return nil
+}
+
+func (r *fakeRepo) MarkDead(_ context.Context, owner string, id uuid.UUID, _ string) error {
+	r.owners = append(r.owners, owner)
+	if r.leaseLost {
+		return ErrLeaseLost
+	}
+	r.dead = append(r.dead, id)
+	return nil
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) PublishEvent(context.Context, string, string, uuid.UUID, string, time.Time, any) error {
+	return errors.New("broker unavailable")
+}
+
+func TestWorker_RetryDelay(t *testing.T) {
+	w := NewWorker(nil, nil, "t", 1, 1, time.Second, time.Second, RetryBackoff(time.Second, time.Minute))
+
+	require.Equal(t, time.Second, w.retryDelay(1))
+	require.Equal(t, 2*time.Second, w.retryDelay(2))
+	require.Equal(t, 32*time.Second, w.retryDelay(6))
+	require.Equal(t, time.Minute, w.retryDelay(7))
+	require.Equal(t, time.Minute, w.retryDelay(100))
+}
+
+func TestWorker_FailedEventsBackOffThenDie(t *testing.T) {
+	retrying := Event{ID: uuid.New(), EventType: "order.created", Attempts: 1}
+	exhausted := Event{ID: uuid.New(), EventType: "order.created", Attempts: 2}
+
+	repo := &fakeRepo{pending: []Event{retrying, exhausted}, failed: make(map[uuid.UUID]time.Time)}
+
+	var deadEvents []Event
+	w := NewWorker(repo, failingPublisher{}, "t", 10, 10, time.Second, time.Second,
+		MaxAttempts(3),
+		RetryBackoff(time.Minute, time.Hour),
+		OnDead(func(_ context.Context, e Event, _ error) { deadEvents = append(deadEvents, e) }),
+	)
+
+	before := time.Now()
+	w.processBatch(context.Background())
+
+	// Вторая неудачная попытка — задержка удваивается.
+	require.Contains(t, repo.failed, retrying.ID)
+	require.WithinDuration(t, before.Add(2*time.Minute), repo.failed[retrying.ID], time.Second)
+
+	// Третья неудачная попытка при MaxAttempts(3) — событие переводится в dead.
+	require.Equal(t, []uuid.UUID{exhausted.ID}, repo.dead)
+	require.Equal(t, []Event{exhausted}, deadEvents)
+	require.Empty(t, repo.processedIDs)
+}
+
+// batchPublisher публикует пачку одним вызовом и отказывает в публикации событиям из failIDs.
+type batchPublisher struct {
+	failingPublisher
+	calls   int
+	failIDs map[uuid.UUID]bool
+}
+
+func (p *batchPublisher) PublishBatch(_ context.Context, batch []kafka.Publication) error {
+	p.calls++
+
+	errs := make([]error, len(batch))
+	failed := false
+	for i, pub := range batch {
+		if p.failIDs[pub.EventID] {
+			errs[i] = errors.New("message too large")
+			failed = true
+		}
+	}
+	if failed {
+		return &kafka.BatchError{Errs: errs}
+	}
+	return nil
+}
+
+func TestWorker_PublishesBatchInOneCall(t *testing.T) {
+	ok1 := Event{ID: uuid.New(), EventType: "order.created"}
+	ok2 := Event{ID: uuid.New(), EventType: "order.paid"}
+	bad := Event{ID: uuid.New(), EventType: "order.paid"}
+
+	repo := &fakeRepo{pending: []Event{ok1, bad, ok2}, failed: make(map[uuid.UUID]time.Time)}
+	pub := &batchPublisher{failIDs: map[uuid.UUID]bool{bad.ID: true}}
+
+	w := NewWorker(repo, pub, "t", 10, 10, time.Second, time.Second)
+	w.processBatch(context.Background())
+
+	require.Equal(t, 1, pub.calls)
+	require.Equal(t, []uuid.UUID{ok1.ID, ok2.ID}, repo.processedIDs)
+	require.Contains(t, repo.failed, bad.ID)
+}
+
+func TestWorker_LeaseLostSkipsDeadHandler(t *testing.T) {
+	exhausted := Event{ID: uuid.New(), EventType: "order.created", Attempts: 2}
+
+	repo := &fakeRepo{pending: []Event{exhausted}, failed: make(map[uuid.UUID]time.Time), leaseLost: true}
+
+	var deadEvents []Event
+	w := NewWorker(repo, failingPublisher{}, "t", 10, 10, time.Second, time.Second,
+		WorkerID("replica-1"),
+		MaxAttempts(3),
+		OnDead(func(_ context.Context, e Event, _ error) { deadEvents = append(deadEvents, e) }),
+	)
+	w.processBatch(context.Background())
+
+	// Событие уже у другого воркера: отметки идут от имени своей реплики, а dead-обработчик не вызывается.
+	require.Equal(t, []string{"replica-1"}, repo.owners)
+	require.Empty(t, repo.dead)
+	require.Empty(t, deadEvents)
+}
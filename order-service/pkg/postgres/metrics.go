@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	queryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "postgres_query_duration_seconds",
+			Help:    "Duration of PostgreSQL queries by repository operation",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		},
+		[]string{"operation"},
+	)
+
+	queryErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "postgres_query_errors_total",
+			Help: "Number of failed PostgreSQL queries by repository operation and SQLSTATE",
+		},
+		[]string{"operation", "sqlstate"},
+	)
+)
+
+// poolCollector отдаёт статистику пулов соединений в момент сбора метрик.
+type poolCollector struct {
+	pg *Postgres
+
+	acquired    *prometheus.Desc
+	idle        *prometheus.Desc
+	total       *prometheus.Desc
+	max         *prometheus.Desc
+	emptyWaits  *prometheus.Desc
+	emptyWaited *prometheus.Desc
+}
+
+func newPoolCollector(pg *Postgres) *poolCollector {
+	labels := []string{"pool"}
+
+	return &poolCollector{
+		pg:          pg,
+		acquired:    prometheus.NewDesc("postgres_pool_acquired_conns", "Number of connections currently acquired from the pool", labels, nil),
+		idle:        prometheus.NewDesc("postgres_pool_idle_conns", "Number of idle connections in the pool", labels, nil),
+		total:       prometheus.NewDesc("postgres_pool_total_conns", "Total number of connections in the pool", labels, nil),
+		max:         prometheus.NewDesc("postgres_pool_max_conns", "Maximum size of the pool", labels, nil),
+		emptyWaits:  prometheus.NewDesc("postgres_pool_empty_acquire_total", "Number of acquires that had to wait for a free connection", labels, nil),
+		emptyWaited: prometheus.NewDesc("postgres_pool_empty_acquire_wait_seconds_total", "Total time acquires waited for a free connection", labels, nil),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.emptyWaits
+	ch <- c.emptyWaited
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collect(ch, "primary", c.pg.Pool)
+	for _, r := range c.pg.replicas {
+		c.collect(ch, r.url, r.pool)
+	}
+}
+
+func (c *poolCollector) collect(ch chan<- prometheus.Metric, name string, pool *pgxpool.Pool) {
+	if pool == nil {
+		return
+	}
+	s := pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()), name)
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()), name)
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()), name)
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()), name)
+	ch <- prometheus.MustNewConstMetric(c.emptyWaits, prometheus.CounterValue, float64(s.EmptyAcquireCount()), name)
+	ch <- prometheus.MustNewConstMetric(c.emptyWaited, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds(), name)
+}
+
+// registerPoolMetrics регистрирует статистику пулов. В процессе обычно один Postgres;
+// если создан второй (например, утилитой replay), его пулы в метрики не попадают.
+func (pg *Postgres) registerPoolMetrics() {
+	err := prometheus.Register(newPoolCollector(pg))
+	if are := (prometheus.AlreadyRegisteredError{}); err != nil && !errors.As(err, &are) {
+		log.Warnf("postgres - register pool metrics: %v", err)
+	}
+}
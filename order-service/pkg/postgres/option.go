@@ -15,3 +15,43 @@ func TimeOut(t time.Duration) Option {
 		p.connTimeout = t
 	}
 }
+
+// Replicas задаёт DSN реплик, на которые GetTxManager отправляет чтение вне транзакций.
+func Replicas(urls ...string) Option {
+	return func(p *Postgres) {
+		for _, url := range urls {
+			if url != "" {
+				p.replicaURLs = append(p.replicaURLs, url)
+			}
+		}
+	}
+}
+
+// MaxReplicaLag задаёт допустимое отставание реплики. Реплика с большим отставанием
+// исключается из чтения, пока не догонит primary. По умолчанию — 5 секунд.
+func MaxReplicaLag(d time.Duration) Option {
+	return func(p *Postgres) {
+		if d > 0 {
+			p.maxReplicaLag = d
+		}
+	}
+}
+
+// ReplicaCheckInterval задаёт период проверки отставания реплик. По умолчанию — раз в секунду.
+func ReplicaCheckInterval(d time.Duration) Option {
+	return func(p *Postgres) {
+		if d > 0 {
+			p.replicaCheckInterval = d
+		}
+	}
+}
+
+// SlowQueryThreshold задаёт длительность, начиная с которой запрос пишется в лог как медленный.
+// По умолчанию — 500 мс.
+func SlowQueryThreshold(d time.Duration) Option {
+	return func(p *Postgres) {
+		if d > 0 {
+			p.slowQueryThreshold = d
+		}
+	}
+}
@@ -2,10 +2,15 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
 	"time"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/transactor"
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -21,14 +26,30 @@ type Postgres struct {
 	connTimeout  time.Duration
 	connAttempts int
 
+	slowQueryThreshold time.Duration
+
+	replicaURLs          []string
+	maxReplicaLag        time.Duration
+	replicaCheckInterval time.Duration
+
 	Pool    *pgxpool.Pool
 	Builder squirrel.StatementBuilderType
+
+	// replicas — пулы реплик для чтения (см. GetTxManager), пусто, если реплики не заданы.
+	replicas    []*replica
+	next        atomic.Uint64
+	stopReplica context.CancelFunc
 }
 
 func New(url string, opts ...Option) (*Postgres, error) {
 	pg := &Postgres{
 		connAttempts: defaultConnAttempts,
 		connTimeout:  defaultConnTimeout,
+
+		slowQueryThreshold: defaultSlowQueryThreshold,
+
+		maxReplicaLag:        defaultMaxReplicaLag,
+		replicaCheckInterval: defaultReplicaCheckInterval,
 	}
 
 	// Custom options
@@ -42,6 +63,7 @@ func New(url string, opts ...Option) (*Postgres, error) {
 	if err != nil {
 		return nil, fmt.Errorf("postgres - NewPostgres - pgxpool.ParseConfig: %w", err)
 	}
+	poolConfig.ConnConfig.Tracer = pg.tracer()
 
 	for pg.connAttempts > 0 {
 		pg.Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
@@ -62,10 +84,24 @@ func New(url string, opts ...Option) (*Postgres, error) {
 		return nil, fmt.Errorf("postgres - NewPostgres - connAtempts == 0: %w", err)
 	}
 
+	if err := pg.connectReplicas(); err != nil {
+		pg.Pool.Close()
+		return nil, err
+	}
+
+	pg.registerPoolMetrics()
+
 	return pg, nil
 }
 
+// Ping проверяет, что primary доступен: берёт соединение из пула и выполняет пустой запрос.
+func (pg *Postgres) Ping(ctx context.Context) error {
+	return pg.Pool.Ping(WithOperation(ctx, "Postgres.Ping"))
+}
+
 func (pg *Postgres) Close() {
+	pg.closeReplicas()
+
 	if pg.Pool != nil {
 		pg.Pool.Close()
 	}
@@ -92,25 +128,99 @@ type TxManager interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 
+// GetTxManager возвращает транзакцию из контекста, а без неё — пул primary.
+// Если заданы реплики (см. Replicas), чтение вне транзакции уходит на реплику:
+// Exec всегда выполняется на primary, Query и QueryRow — на реплике, если запрос только читает
+// (см. isReadQuery). WithPrimary отключает реплики для отдельного вызова.
 func (pg *Postgres) GetTxManager(ctx context.Context) TxManager {
 	if tx, ok := extractTx(ctx); ok {
 		return tx
 	}
-	return pg.Pool
+	if len(pg.replicas) == 0 || forcePrimary(ctx) {
+		return pg.Pool
+	}
+	return readRouter{pg: pg}
+}
+
+// WithinTransaction выполняет fn в транзакции, которая передаётся через контекст.
+// Если в ctx уже есть транзакция, открывается savepoint: ошибка fn откатывает только его,
+// а фиксирует изменения внешняя транзакция. Опции применяются только к внешней транзакции.
+func (pg *Postgres) WithinTransaction(ctx context.Context, fn func(context.Context) error, opts ...transactor.Option) error {
+	if tx, ok := extractTx(ctx); ok {
+		return pg.withinSavepoint(ctx, tx, fn)
+	}
+
+	o := transactor.Apply(opts...)
+	txOptions := pgx.TxOptions{IsoLevel: pgx.TxIsoLevel(o.Isolation)}
+	if o.ReadOnly {
+		txOptions.AccessMode = pgx.ReadOnly
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := pg.runTx(ctx, txOptions, fn)
+		if err == nil || attempt >= o.MaxRetries || !isRetryable(err) {
+			return err
+		}
+
+		delay := retryDelay(attempt)
+		log.Warnf("postgres - WithinTransaction: retrying after %v in %v (attempt %d/%d)", err, delay, attempt+1, o.MaxRetries)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
 }
 
-func (pg *Postgres) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
-	tx, err := pg.Pool.Begin(ctx)
+func (pg *Postgres) runTx(ctx context.Context, txOptions pgx.TxOptions, fn func(context.Context) error) error {
+	tx, err := pg.Pool.BeginTx(ctx, txOptions)
 	if err != nil {
 		return fmt.Errorf("postgres - Begin transaction: %w", err)
 	}
 
-	ctxTx := injectTx(ctx, tx)
-
-	if err := fn(ctxTx); err != nil {
+	if err := fn(injectTx(ctx, tx)); err != nil {
 		_ = tx.Rollback(ctx)
 		return err
 	}
 
 	return tx.Commit(ctx)
 }
+
+// withinSavepoint выполняет fn во вложенной транзакции pgx, то есть под SAVEPOINT.
+func (pg *Postgres) withinSavepoint(ctx context.Context, tx pgx.Tx, fn func(context.Context) error) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres - Begin savepoint: %w", err)
+	}
+
+	if err := fn(injectTx(ctx, sp)); err != nil {
+		_ = sp.Rollback(ctx)
+		return err
+	}
+
+	return sp.Commit(ctx)
+}
+
+const (
+	retryBaseDelay = 10 * time.Millisecond
+	retryMaxDelay  = 500 * time.Millisecond
+)
+
+// retryDelay возвращает случайную задержку в [d/2, d), где d = retryBaseDelay * 2^attempt,
+// но не больше retryMaxDelay. Разброс не даёт конфликтующим транзакциям повторяться синхронно.
+func retryDelay(attempt int) time.Duration {
+	d := retryBaseDelay << min(attempt, 16)
+	d = min(d, retryMaxDelay)
+	return d/2 + rand.N(d/2)
+}
+
+// isRetryable сообщает, можно ли повторить транзакцию целиком:
+// serialization failure (40001) или обнаруженный deadlock (40P01).
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgerrcode.SerializationFailure || pgErr.Code == pgerrcode.DeadlockDetected
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryable(t *testing.T) {
+	wrap := func(code string) error {
+		return fmt.Errorf("repo: %w", &pgconn.PgError{Code: code})
+	}
+
+	require.True(t, isRetryable(wrap(pgerrcode.SerializationFailure)))
+	require.True(t, isRetryable(wrap(pgerrcode.DeadlockDetected)))
+	require.False(t, isRetryable(wrap(pgerrcode.UniqueViolation)))
+	require.False(t, isRetryable(errors.New("boom")))
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 0; attempt < 40; attempt++ {
+		d := min(retryBaseDelay<<min(attempt, 16), retryMaxDelay)
+		got := retryDelay(attempt)
+		require.GreaterOrEqual(t, got, d/2)
+		require.Less(t, got, d)
+	}
+}
+
+func TestIsReadQuery(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want bool
+	}{
+		{"SELECT id FROM orders WHERE id = $1", true},
+		{"\n\t\tselect count(*) from orders", true},
+		{"(SELECT 1) UNION ALL (SELECT 2)", true},
+		{"SELECT id FROM orders WHERE id = $1 FOR UPDATE", false},
+		{"SELECT id FROM outbox FOR NO KEY UPDATE SKIP LOCKED", false},
+		{"SELECT pg_notify($1, '')", false},
+		{"SELECT pg_try_advisory_lock($1)", false},
+		{"WITH claimed AS (UPDATE outbox SET locked_by = $1 RETURNING *) SELECT * FROM claimed", false},
+		{"INSERT INTO outbox (payload) VALUES ($1) RETURNING id", false},
+		{"SELECT selected_for_update FROM t", true},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, isReadQuery(tt.sql), tt.sql)
+	}
+}
+
+func TestReadPool_FallsBackToPrimary(t *testing.T) {
+	pg := &Postgres{Pool: &pgxpool.Pool{}, replicas: []*replica{{pool: &pgxpool.Pool{}}}}
+	require.Same(t, pg.Pool, pg.readPool())
+
+	healthy := &replica{pool: &pgxpool.Pool{}}
+	healthy.healthy.Store(true)
+	pg.replicas = append(pg.replicas, healthy)
+	for i := 0; i < 5; i++ {
+		require.Same(t, healthy.pool, pg.readPool())
+	}
+}
+
+func TestGetTxManager_WithPrimary(t *testing.T) {
+	pg := &Postgres{replicas: []*replica{{}}}
+
+	require.IsType(t, readRouter{}, pg.GetTxManager(context.Background()))
+	require.Equal(t, TxManager(pg.Pool), pg.GetTxManager(WithPrimary(context.Background())))
+}
+
+func TestSanitizeSQL(t *testing.T) {
+	sql := `
+		SELECT id, 'it''s secret' AS note
+		FROM orders
+		WHERE customer_id = $1 AND total_amount > 100.50 AND idx_2 = 3
+		LIMIT $2`
+
+	require.Equal(t,
+		"SELECT id, ? AS note FROM orders WHERE customer_id = $1 AND total_amount > ? AND idx_2 = ? LIMIT $2",
+		sanitizeSQL(sql))
+}
+
+func TestSQLState(t *testing.T) {
+	require.Equal(t, pgerrcode.UniqueViolation, sqlState(fmt.Errorf("insert: %w", &pgconn.PgError{Code: pgerrcode.UniqueViolation})))
+	require.Equal(t, "client", sqlState(context.DeadlineExceeded))
+}
+
+func TestOperation(t *testing.T) {
+	require.Equal(t, unknownOperation, operation(context.Background()))
+	require.Equal(t, "OrderRepository.GetOrderByID", operation(WithOperation(context.Background(), "OrderRepository.GetOrderByID")))
+}
@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxReplicaLag        = 5 * time.Second
+	defaultReplicaCheckInterval = time.Second
+)
+
+// replicaLagQuery возвращает отставание реплики в секундах. Реплика, которая применила
+// всё полученное WAL, не отстаёт, даже если primary давно ничего не писал.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`
+
+type replica struct {
+	url     string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+type primaryKey struct{}
+
+// WithPrimary помечает контекст: чтение через GetTxManager идёт на primary, даже если заданы реплики.
+// Нужно, когда результат должен учитывать только что закоммиченную запись.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func forcePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// connectReplicas создаёт пулы реплик и запускает проверку отставания.
+// Недоступная при старте реплика не мешает запуску: она включится, когда ответит на проверку.
+func (pg *Postgres) connectReplicas() error {
+	if len(pg.replicaURLs) == 0 {
+		return nil
+	}
+
+	for _, url := range pg.replicaURLs {
+		poolConfig, err := pgxpool.ParseConfig(url)
+		if err != nil {
+			pg.closeReplicas()
+			return fmt.Errorf("postgres - NewPostgres - replica pgxpool.ParseConfig: %w", err)
+		}
+		poolConfig.ConnConfig.Tracer = pg.tracer()
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			pg.closeReplicas()
+			return fmt.Errorf("postgres - NewPostgres - replica pgxpool.NewWithConfig: %w", err)
+		}
+		pg.replicas = append(pg.replicas, &replica{url: redactURL(url), pool: pool})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pg.stopReplica = cancel
+
+	pg.checkReplicas(ctx)
+	go func() {
+		ticker := time.NewTicker(pg.replicaCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				pg.checkReplicas(ctx)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (pg *Postgres) closeReplicas() {
+	if pg.stopReplica != nil {
+		pg.stopReplica()
+	}
+	for _, r := range pg.replicas {
+		r.pool.Close()
+	}
+	pg.replicas = nil
+}
+
+// checkReplicas исключает из чтения недоступные реплики и реплики с отставанием больше maxReplicaLag.
+func (pg *Postgres) checkReplicas(ctx context.Context) {
+	for _, r := range pg.replicas {
+		checkCtx, cancel := context.WithTimeout(WithOperation(ctx, "Postgres.checkReplicas"), pg.replicaCheckInterval)
+		var lag float64
+		err := r.pool.QueryRow(checkCtx, replicaLagQuery).Scan(&lag)
+		cancel()
+
+		lagDuration := time.Duration(lag * float64(time.Second))
+		healthy := err == nil && lagDuration <= pg.maxReplicaLag
+
+		if was := r.healthy.Swap(healthy); was != healthy {
+			if healthy {
+				log.Infof("Postgres replica %s is back, lag %v", r.url, lagDuration)
+			} else if err != nil {
+				log.Warnf("Postgres replica %s is unavailable, reading from primary: %v", r.url, err)
+			} else {
+				log.Warnf("Postgres replica %s lags %v behind primary, reading from primary", r.url, lagDuration)
+			}
+		}
+	}
+}
+
+// readPool возвращает пул очередной исправной реплики по кругу, а если таких нет — primary.
+func (pg *Postgres) readPool() *pgxpool.Pool {
+	n := uint64(len(pg.replicas))
+	start := pg.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := pg.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return pg.Pool
+}
+
+// readRouter — TxManager вне транзакции при заданных репликах.
+type readRouter struct {
+	pg *Postgres
+}
+
+func (r readRouter) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return r.pg.Pool.Exec(ctx, sql, args...)
+}
+
+func (r readRouter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return r.pool(sql).Query(ctx, sql, args...)
+}
+
+func (r readRouter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return r.pool(sql).QueryRow(ctx, sql, args...)
+}
+
+func (r readRouter) pool(sql string) *pgxpool.Pool {
+	if isReadQuery(sql) {
+		return r.pg.readPool()
+	}
+	return r.pg.Pool
+}
+
+var (
+	readQueryPrefix = regexp.MustCompile(`(?is)^\s*(\(\s*)*select\b`)
+	// lockingOrWriting — конструкции SELECT, которые блокируют строки, меняют состояние
+	// или должны выполняться на primary.
+	lockingOrWriting = regexp.MustCompile(`(?i)\bfor\s+(no\s+key\s+update|update|key\s+share|share)\b|\b(nextval|setval|pg_notify|pg_advisory\w*|pg_try_advisory\w*)\s*\(`)
+)
+
+// isReadQuery сообщает, что запрос только читает данные: начинается с SELECT и не захватывает
+// блокировки строк и advisory-блокировки. WITH-запросы всегда идут на primary —
+// CTE может изменять данные (UPDATE ... RETURNING).
+func isReadQuery(sql string) bool {
+	return readQueryPrefix.MatchString(sql) && !lockingOrWriting.MatchString(sql)
+}
+
+// redactURL убирает пароль из DSN для логов.
+func redactURL(url string) string {
+	cfg, err := pgx.ParseConfig(url)
+	if err != nil {
+		return "replica"
+	}
+	return fmt.Sprintf("%s:%d/%s", cfg.Host, cfg.Port, strings.TrimPrefix(cfg.Database, "/"))
+}
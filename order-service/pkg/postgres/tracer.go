@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSlowQueryThreshold = 500 * time.Millisecond
+
+	// unknownOperation — метка запросов, для которых не задана операция (см. WithOperation).
+	unknownOperation = "unknown"
+
+	maxLoggedSQL = 2000
+)
+
+type operationKey struct{}
+
+// WithOperation задаёт имя операции ("OrderRepository.GetOrderByID") для запросов,
+// выполненных с этим контекстом: по нему размечаются метрики и логи медленных запросов.
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+func operation(ctx context.Context) string {
+	if name, ok := ctx.Value(operationKey{}).(string); ok && name != "" {
+		return name
+	}
+	return unknownOperation
+}
+
+type queryTraceKey struct{}
+
+type queryTrace struct {
+	operation string
+	sql       string
+	start     time.Time
+}
+
+// queryTracer — pgx.QueryTracer: пишет длительность запросов в postgres_query_duration_seconds,
+// ошибки — в postgres_query_errors_total по SQLSTATE, а запросы дольше slowThreshold — в лог.
+type queryTracer struct {
+	slowThreshold time.Duration
+}
+
+func (pg *Postgres) tracer() *queryTracer {
+	return &queryTracer{slowThreshold: pg.slowQueryThreshold}
+}
+
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryTraceKey{}, queryTrace{
+		operation: operation(ctx),
+		sql:       data.SQL,
+		start:     time.Now(),
+	})
+}
+
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(queryTraceKey{}).(queryTrace)
+	if !ok {
+		return
+	}
+
+	elapsed := time.Since(trace.start)
+	queryDuration.WithLabelValues(trace.operation).Observe(elapsed.Seconds())
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		queryErrors.WithLabelValues(trace.operation, sqlState(data.Err)).Inc()
+	}
+
+	if t.slowThreshold > 0 && elapsed >= t.slowThreshold {
+		log.Warnf("Postgres slow query: operation=%s duration=%v sql=%s",
+			trace.operation, elapsed, sanitizeSQL(trace.sql))
+	}
+}
+
+// sqlState возвращает SQLSTATE ошибки Postgres; для ошибок соединения, таймаутов и т.п. — "client".
+func sqlState(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return "client"
+}
+
+var (
+	sqlStringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumberLiteral = regexp.MustCompile(`([^$\w.])\d+(?:\.\d+)?\b`)
+)
+
+// sanitizeSQL готовит запрос для лога: заменяет литералы на ?, схлопывает пробелы
+// и обрезает слишком длинный текст. Параметры запроса ($1, $2...) в лог не попадают.
+func sanitizeSQL(sql string) string {
+	sql = sqlStringLiteral.ReplaceAllString(sql, "?")
+	sql = sqlNumberLiteral.ReplaceAllString(sql, "${1}?")
+	sql = strings.Join(strings.Fields(sql), " ")
+
+	if len(sql) > maxLoggedSQL {
+		sql = sql[:maxLoggedSQL] + "..."
+	}
+	return sql
+}
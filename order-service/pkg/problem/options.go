@@ -0,0 +1,21 @@
+package problem
+
+// Option -.
+type Option func(*Handler)
+
+// Map сопоставляет ошибку сервиса (sentinel, проверяется через errors.Is) со статусом ответа
+// и стабильным code. Ошибки проверяются в порядке регистрации.
+func Map(err error, status int, code string) Option {
+	return func(h *Handler) {
+		h.mappings = append(h.mappings, mapping{err: err, status: status, code: code})
+	}
+}
+
+// TypePrefix задаёт префикс type: type = prefix + code.
+func TypePrefix(prefix string) Option {
+	return func(h *Handler) {
+		if prefix != "" {
+			h.typePrefix = prefix
+		}
+	}
+}
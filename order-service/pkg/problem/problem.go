@@ -0,0 +1,153 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/middleware"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/validator"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+)
+
+// MIMEProblemJSON — Content-Type ответа с ошибкой по RFC 7807.
+const MIMEProblemJSON = "application/problem+json"
+
+// defaultTypePrefix — type ошибки — URN, а не URL: документация по нему не открывается,
+// но идентификатор стабилен и не зависит от адреса, по которому развёрнут сервис.
+const defaultTypePrefix = "urn:big-bob-pizza:problem:"
+
+// CodeValidationFailed — code ошибки валидации тела запроса, детали по полям — в Errors.
+const CodeValidationFailed = "validation_failed"
+
+// Problem — тело ответа с ошибкой (RFC 7807). Code дублирует последний сегмент Type, чтобы
+// клиентам не нужно было разбирать URI.
+type Problem struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty"`
+	Code      string                 `json:"code"`
+	RequestID string                 `json:"requestId,omitempty"`
+	Errors    []validator.FieldError `json:"errors,omitempty"`
+}
+
+// mapping сопоставляет ошибку сервиса со статусом и code ответа.
+type mapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// Handler — центральный обработчик ошибок Echo: переводит ошибки обработчиков в Problem.
+type Handler struct {
+	mappings   []mapping
+	typePrefix string
+}
+
+func New(opts ...Option) *Handler {
+	h := &Handler{typePrefix: defaultTypePrefix}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Handle — echo.HTTPErrorHandler. Порядок разбора ошибки:
+//   - *validator.ValidationError — 400 validation_failed с ошибками по полям;
+//   - ошибка из Map (через errors.Is) — её статус и code, detail — текст сопоставленной ошибки;
+//   - *echo.HTTPError — его статус; для 4xx detail — сообщение ошибки;
+//   - всё остальное — 500.
+//
+// Причина 5xx пишется в лог и никогда не попадает в ответ: в detail могли бы оказаться
+// тексты ошибок pgx или Kafka.
+func (h *Handler) Handle(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	p := h.problem(err)
+	p.Instance = c.Request().URL.Path
+	p.RequestID = middleware.RequestIDFromContext(c.Request().Context())
+
+	if p.Status >= http.StatusInternalServerError {
+		log.WithFields(log.Fields{
+			"request_id": p.RequestID,
+			"method":     c.Request().Method,
+			"route":      c.Path(),
+			"status":     p.Status,
+		}).Errorf("ErrorHandler: %v", err)
+	}
+
+	if werr := write(c, p); werr != nil {
+		log.Errorf("ErrorHandler: write response: %v", werr)
+	}
+}
+
+func (h *Handler) problem(err error) *Problem {
+	var validationErr *validator.ValidationError
+	if errors.As(err, &validationErr) {
+		p := h.newProblem(http.StatusBadRequest, CodeValidationFailed)
+		p.Detail = "request validation failed"
+		p.Errors = validationErr.Fields
+		return p
+	}
+
+	for _, m := range h.mappings {
+		if errors.Is(err, m.err) {
+			p := h.newProblem(m.status, m.code)
+			if m.status < http.StatusInternalServerError {
+				p.Detail = m.err.Error()
+			}
+			return p
+		}
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		p := h.newProblem(httpErr.Code, statusCode(httpErr.Code))
+		if httpErr.Code < http.StatusInternalServerError {
+			if msg := fmt.Sprint(httpErr.Message); msg != http.StatusText(httpErr.Code) {
+				p.Detail = msg
+			}
+		}
+		return p
+	}
+
+	return h.newProblem(http.StatusInternalServerError, statusCode(http.StatusInternalServerError))
+}
+
+func (h *Handler) newProblem(status int, code string) *Problem {
+	return &Problem{
+		Type:   h.typePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   code,
+	}
+}
+
+// statusCode — code для ошибок без сопоставления: текст статуса в snake_case ("not_found").
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
+
+func write(c echo.Context, p *Problem) error {
+	res := c.Response()
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(p.Status)
+	}
+
+	res.Header().Set(echo.HeaderContentType, MIMEProblemJSON)
+	res.WriteHeader(p.Status)
+	return json.NewEncoder(res).Encode(p)
+}
@@ -0,0 +1,128 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/validator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errOrderNotFound = errors.New("order not found")
+
+type request struct {
+	Name  string `json:"name" validate:"required"`
+	Count int    `json:"count" validate:"min=1"`
+}
+
+func newEcho() *echo.Echo {
+	e := echo.New()
+	e.Validator = validator.NewCustomValidator()
+	e.HTTPErrorHandler = New(Map(errOrderNotFound, http.StatusNotFound, "order_not_found")).Handle
+	return e
+}
+
+func do(t *testing.T, e *echo.Echo, req *http.Request) (*httptest.ResponseRecorder, Problem) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var p Problem
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, MIMEProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	return rec, p
+}
+
+func TestHandle_MappedError(t *testing.T) {
+	e := newEcho()
+	e.GET("/orders/:id", func(c echo.Context) error {
+		return fmt.Errorf("service - GetOrderByID: %w", errOrderNotFound)
+	})
+
+	rec, p := do(t, e, httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "order_not_found", p.Code)
+	assert.Equal(t, defaultTypePrefix+"order_not_found", p.Type)
+	assert.Equal(t, "order not found", p.Detail)
+	assert.Equal(t, "/orders/1", p.Instance)
+}
+
+func TestHandle_InternalErrorIsHidden(t *testing.T) {
+	e := newEcho()
+	e.GET("/orders", func(c echo.Context) error {
+		return errors.New(`ERROR: relation "orders" does not exist (SQLSTATE 42P01)`)
+	})
+
+	rec, p := do(t, e, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "internal_server_error", p.Code)
+	assert.Empty(t, p.Detail)
+	assert.NotContains(t, rec.Body.String(), "SQLSTATE")
+}
+
+func TestHandle_ValidationError(t *testing.T) {
+	e := newEcho()
+	e.POST("/orders", func(c echo.Context) error {
+		var in request
+		if err := c.Bind(&in); err != nil {
+			return err
+		}
+		return c.Validate(in)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"count":0}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec, p := do(t, e, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, CodeValidationFailed, p.Code)
+	require.Len(t, p.Errors, 2)
+	assert.Equal(t, "name", p.Errors[0].Field)
+	assert.Equal(t, "required", p.Errors[0].Rule)
+	assert.Equal(t, "count", p.Errors[1].Field)
+	assert.Equal(t, "1", p.Errors[1].Param)
+}
+
+func TestHandle_HTTPError(t *testing.T) {
+	e := newEcho()
+	e.GET("/orders/:id", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid order ID")
+	})
+
+	rec, p := do(t, e, httptest.NewRequest(http.MethodGet, "/orders/x", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "bad_request", p.Code)
+	assert.Equal(t, "invalid order ID", p.Detail)
+}
+
+func TestHandle_HTTPError5xxMessageIsHidden(t *testing.T) {
+	e := newEcho()
+	e.GET("/orders", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusInternalServerError, "dial tcp 10.0.0.5:5432: connection refused")
+	})
+
+	rec, p := do(t, e, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, p.Detail)
+	assert.NotContains(t, rec.Body.String(), "10.0.0.5")
+}
+
+func TestHandle_RouteNotFound(t *testing.T) {
+	e := newEcho()
+
+	rec, p := do(t, e, httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "not_found", p.Code)
+}
@@ -0,0 +1,219 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 5 * time.Second
+)
+
+// ErrCircuitOpen возвращается командами, пока circuit breaker открыт: Redis считается недоступным,
+// и вызывающий код должен идти в основное хранилище, не дожидаясь таймаута.
+var ErrCircuitOpen = errors.New("redis: circuit breaker is open")
+
+// BreakerState — состояние circuit breaker. Значение экспортируется в метрику redis_circuit_breaker_state.
+type BreakerState int
+
+const (
+	// BreakerClosed — Redis исправен, команды выполняются.
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen — истёк openTimeout, выполняется одна пробная команда.
+	BreakerHalfOpen
+	// BreakerOpen — команды отклоняются с ErrCircuitOpen.
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	breakerStateGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "redis_circuit_breaker_state",
+		Help: "Redis circuit breaker state: 0 - closed, 1 - half-open, 2 - open",
+	})
+
+	breakerRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "redis_circuit_breaker_rejected_total",
+		Help: "Number of Redis commands rejected by the open circuit breaker",
+	})
+)
+
+// breaker — go-redis hook, который считает подряд идущие сетевые ошибки и таймауты.
+// Ответы сервера вроде redis.Nil или WRONGTYPE ошибками доступности не считаются.
+type breaker struct {
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(threshold int, openTimeout time.Duration) *breaker {
+	return &breaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		now:         time.Now,
+	}
+}
+
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow сообщает, можно ли выполнить команду. В half-open пропускается только одна пробная команда.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.setState(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// done учитывает результат выполненной команды.
+func (b *breaker) done(err error) {
+	failed := isUnavailable(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case b.state == BreakerHalfOpen && errors.Is(err, context.Canceled):
+		// Проба прервана вызывающим кодом — дождёмся следующей.
+		b.probing = false
+	case b.state == BreakerHalfOpen:
+		b.probing = false
+		if failed {
+			b.open()
+		} else {
+			b.failures = 0
+			b.setState(BreakerClosed)
+		}
+	case failed:
+		b.failures++
+		if b.state == BreakerClosed && b.failures >= b.threshold {
+			b.open()
+		}
+	default:
+		b.failures = 0
+	}
+}
+
+func (b *breaker) open() {
+	b.openedAt = b.now()
+	b.setState(BreakerOpen)
+}
+
+func (b *breaker) setState(s BreakerState) {
+	if b.state == s {
+		return
+	}
+
+	switch s {
+	case BreakerOpen:
+		log.Warnf("Redis circuit breaker opened after %d failures, retry in %v", b.failures, b.openTimeout)
+	case BreakerClosed:
+		log.Infof("Redis circuit breaker closed, Redis is available again")
+	}
+
+	b.state = s
+	breakerStateGauge.Set(float64(s))
+}
+
+func (b *breaker) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (b *breaker) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !b.allow() {
+			breakerRejected.Inc()
+			cmd.SetErr(ErrCircuitOpen)
+			return ErrCircuitOpen
+		}
+
+		err := next(ctx, cmd)
+		b.done(err)
+		return err
+	}
+}
+
+func (b *breaker) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !b.allow() {
+			breakerRejected.Inc()
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrCircuitOpen)
+			}
+			return ErrCircuitOpen
+		}
+
+		err := next(ctx, cmds)
+		b.done(err)
+		return err
+	}
+}
+
+// isUnavailable отделяет недоступность Redis от обычных ответов сервера.
+// Отмена контекста вызывающим кодом не говорит о состоянии Redis и не учитывается.
+func isUnavailable(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		// Узел жив, но не может обслуживать запросы.
+		msg := redisErr.Error()
+		for _, prefix := range []string{"LOADING", "READONLY", "MASTERDOWN", "CLUSTERDOWN", "TRYAGAIN"} {
+			if strings.HasPrefix(msg, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Сетевые ошибки, таймауты, EOF, исчерпание пула.
+	return true
+}
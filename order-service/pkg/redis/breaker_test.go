@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := newBreaker(3, 5*time.Second)
+	b.now = func() time.Time { return now }
+
+	netErr := errors.New("dial tcp: connection refused")
+
+	// Ответы сервера не открывают breaker.
+	for i := 0; i < 5; i++ {
+		require.True(t, b.allow())
+		b.done(redis.Nil)
+	}
+	require.Equal(t, BreakerClosed, b.State())
+
+	for i := 0; i < 3; i++ {
+		require.True(t, b.allow())
+		b.done(netErr)
+	}
+	require.Equal(t, BreakerOpen, b.State())
+	require.False(t, b.allow())
+
+	// После openTimeout пропускается одна пробная команда.
+	now = now.Add(5 * time.Second)
+	require.True(t, b.allow())
+	require.Equal(t, BreakerHalfOpen, b.State())
+	require.False(t, b.allow())
+
+	b.done(netErr)
+	require.Equal(t, BreakerOpen, b.State())
+
+	now = now.Add(5 * time.Second)
+	require.True(t, b.allow())
+	b.done(nil)
+	require.Equal(t, BreakerClosed, b.State())
+	require.True(t, b.allow())
+}
+
+func TestIsUnavailable(t *testing.T) {
+	require.False(t, isUnavailable(nil))
+	require.False(t, isUnavailable(redis.Nil))
+	require.False(t, isUnavailable(context.Canceled))
+	require.True(t, isUnavailable(context.DeadlineExceeded))
+	require.True(t, isUnavailable(redis.ErrPoolTimeout))
+}
@@ -1,6 +1,9 @@
 package redis
 
-import "time"
+import (
+	"crypto/tls"
+	"time"
+)
 
 type Option func(*Redis)
 
@@ -21,3 +24,79 @@ func Prefix(p string) Option {
 		r.keyPrefix = p
 	}
 }
+
+// Sentinel включает режим Sentinel: addrs — адреса sentinel-узлов, masterName — имя master.
+func Sentinel(masterName string) Option {
+	return func(r *Redis) {
+		r.mode = ModeSentinel
+		r.masterName = masterName
+	}
+}
+
+// SentinelAuth задаёт учётные данные sentinel-узлов, если они отличаются от учётных данных Redis.
+func SentinelAuth(username, password string) Option {
+	return func(r *Redis) {
+		r.sentinelUsername = username
+		r.sentinelPassword = password
+	}
+}
+
+// Cluster включает режим Redis Cluster. DB в этом режиме не используется.
+func Cluster() Option {
+	return func(r *Redis) {
+		r.mode = ModeCluster
+	}
+}
+
+// Auth задаёт пользователя (ACL, Redis 6+) и пароль. Пустой username — пользователь default.
+func Auth(username, password string) Option {
+	return func(r *Redis) {
+		r.username = username
+		r.password = password
+	}
+}
+
+// DB задаёт номер базы для standalone и sentinel.
+func DB(db int) Option {
+	return func(r *Redis) {
+		r.db = db
+	}
+}
+
+// PoolSize задаёт размер пула соединений (на каждый узел в режиме кластера).
+func PoolSize(n int) Option {
+	return func(r *Redis) {
+		if n > 0 {
+			r.poolSize = n
+		}
+	}
+}
+
+// CommandTimeout задаёт таймаут чтения и записи одной команды.
+func CommandTimeout(t time.Duration) Option {
+	return func(r *Redis) {
+		if t > 0 {
+			r.commandTimeout = t
+		}
+	}
+}
+
+// TLS включает TLS для соединений с Redis (и sentinel-узлами).
+func TLS(cfg *tls.Config) Option {
+	return func(r *Redis) {
+		r.tlsConfig = cfg
+	}
+}
+
+// Breaker настраивает circuit breaker: после threshold подряд неудачных команд он открывается
+// на openTimeout, затем пропускает одну пробную команду.
+func Breaker(threshold int, openTimeout time.Duration) Option {
+	return func(r *Redis) {
+		if threshold > 0 {
+			r.breaker.threshold = threshold
+		}
+		if openTimeout > 0 {
+			r.breaker.openTimeout = openTimeout
+		}
+	}
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"time"
 
@@ -10,22 +11,61 @@ import (
 )
 
 const (
-	defaultConnTimeout  = time.Second
-	defaultConnAttempts = 10
+	defaultConnTimeout    = time.Second
+	defaultConnAttempts   = 10
+	defaultPoolSize       = 20
+	defaultMinIdleConns   = 5
+	defaultCommandTimeout = 500 * time.Millisecond
+)
+
+// Nil — ошибка чтения отсутствующего ключа.
+var Nil = redis.Nil
+
+// Mode — топология Redis.
+type Mode string
+
+const (
+	// ModeStandalone — один узел, адрес — первый из addrs.
+	ModeStandalone Mode = "standalone"
+	// ModeSentinel — master/replica под управлением Sentinel, addrs — адреса sentinel-узлов.
+	ModeSentinel Mode = "sentinel"
+	// ModeCluster — Redis Cluster, addrs — адреса одного или нескольких узлов кластера.
+	ModeCluster Mode = "cluster"
 )
 
 type Redis struct {
-	Client       *redis.Client
+	Client       redis.UniversalClient
 	connAttempts int
 	connTimeout  time.Duration
 	keyPrefix    string
+
+	mode       Mode
+	masterName string
+	username   string
+	password   string
+	// sentinelUsername и sentinelPassword — учётные данные самих sentinel-узлов, если у них включена авторизация.
+	sentinelUsername string
+	sentinelPassword string
+	db               int
+	poolSize         int
+	commandTimeout   time.Duration
+	tlsConfig        *tls.Config
+
+	breaker *breaker
 }
 
-func New(addr, password string, db int, opts ...Option) (*Redis, error) {
+// New подключается к Redis. addrs трактуются в зависимости от режима (см. Mode);
+// по умолчанию — standalone. Все команды клиента проходят через circuit breaker:
+// пока Redis недоступен, они сразу возвращают ErrCircuitOpen, не дожидаясь таймаута.
+func New(addrs []string, opts ...Option) (*Redis, error) {
 	r := &Redis{
-		connAttempts: defaultConnAttempts,
-		connTimeout:  defaultConnTimeout,
-		keyPrefix:    "",
+		connAttempts:   defaultConnAttempts,
+		connTimeout:    defaultConnTimeout,
+		keyPrefix:      "",
+		mode:           ModeStandalone,
+		poolSize:       defaultPoolSize,
+		commandTimeout: defaultCommandTimeout,
+		breaker:        newBreaker(defaultFailureThreshold, defaultOpenTimeout),
 	}
 
 	// apply custom options
@@ -33,15 +73,16 @@ func New(addr, password string, db int, opts ...Option) (*Redis, error) {
 		opt(r)
 	}
 
-	r.Client = redis.NewClient(&redis.Options{
-		Addr:         addr,
-		Password:     password,
-		DB:           db,
-		PoolSize:     20,
-		MinIdleConns: 5,
-	})
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("redis - NewRedis: no addresses")
+	}
+
+	client, err := r.newClient(addrs)
+	if err != nil {
+		return nil, fmt.Errorf("redis - NewRedis: %w", err)
+	}
+	r.Client = client
 
-	var err error
 	for r.connAttempts > 0 {
 		ctx, cancel := context.WithTimeout(context.Background(), r.connTimeout)
 		defer cancel()
@@ -57,12 +98,59 @@ func New(addr, password string, db int, opts ...Option) (*Redis, error) {
 	}
 
 	if err != nil {
+		r.Client.Close()
 		return nil, fmt.Errorf("redis - NewRedis - connAttempts == 0: %w", err)
 	}
 
+	// Breaker подключается после успешного ping, чтобы попытки подключения его не открыли.
+	r.Client.AddHook(r.breaker)
+
 	return r, nil
 }
 
+func (r *Redis) newClient(addrs []string) (redis.UniversalClient, error) {
+	opts := &redis.UniversalOptions{
+		Addrs:        addrs,
+		MasterName:   r.masterName,
+		Username:     r.username,
+		Password:     r.password,
+		DB:           r.db,
+		PoolSize:     r.poolSize,
+		MinIdleConns: defaultMinIdleConns,
+		DialTimeout:  r.connTimeout,
+		ReadTimeout:  r.commandTimeout,
+		WriteTimeout: r.commandTimeout,
+		TLSConfig:    r.tlsConfig,
+
+		SentinelUsername: r.sentinelUsername,
+		SentinelPassword: r.sentinelPassword,
+	}
+
+	switch r.mode {
+	case ModeStandalone, "":
+		return redis.NewClient(opts.Simple()), nil
+	case ModeSentinel:
+		if r.masterName == "" {
+			return nil, fmt.Errorf("sentinel mode requires master name")
+		}
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case ModeCluster:
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("unknown mode %q", r.mode)
+	}
+}
+
+// BreakerState возвращает текущее состояние circuit breaker.
+func (r *Redis) BreakerState() BreakerState {
+	return r.breaker.State()
+}
+
+// Ping проверяет доступность Redis. При разомкнутом circuit breaker сразу возвращает ErrCircuitOpen.
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.Client.Ping(ctx).Err()
+}
+
 func (r *Redis) Close() error {
 	if r.Client != nil {
 		return r.Client.Close()
@@ -88,6 +176,29 @@ func (r *Redis) Get(ctx context.Context, key string) (string, error) {
 	return r.Client.Get(ctx, r.key(key)).Result()
 }
 
+// GetWithTTL возвращает значение и оставшийся TTL ключа одним запросом.
+// Для ключа без TTL возвращается отрицательная длительность (см. PTTL).
+func (r *Redis) GetWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	var (
+		get *redis.StringCmd
+		ttl *redis.DurationCmd
+	)
+	_, err := r.Client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		get = p.Get(ctx, r.key(key))
+		ttl = p.PTTL(ctx, r.key(key))
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return get.Val(), ttl.Val(), nil
+}
+
+// SetNX записывает значение, только если ключа нет. Возвращает true, если значение записано.
+func (r *Redis) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return r.Client.SetNX(ctx, r.key(key), value, ttl).Result()
+}
+
 func (r *Redis) Exists(ctx context.Context, key string) (bool, error) {
 	n, err := r.Client.Exists(ctx, r.key(key)).Result()
 	return n == 1, err
@@ -0,0 +1,163 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	SourceKafka  = "kafka"
+	SourceOutbox = "outbox"
+)
+
+// Target — консьюмер сервиса, в обработчик которого можно прогнать события.
+type Target struct {
+	// Topic — топик, который читает консьюмер; используется по умолчанию для -topic.
+	Topic   string
+	Handler kafka.BatchHandler
+}
+
+// Command — CLI повторного прогона событий. Каждый сервис заполняет Targets своими
+// консьюмерами и вызывает Run с аргументами командной строки.
+type Command struct {
+	Brokers []string
+	// OutboxURL — строка подключения к БД order-service для -source=outbox по умолчанию.
+	OutboxURL string
+	Targets   map[string]Target
+}
+
+// Run разбирает аргументы, прогоняет события и печатает итог.
+// Возвращает ошибку, если хотя бы одно событие не удалось обработать.
+func (c *Command) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+
+	var (
+		consumer  = fs.String("consumer", "", "consumer to replay into: "+strings.Join(c.targetNames(), ", "))
+		source    = fs.String("source", SourceKafka, "event source: kafka or outbox")
+		topic     = fs.String("topic", "", "topic to read (default: consumer topic)")
+		fromFlag  = fs.String("from", "", "start of the time range, RFC3339 (required)")
+		toFlag    = fs.String("to", "", "end of the time range, RFC3339 (default: now)")
+		types     = fs.String("types", "", "comma-separated event types to replay (default: all)")
+		dryRun    = fs.Bool("dry-run", false, "only log events, do not call the handler")
+		rate      = fs.Float64("rate", 0, "max events per second (0 - unlimited)")
+		batch     = fs.Int("batch", defaultBatchSize, "events per handler call")
+		outboxURL = fs.String("outbox-url", c.OutboxURL, "order-service postgres url for -source=outbox")
+		outboxTbl = fs.String("outbox-table", "", "outbox table for -source=outbox (default: outbox)")
+	)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	target, ok := c.Targets[*consumer]
+	if !ok {
+		return fmt.Errorf("replay - Command.Run: unknown consumer %q, expected one of: %s",
+			*consumer, strings.Join(c.targetNames(), ", "))
+	}
+	if *topic == "" {
+		*topic = target.Topic
+	}
+
+	from, err := time.Parse(time.RFC3339, *fromFlag)
+	if err != nil {
+		return fmt.Errorf("replay - Command.Run - -from: %w", err)
+	}
+	to := time.Now().UTC()
+	if *toFlag != "" {
+		if to, err = time.Parse(time.RFC3339, *toFlag); err != nil {
+			return fmt.Errorf("replay - Command.Run - -to: %w", err)
+		}
+	}
+	if !from.Before(to) {
+		return errors.New("replay - Command.Run: -from must be before -to")
+	}
+
+	src, err := c.openSource(ctx, *source, *topic, *outboxURL, *outboxTbl, from, to)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	opts := []Option{DryRun(*dryRun), Rate(*rate), BatchSize(*batch)}
+	if *types != "" {
+		opts = append(opts, EventTypes(strings.Split(*types, ",")...))
+	}
+
+	logrus.Infof("Replay: consumer=%s source=%s topic=%s from=%s to=%s dry-run=%t",
+		*consumer, *source, *topic, from.Format(time.RFC3339), to.Format(time.RFC3339), *dryRun)
+
+	stats, err := New(target.Handler, opts...).Run(ctx, src)
+
+	logrus.Infof("Replay: read=%d matched=%d handled=%d failed=%d",
+		stats.Read, stats.Matched, stats.Handled, stats.Failed)
+
+	if err != nil {
+		return fmt.Errorf("replay - Command.Run: %w", err)
+	}
+	if stats.Failed > 0 {
+		return fmt.Errorf("replay - Command.Run: %d events failed", stats.Failed)
+	}
+
+	return nil
+}
+
+func (c *Command) openSource(ctx context.Context, source, topic, outboxURL, outboxTable string, from, to time.Time) (Source, error) {
+	switch source {
+	case SourceKafka:
+		return NewKafkaSource(ctx, c.Brokers, topic, from, to)
+
+	case SourceOutbox:
+		if outboxURL == "" {
+			return nil, errors.New("replay - Command.Run: -outbox-url is required for -source=outbox")
+		}
+
+		pg, err := postgres.New(outboxURL, postgres.ConnAttempts(3))
+		if err != nil {
+			return nil, fmt.Errorf("replay - Command.Run - postgres.New: %w", err)
+		}
+
+		src, err := NewOutboxSource(ctx, outbox.NewStore(pg, outbox.StoreTable(outboxTable)), topic, from, to, schema.Default())
+		if err != nil {
+			pg.Close()
+			return nil, err
+		}
+
+		return &closingSource{Source: src, close: pg.Close}, nil
+
+	default:
+		return nil, fmt.Errorf("replay - Command.Run: unknown source %q", source)
+	}
+}
+
+func (c *Command) targetNames() []string {
+	names := make([]string, 0, len(c.Targets))
+	for name := range c.Targets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// closingSource закрывает дополнительный ресурс (пул соединений) вместе с источником.
+type closingSource struct {
+	Source
+	close func()
+}
+
+func (s *closingSource) Close() error {
+	err := s.Source.Close()
+	s.close()
+	return err
+}
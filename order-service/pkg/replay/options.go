@@ -0,0 +1,40 @@
+package replay
+
+// Option -.
+type Option func(*Replayer)
+
+// DryRun включает режим, в котором события только логируются, но не передаются обработчику.
+func DryRun(enabled bool) Option {
+	return func(r *Replayer) {
+		r.dryRun = enabled
+	}
+}
+
+// Rate ограничивает скорость прогона (событий в секунду). 0 — без ограничения.
+func Rate(perSecond float64) Option {
+	return func(r *Replayer) {
+		r.rate = perSecond
+	}
+}
+
+// EventTypes оставляет только события указанных типов. Без типов прогоняются все события.
+func EventTypes(types ...string) Option {
+	return func(r *Replayer) {
+		if len(types) == 0 {
+			return
+		}
+		r.eventTypes = make(map[string]bool, len(types))
+		for _, t := range types {
+			r.eventTypes[t] = true
+		}
+	}
+}
+
+// BatchSize задаёт размер пачки, передаваемой обработчику.
+func BatchSize(n int) Option {
+	return func(r *Replayer) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package replay повторно прогоняет исторические события через обработчик консьюмера,
+// чтобы пересобрать состояние downstream-сервиса (например, аналитики после бага).
+//
+// Источники событий:
+//   - KafkaSource — сообщения топика за интервал времени (по offset, найденным по времени);
+//   - OutboxSource — события, заново собранные из outbox-таблицы order-service.
+//
+// Replay безопасен только для обработчиков, идемпотентных по eventId (как analytics:
+// order_events.event_id уникален). Outbox публикует события с eventId = id записи,
+// поэтому события из обоих источников имеют одинаковые eventId.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultBatchSize = 100
+
+// Source — источник событий для повторной обработки.
+// Next возвращает io.EOF, когда события закончились.
+type Source interface {
+	Next(ctx context.Context) (kafka.Message, error)
+	Close() error
+}
+
+// Stats — итог прогона.
+type Stats struct {
+	// Read — сколько сообщений прочитано из источника.
+	Read int
+	// Matched — сколько из них прошло фильтр по типу события.
+	Matched int
+	// Handled — сколько успешно обработано (в dry-run всегда 0).
+	Handled int
+	// Failed — сколько не удалось разобрать или обработать.
+	Failed int
+}
+
+// Replayer передаёт события из Source в обработчик консьюмера.
+type Replayer struct {
+	handler    kafka.BatchHandler
+	dryRun     bool
+	rate       float64
+	eventTypes map[string]bool
+	batchSize  int
+}
+
+// New создаёт Replayer, передающий события в handler.
+func New(handler kafka.BatchHandler, opts ...Option) *Replayer {
+	r := &Replayer{
+		handler:   handler,
+		batchSize: defaultBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Run читает src до конца и передаёт подходящие события обработчику пачками.
+// Ошибки обработчика не прерывают прогон: они логируются и учитываются в Stats.Failed.
+func (r *Replayer) Run(ctx context.Context, src Source) (Stats, error) {
+	var (
+		stats   Stats
+		batch   []kafka.Message
+		limiter = newLimiter(r.rate)
+	)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		if err := r.handler(ctx, batch); err != nil {
+			logrus.Errorf("Replayer: handler failed for batch of %d events: %v", len(batch), err)
+			stats.Failed += len(batch)
+		} else {
+			stats.Handled += len(batch)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		m, err := src.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			flush()
+			return stats, err
+		}
+		stats.Read++
+
+		var env kafka.Envelope
+		if err := json.Unmarshal(m.Value, &env); err != nil {
+			logrus.Errorf("Replayer: skip invalid envelope at %s/%d/%d: %v", m.Topic, m.Partition, m.Offset, err)
+			stats.Failed++
+			continue
+		}
+
+		if len(r.eventTypes) > 0 && !r.eventTypes[env.EventType] {
+			continue
+		}
+		stats.Matched++
+
+		if err := limiter.wait(ctx); err != nil {
+			flush()
+			return stats, err
+		}
+
+		if r.dryRun {
+			logrus.Infof("Replayer (dry-run): eventType=%s eventId=%s occurredAt=%s",
+				env.EventType, env.EventID, env.OccurredAt.Format(time.RFC3339))
+			continue
+		}
+
+		batch = append(batch, m)
+		if len(batch) >= r.batchSize {
+			flush()
+		}
+	}
+
+	flush()
+
+	return stats, nil
+}
+
+// limiter ограничивает число событий в секунду.
+type limiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+func newLimiter(perSecond float64) *limiter {
+	if perSecond <= 0 {
+		return &limiter{}
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l.interval == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if l.next.After(now) {
+		timer := time.NewTimer(l.next.Sub(now))
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		now = l.next
+	}
+	l.next = now.Add(l.interval)
+
+	return nil
+}
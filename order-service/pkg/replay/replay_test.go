@@ -0,0 +1,102 @@
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/replay"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// sliceSource отдаёт заранее заданные сообщения.
+type sliceSource struct {
+	msgs []kafka.Message
+}
+
+func (s *sliceSource) Next(context.Context) (kafka.Message, error) {
+	if len(s.msgs) == 0 {
+		return kafka.Message{}, io.EOF
+	}
+	m := s.msgs[0]
+	s.msgs = s.msgs[1:]
+	return m, nil
+}
+
+func (s *sliceSource) Close() error { return nil }
+
+func message(t *testing.T, eventType string, ev events.Event) kafka.Message {
+	t.Helper()
+
+	value, err := kafka.EncodeEnvelope(schema.Default(), uuid.New(), eventType, time.Now().UTC(), ev)
+	require.NoError(t, err)
+
+	return kafka.Message{Topic: "order.events", Value: value}
+}
+
+func newSource(t *testing.T) *sliceSource {
+	orderID := uuid.New()
+
+	return &sliceSource{msgs: []kafka.Message{
+		message(t, events.TypeOrderCreated, events.OrderCreated{OrderID: orderID, UserID: uuid.New(), TotalPrice: 100}),
+		message(t, events.TypeOrderPaid, events.OrderPaid{OrderID: orderID, PaymentID: uuid.New()}),
+		{Topic: "order.events", Value: []byte("not json")},
+		message(t, events.TypeOrderCompleted, events.OrderCompleted{OrderID: orderID}),
+	}}
+}
+
+func TestReplayer_Run(t *testing.T) {
+	ctx := context.Background()
+
+	var batches [][]kafka.Message
+	handler := func(_ context.Context, msgs []kafka.Message) error {
+		batches = append(batches, append([]kafka.Message(nil), msgs...))
+		return nil
+	}
+
+	stats, err := replay.New(handler, replay.BatchSize(2)).Run(ctx, newSource(t))
+	require.NoError(t, err)
+	require.Equal(t, replay.Stats{Read: 4, Matched: 3, Handled: 3, Failed: 1}, stats)
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0], 2)
+	require.Len(t, batches[1], 1)
+}
+
+func TestReplayer_FilterAndDryRun(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	handler := func(context.Context, []kafka.Message) error {
+		calls++
+		return nil
+	}
+
+	stats, err := replay.New(handler, replay.EventTypes(events.TypeOrderPaid)).Run(ctx, newSource(t))
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.Matched)
+	require.Equal(t, 1, stats.Handled)
+	require.Equal(t, 1, calls)
+
+	stats, err = replay.New(handler, replay.DryRun(true)).Run(ctx, newSource(t))
+	require.NoError(t, err)
+	require.Equal(t, 3, stats.Matched)
+	require.Equal(t, 0, stats.Handled)
+	require.Equal(t, 1, calls)
+}
+
+func TestReplayer_HandlerError(t *testing.T) {
+	handler := func(context.Context, []kafka.Message) error {
+		return errors.New("boom")
+	}
+
+	stats, err := replay.New(handler).Run(context.Background(), newSource(t))
+	require.NoError(t, err)
+	require.Equal(t, 0, stats.Handled)
+	require.Equal(t, 4, stats.Failed)
+}
@@ -0,0 +1,209 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// KafkaSource читает сообщения топика, опубликованные в интервале [from, to).
+// Партиции читаются по очереди без consumer group, поэтому offset групп не меняются.
+type KafkaSource struct {
+	brokers    []string
+	topic      string
+	from, to   time.Time
+	partitions []int
+
+	// current — индекс читаемой партиции в partitions.
+	current int
+	reader  *kafkago.Reader
+	// end — offset, следующий за последним сообщением партиции на момент начала чтения.
+	end int64
+}
+
+// NewKafkaSource находит партиции топика. Чтение начинается при первом вызове Next.
+func NewKafkaSource(ctx context.Context, brokers []string, topic string, from, to time.Time) (*KafkaSource, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("replay - NewKafkaSource: no brokers")
+	}
+
+	conn, err := kafkago.DialContext(ctx, "tcp", brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("replay - NewKafkaSource - dial: %w", err)
+	}
+	defer conn.Close()
+
+	parts, err := conn.ReadPartitions(topic)
+	if err != nil {
+		return nil, fmt.Errorf("replay - NewKafkaSource - read partitions: %w", err)
+	}
+
+	s := &KafkaSource{brokers: brokers, topic: topic, from: from, to: to}
+	for _, p := range parts {
+		s.partitions = append(s.partitions, p.ID)
+	}
+
+	return s, nil
+}
+
+func (s *KafkaSource) Next(ctx context.Context) (kafka.Message, error) {
+	for {
+		if s.reader == nil {
+			if s.current >= len(s.partitions) {
+				return kafka.Message{}, io.EOF
+			}
+			empty, err := s.open(ctx, s.partitions[s.current])
+			if err != nil {
+				return kafka.Message{}, err
+			}
+			if empty {
+				s.nextPartition()
+				continue
+			}
+		}
+
+		m, err := s.reader.ReadMessage(ctx)
+		if err != nil {
+			return kafka.Message{}, fmt.Errorf("replay - KafkaSource - partition %d: %w", s.partitions[s.current], err)
+		}
+
+		if !m.Time.Before(s.to) {
+			// Сообщения партиции упорядочены по времени записи — дальше только более поздние.
+			s.nextPartition()
+			continue
+		}
+
+		if m.Offset+1 >= s.end {
+			s.nextPartition()
+		}
+
+		return kafka.Message{
+			Topic:     m.Topic,
+			Partition: m.Partition,
+			Offset:    m.Offset,
+			Key:       m.Key,
+			Value:     m.Value,
+			Time:      m.Time,
+		}, nil
+	}
+}
+
+func (s *KafkaSource) Close() error {
+	if s.reader != nil {
+		return s.reader.Close()
+	}
+	return nil
+}
+
+// open открывает reader партиции с первого сообщения не раньше from.
+// Возвращает true, если в партиции нет сообщений за интервал.
+func (s *KafkaSource) open(ctx context.Context, partition int) (bool, error) {
+	leader, err := kafkago.DialLeader(ctx, "tcp", s.brokers[0], s.topic, partition)
+	if err != nil {
+		return false, fmt.Errorf("replay - KafkaSource - dial leader %d: %w", partition, err)
+	}
+	defer leader.Close()
+
+	start, err := leader.ReadOffset(s.from)
+	if err != nil {
+		return false, fmt.Errorf("replay - KafkaSource - offset at %s: %w", s.from, err)
+	}
+
+	s.end, err = leader.ReadLastOffset()
+	if err != nil {
+		return false, fmt.Errorf("replay - KafkaSource - last offset %d: %w", partition, err)
+	}
+
+	if start < 0 || start >= s.end {
+		return true, nil
+	}
+
+	s.reader = kafkago.NewReader(kafkago.ReaderConfig{
+		Brokers:   s.brokers,
+		Topic:     s.topic,
+		Partition: partition,
+		MaxBytes:  10e6,
+	})
+
+	if err := s.reader.SetOffset(start); err != nil {
+		return false, fmt.Errorf("replay - KafkaSource - set offset: %w", err)
+	}
+
+	return false, nil
+}
+
+func (s *KafkaSource) nextPartition() {
+	if s.reader != nil {
+		_ = s.reader.Close()
+		s.reader = nil
+	}
+	s.current++
+}
+
+// OutboxSource заново собирает processed-события из outbox-таблицы и её архива
+// (см. outbox.Retention) за интервал [from, to) по created_at. eventId события — id outbox-записи, occurredAt — created_at,
+// ключ — id агрегата, как при обычной публикации через outbox.Worker.
+type OutboxSource struct {
+	rows    pgx.Rows
+	topic   string
+	schemas *schema.Registry
+}
+
+// NewOutboxSource выполняет выборку из таблиц store. Payload проверяется по реестру schemas.
+func NewOutboxSource(ctx context.Context, store *outbox.Store, topic string, from, to time.Time, schemas *schema.Registry) (*OutboxSource, error) {
+	rows, err := store.QueryProcessed(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("replay - NewOutboxSource: %w", err)
+	}
+
+	return &OutboxSource{rows: rows, topic: topic, schemas: schemas}, nil
+}
+
+func (s *OutboxSource) Next(ctx context.Context) (kafka.Message, error) {
+	if !s.rows.Next() {
+		if err := s.rows.Err(); err != nil {
+			return kafka.Message{}, fmt.Errorf("replay - OutboxSource: %w", err)
+		}
+		return kafka.Message{}, io.EOF
+	}
+
+	var (
+		id            uuid.UUID
+		aggregateType string
+		aggregateID   uuid.UUID
+		eventType     string
+		payload       json.RawMessage
+		occurredAt    time.Time
+	)
+	if err := s.rows.Scan(&id, &aggregateType, &aggregateID, &eventType, &payload, &occurredAt); err != nil {
+		return kafka.Message{}, fmt.Errorf("replay - OutboxSource - scan: %w", err)
+	}
+	eventType = outbox.FullEventType(aggregateType, eventType)
+
+	value, err := kafka.EncodeEnvelope(s.schemas, id, eventType, occurredAt, payload)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("replay - OutboxSource - event %s: %w", id, err)
+	}
+
+	return kafka.Message{
+		Topic: s.topic,
+		Key:   []byte(aggregateID.String()),
+		Value: value,
+		Time:  occurredAt,
+	}, nil
+}
+
+func (s *OutboxSource) Close() error {
+	s.rows.Close()
+	return nil
+}
@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// files — JSON Schema всех событий из docs/EVENT_CATALOG.md.
+// Имя файла: <eventType>.v<version>.json, например order.created.v1.json.
+//
+//go:embed schemas/*.json
+var files embed.FS
+
+var fileNameRe = regexp.MustCompile(`^(.+)\.v([0-9]+)\.json$`)
+
+// upcasters — преобразования payload встроенных схем между соседними версиями.
+// Ключ — тип события, вложенный ключ — исходная версия.
+// При выпуске <eventType>.v<N+1>.json сюда добавляется апкастер из версии N.
+var upcasters = map[string]map[int]Upcaster{}
+
+var (
+	defaultOnce     sync.Once
+	defaultRegistry *Registry
+)
+
+// Default возвращает общий реестр, заполненный встроенными схемами каталога событий.
+// Реестр создаётся один раз; встроенные схемы проверяются тестами, поэтому
+// ошибка их загрузки считается ошибкой сборки и приводит к панике.
+func Default() *Registry {
+	defaultOnce.Do(func() {
+		reg, err := Load(files, "schemas")
+		if err != nil {
+			panic(err)
+		}
+		for eventType, byVersion := range upcasters {
+			for from, fn := range byVersion {
+				reg.RegisterUpcaster(eventType, from, fn)
+			}
+		}
+		defaultRegistry = reg
+	})
+	return defaultRegistry
+}
+
+// Load создаёт реестр из каталога dir файловой системы fsys.
+// Файлы, не подходящие под шаблон <eventType>.v<version>.json, пропускаются.
+func Load(fsys fs.FS, dir string) (*Registry, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("schema - Load - read dir %s: %w", dir, err)
+	}
+
+	reg := NewRegistry()
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		m := fileNameRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("schema - Load - %s: invalid version: %w", e.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("schema - Load - read %s: %w", e.Name(), err)
+		}
+
+		if err := reg.Register(m[1], version, data); err != nil {
+			return nil, err
+		}
+	}
+
+	return reg, nil
+}
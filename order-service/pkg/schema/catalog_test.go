@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const catalogPath = "../../../docs/EVENT_CATALOG.md"
+
+var (
+	eventHeadingRe = regexp.MustCompile("(?m)^## Event: `([^`]+)`")
+	jsonBlockRe    = regexp.MustCompile("(?s)```json\\s*\\n(.*?)```")
+)
+
+// parseCatalog возвращает поля примера payload для каждого события из EVENT_CATALOG.md.
+func parseCatalog(t *testing.T) map[string][]string {
+	t.Helper()
+
+	raw, err := os.ReadFile(catalogPath)
+	require.NoError(t, err)
+	doc := string(raw)
+
+	headings := eventHeadingRe.FindAllStringSubmatchIndex(doc, -1)
+	require.NotEmpty(t, headings, "no events found in catalog")
+
+	events := make(map[string][]string, len(headings))
+	for i, h := range headings {
+		name := doc[h[2]:h[3]]
+
+		end := len(doc)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+
+		block := jsonBlockRe.FindStringSubmatch(doc[h[1]:end])
+		require.NotNil(t, block, "event %s has no json payload example", name)
+
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal([]byte(block[1]), &payload), "event %s: invalid json example", name)
+
+		events[name] = sortedKeys(payload)
+	}
+
+	return events
+}
+
+// latestSchemaProperties возвращает поля последней версии каждой встроенной схемы.
+func latestSchemaProperties(t *testing.T) map[string][]string {
+	t.Helper()
+
+	reg := Default()
+	props := make(map[string][]string)
+
+	for _, eventType := range reg.EventTypes() {
+		latest, err := reg.Latest(eventType)
+		require.NoError(t, err)
+
+		fields, err := reg.Fields(eventType, latest)
+		require.NoError(t, err)
+
+		props[eventType] = fields
+	}
+
+	return props
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestCatalogMatchesSchemas(t *testing.T) {
+	catalog := parseCatalog(t)
+	schemas := latestSchemaProperties(t)
+
+	require.Equal(t, sortedKeys(catalog), sortedKeys(schemas),
+		"event types in EVENT_CATALOG.md and pkg/schema/schemas differ")
+
+	for eventType, fields := range catalog {
+		require.Equal(t, schemas[eventType], fields,
+			"payload fields of %s differ between EVENT_CATALOG.md and its latest schema", eventType)
+	}
+}
+
+func TestCatalogEnvelopeHasSchemaVersion(t *testing.T) {
+	raw, err := os.ReadFile(catalogPath)
+	require.NoError(t, err)
+
+	require.True(t, strings.Contains(string(raw), `"schemaVersion"`), "envelope example must contain schemaVersion")
+	require.True(t, strings.Contains(string(raw), `"occurredAt"`), "envelope example must contain occurredAt")
+}
@@ -0,0 +1,226 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+var (
+	ErrUnknownEventType = errors.New("schema: unknown event type")
+	ErrUnknownVersion   = errors.New("schema: unknown schema version")
+	ErrNoUpcaster       = errors.New("schema: no upcaster registered")
+	ErrInvalidPayload   = errors.New("schema: payload does not match schema")
+)
+
+// Upcaster преобразует payload события из версии N в версию N+1.
+// Используется консьюмерами, чтобы обрабатывать старые сообщения как актуальные.
+type Upcaster func(data json.RawMessage) (json.RawMessage, error)
+
+// Registry хранит JSON Schema для каждой пары (eventType, version)
+// и цепочки апкастеров между соседними версиями.
+//
+// Registry безопасен для конкурентного использования.
+type Registry struct {
+	mu        sync.RWMutex
+	schemas   map[string]map[int]*jsonschema.Schema
+	fields    map[string]map[int][]string
+	upcasters map[string]map[int]Upcaster
+}
+
+// NewRegistry создаёт пустой реестр схем.
+// Для реестра со схемами из каталога событий используйте Default.
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas:   make(map[string]map[int]*jsonschema.Schema),
+		fields:    make(map[string]map[int][]string),
+		upcasters: make(map[string]map[int]Upcaster),
+	}
+}
+
+// Register компилирует JSON Schema и регистрирует её для eventType и version.
+func (r *Registry) Register(eventType string, version int, schemaJSON []byte) error {
+	if version < 1 {
+		return fmt.Errorf("schema - Register - %s: version must be >= 1, got %d", eventType, version)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schemaJSON))
+	if err != nil {
+		return fmt.Errorf("schema - Register - %s v%d: invalid json: %w", eventType, version, err)
+	}
+
+	url := fmt.Sprintf("mem://events/%s.v%d.json", eventType, version)
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+	if err := compiler.AddResource(url, doc); err != nil {
+		return fmt.Errorf("schema - Register - %s v%d: %w", eventType, version, err)
+	}
+
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return fmt.Errorf("schema - Register - %s v%d: compile: %w", eventType, version, err)
+	}
+
+	var top struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(schemaJSON, &top); err != nil {
+		return fmt.Errorf("schema - Register - %s v%d: properties: %w", eventType, version, err)
+	}
+
+	fields := make([]string, 0, len(top.Properties))
+	for name := range top.Properties {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.schemas[eventType] == nil {
+		r.schemas[eventType] = make(map[int]*jsonschema.Schema)
+		r.fields[eventType] = make(map[int][]string)
+	}
+	r.schemas[eventType][version] = compiled
+	r.fields[eventType][version] = fields
+
+	return nil
+}
+
+// RegisterUpcaster регистрирует преобразование payload из версии fromVersion в fromVersion+1.
+func (r *Registry) RegisterUpcaster(eventType string, fromVersion int, fn Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.upcasters[eventType] == nil {
+		r.upcasters[eventType] = make(map[int]Upcaster)
+	}
+	r.upcasters[eventType][fromVersion] = fn
+}
+
+// Latest возвращает последнюю зарегистрированную версию схемы события.
+func (r *Registry) Latest(eventType string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.schemas[eventType]
+	if !ok || len(versions) == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+
+	latest := 0
+	for v := range versions {
+		if v > latest {
+			latest = v
+		}
+	}
+
+	return latest, nil
+}
+
+// Fields возвращает отсортированный список полей верхнего уровня (properties) схемы.
+func (r *Registry) Fields(eventType string, version int) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.fields[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+
+	fields, ok := versions[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnknownVersion, eventType, version)
+	}
+
+	return append([]string(nil), fields...), nil
+}
+
+// EventTypes возвращает отсортированный список зарегистрированных типов событий.
+func (r *Registry) EventTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.schemas))
+	for t := range r.schemas {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// Validate проверяет payload события на соответствие схеме указанной версии.
+func (r *Registry) Validate(eventType string, version int, data []byte) error {
+	r.mu.RLock()
+	versions, ok := r.schemas[eventType]
+	var compiled *jsonschema.Schema
+	if ok {
+		compiled = versions[version]
+	}
+	r.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+	if compiled == nil {
+		return fmt.Errorf("%w: %s v%d", ErrUnknownVersion, eventType, version)
+	}
+
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %s v%d: invalid json: %v", ErrInvalidPayload, eventType, version, err)
+	}
+
+	if err := compiled.Validate(inst); err != nil {
+		return fmt.Errorf("%w: %s v%d: %v", ErrInvalidPayload, eventType, version, err)
+	}
+
+	return nil
+}
+
+// Upcast проверяет payload по схеме его версии, последовательно применяет апкастеры
+// до последней версии и проверяет результат по актуальной схеме.
+// Возвращает преобразованный payload и его новую версию.
+func (r *Registry) Upcast(eventType string, version int, data json.RawMessage) (json.RawMessage, int, error) {
+	if err := r.Validate(eventType, version, data); err != nil {
+		return nil, 0, err
+	}
+
+	latest, err := r.Latest(eventType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if version == latest {
+		return data, version, nil
+	}
+
+	for version < latest {
+		r.mu.RLock()
+		fn := r.upcasters[eventType][version]
+		r.mu.RUnlock()
+
+		if fn == nil {
+			return nil, 0, fmt.Errorf("%w: %s v%d -> v%d", ErrNoUpcaster, eventType, version, version+1)
+		}
+
+		data, err = fn(data)
+		if err != nil {
+			return nil, 0, fmt.Errorf("schema - Upcast - %s v%d -> v%d: %w", eventType, version, version+1, err)
+		}
+		version++
+	}
+
+	if err := r.Validate(eventType, latest, data); err != nil {
+		return nil, 0, err
+	}
+
+	return data, latest, nil
+}
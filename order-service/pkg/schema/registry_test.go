@@ -0,0 +1,129 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSchemaV1 = `{
+		"type": "object",
+		"properties": {"orderId": {"type": "string", "format": "uuid"}, "price": {"type": "number"}},
+		"required": ["orderId", "price"],
+		"additionalProperties": false
+	}`
+	testSchemaV2 = `{
+		"type": "object",
+		"properties": {"orderId": {"type": "string", "format": "uuid"}, "totalPrice": {"type": "number"}},
+		"required": ["orderId", "totalPrice"],
+		"additionalProperties": false
+	}`
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+
+	reg := NewRegistry()
+	require.NoError(t, reg.Register("test.event", 1, []byte(testSchemaV1)))
+	require.NoError(t, reg.Register("test.event", 2, []byte(testSchemaV2)))
+	reg.RegisterUpcaster("test.event", 1, func(data json.RawMessage) (json.RawMessage, error) {
+		var v1 struct {
+			OrderID string  `json:"orderId"`
+			Price   float64 `json:"price"`
+		}
+		if err := json.Unmarshal(data, &v1); err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{"orderId": v1.OrderID, "totalPrice": v1.Price})
+	})
+
+	return reg
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	tests := []struct {
+		name      string
+		eventType string
+		version   int
+		payload   string
+		wantErr   error
+	}{
+		{
+			name:      "valid",
+			eventType: "test.event",
+			version:   2,
+			payload:   `{"orderId": "0b8f4a52-8a0b-4c1e-9d36-0d6f1f0c6a11", "totalPrice": 10}`,
+		},
+		{
+			name:      "missing required field",
+			eventType: "test.event",
+			version:   2,
+			payload:   `{"orderId": "0b8f4a52-8a0b-4c1e-9d36-0d6f1f0c6a11"}`,
+			wantErr:   ErrInvalidPayload,
+		},
+		{
+			name:      "unexpected field",
+			eventType: "test.event",
+			version:   2,
+			payload:   `{"orderId": "0b8f4a52-8a0b-4c1e-9d36-0d6f1f0c6a11", "totalPrice": 10, "userId": "x"}`,
+			wantErr:   ErrInvalidPayload,
+		},
+		{
+			name:      "invalid uuid",
+			eventType: "test.event",
+			version:   2,
+			payload:   `{"orderId": "not-a-uuid", "totalPrice": 10}`,
+			wantErr:   ErrInvalidPayload,
+		},
+		{
+			name:      "unknown version",
+			eventType: "test.event",
+			version:   3,
+			payload:   `{}`,
+			wantErr:   ErrUnknownVersion,
+		},
+		{
+			name:      "unknown event type",
+			eventType: "other.event",
+			version:   1,
+			payload:   `{}`,
+			wantErr:   ErrUnknownEventType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := reg.Validate(tt.eventType, tt.version, []byte(tt.payload))
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRegistry_Upcast(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	data, version, err := reg.Upcast("test.event", 1,
+		json.RawMessage(`{"orderId": "0b8f4a52-8a0b-4c1e-9d36-0d6f1f0c6a11", "price": 42.5}`))
+	require.NoError(t, err)
+	require.Equal(t, 2, version)
+	require.JSONEq(t, `{"orderId": "0b8f4a52-8a0b-4c1e-9d36-0d6f1f0c6a11", "totalPrice": 42.5}`, string(data))
+
+	_, _, err = reg.Upcast("test.event", 1, json.RawMessage(`{"orderId": "0b8f4a52-8a0b-4c1e-9d36-0d6f1f0c6a11"}`))
+	require.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestDefault_LoadsCatalog(t *testing.T) {
+	reg := Default()
+
+	require.Contains(t, reg.EventTypes(), "order.created")
+	require.NoError(t, reg.Validate("order.created", 1,
+		[]byte(`{"orderId": "0b8f4a52-8a0b-4c1e-9d36-0d6f1f0c6a11", "userId": "6f1b8a20-3c55-4d7e-8f0a-2b9c1d3e4f5a", "totalPrice": 100}`)))
+}
@@ -2,7 +2,64 @@ package transactor
 
 import "context"
 
-//go:generate go tool mockgen -source=transactor.go -destination=../../internal/mocks/mock_transactor.go
+//go:generate go tool mockgen -source=transactor.go -destination=../../internal/mocks/mock_transactor.go -package=mocks
 type Transactor interface {
-	WithinTransaction(ctx context.Context, fn func(context.Context) error) error
+	// WithinTransaction выполняет fn в транзакции. Вызов внутри другой транзакции
+	// открывает savepoint: ошибка fn откатывает только его, а не внешнюю транзакцию.
+	WithinTransaction(ctx context.Context, fn func(context.Context) error, opts ...Option) error
+}
+
+// IsolationLevel — уровень изоляции транзакции. Пустое значение — уровень по умолчанию базы.
+type IsolationLevel string
+
+const (
+	ReadCommitted  IsolationLevel = "read committed"
+	RepeatableRead IsolationLevel = "repeatable read"
+	Serializable   IsolationLevel = "serializable"
+)
+
+// Options — параметры одного вызова WithinTransaction.
+// Для вложенного вызова (savepoint) действуют параметры внешней транзакции, опции игнорируются.
+type Options struct {
+	Isolation IsolationLevel
+	ReadOnly  bool
+	// MaxRetries — сколько раз транзакция перезапускается целиком после
+	// serialization failure (40001) или deadlock (40P01). 0 — без повторов.
+	MaxRetries int
+}
+
+// Option -.
+type Option func(*Options)
+
+// Isolation задаёт уровень изоляции транзакции.
+func Isolation(level IsolationLevel) Option {
+	return func(o *Options) {
+		o.Isolation = level
+	}
+}
+
+// ReadOnly открывает транзакцию только для чтения.
+func ReadOnly() Option {
+	return func(o *Options) {
+		o.ReadOnly = true
+	}
+}
+
+// RetrySerialization включает повтор транзакции при конфликте сериализации и deadlock.
+// fn должна быть безопасна для повторного выполнения: побочные эффекты вне базы повторятся.
+func RetrySerialization(maxRetries int) Option {
+	return func(o *Options) {
+		if maxRetries > 0 {
+			o.MaxRetries = maxRetries
+		}
+	}
+}
+
+// Apply собирает Options из списка опций.
+func Apply(opts ...Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
 }
@@ -0,0 +1,106 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError — ошибка одного поля запроса. Field — имя поля из json-тега, путь вложенных
+// полей — через точку: "items[0].productId".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationError содержит ошибки всех невалидных полей, а не только первого.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// CustomValidator — echo.Validator поверх go-playground/validator, имена полей берутся из json-тегов.
+type CustomValidator struct {
+	v *validator.Validate
+}
+
+func NewCustomValidator() *CustomValidator {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	return &CustomValidator{v: v}
+}
+
+// Validate возвращает *ValidationError, если структура не прошла валидацию.
+func (cv *CustomValidator) Validate(i any) error {
+	err := cv.v.Struct(i)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	fields := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		field := fieldPath(fe.Namespace())
+		fields = append(fields, FieldError{
+			Field:   field,
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: message(field, fe.Tag(), fe.Param()),
+		})
+	}
+
+	return &ValidationError{Fields: fields}
+}
+
+// fieldPath отрезает от пути имя корневой структуры: "Request.items[0].amount" → "items[0].amount".
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+func message(field, tag, param string) string {
+	switch tag {
+	case "required":
+		return fmt.Sprintf("field %s is required", field)
+	case "len":
+		return fmt.Sprintf("field %s must be %s characters length", field, param)
+	case "uri":
+		return fmt.Sprintf("field %s must be a valid URI", field)
+	case "email":
+		return fmt.Sprintf("field %s must be a valid email address", field)
+	case "min":
+		return fmt.Sprintf("field %s must be at least %s", field, param)
+	case "max":
+		return fmt.Sprintf("field %s must be at most %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("field %s must be one of [%s]", field, param)
+	default:
+		return fmt.Sprintf("field %s is invalid", field)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/4udiwe/big-bob-pizza/order-service/internal/database"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/redis"
 	log "github.com/sirupsen/logrus"
@@ -44,8 +45,11 @@ func TestMain(m *testing.M) {
 	if err := database.RunMigrations(context.Background(), testPostgres.Pool); err != nil {
 		log.Fatalf("failed to run migrations: %v", err)
 	}
+	if err := outbox.NewStore(testPostgres).Migrate(context.Background()); err != nil {
+		log.Fatalf("failed to run outbox migrations: %v", err)
+	}
 
-	testRedis, err = redis.New(redisAddr, "", 0)
+	testRedis, err = redis.New([]string{redisAddr})
 	if err != nil {
 		log.Fatalf("failed to connect to redis: %v", err)
 	}
@@ -0,0 +1,173 @@
+//go:build integration
+
+package integration_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore создаёт Store на отдельных таблицах, чтобы тесты не видели события сервиса
+// и друг друга.
+func newTestStore(t *testing.T) *outbox.Store {
+	t.Helper()
+
+	table := "outbox_it_" + uuid.NewString()[:8]
+	store := outbox.NewStore(testPostgres, outbox.StoreTable(table))
+	require.NoError(t, store.Migrate(context.Background()))
+
+	t.Cleanup(func() {
+		drop := fmt.Sprintf(`
+			DROP TABLE IF EXISTS %[1]s, %[2]s, %[3]s, %[4]s CASCADE;
+			DROP FUNCTION IF EXISTS %[5]s();
+		`,
+			pgx.Identifier{table}.Sanitize(),
+			pgx.Identifier{table + "_status"}.Sanitize(),
+			pgx.Identifier{table + "_archive"}.Sanitize(),
+			pgx.Identifier{table + "_goose_db_version"}.Sanitize(),
+			pgx.Identifier{table + "_notify"}.Sanitize(),
+		)
+		_, err := testPostgres.Pool.Exec(context.Background(), drop)
+		assert.NoError(t, err)
+	})
+
+	return store
+}
+
+func createEvent(t *testing.T, store *outbox.Store, aggregateID uuid.UUID, eventType string) uuid.UUID {
+	t.Helper()
+
+	id, err := store.Create(context.Background(), outbox.Message{
+		AggregateType: "order",
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       map[string]any{"orderId": aggregateID},
+	})
+	require.NoError(t, err)
+
+	return id
+}
+
+func TestOutboxStore_ListAndGetByID_Integration(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	orderA, orderB := uuid.New(), uuid.New()
+	created := createEvent(t, store, orderA, "created")
+	paid := createEvent(t, store, orderA, "paid")
+	createEvent(t, store, orderB, "created")
+
+	// Выборка по агрегату: новые первыми, total — без учёта limit.
+	records, total, err := store.List(ctx, outbox.Filter{AggregateID: &orderA}, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, records, 1)
+	assert.Equal(t, paid, records[0].ID)
+
+	records, total, err = store.List(ctx, outbox.Filter{Status: outbox.StatusPending}, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, records, 3)
+
+	record, err := store.GetByID(ctx, created)
+	require.NoError(t, err)
+	assert.Equal(t, orderA, record.AggregateID)
+	assert.Equal(t, "created", record.EventType)
+	assert.Equal(t, outbox.StatusPending, record.Status)
+	assert.JSONEq(t, fmt.Sprintf(`{"orderId": %q}`, orderA), string(record.Payload))
+
+	_, err = store.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, outbox.ErrEventNotFound)
+}
+
+func TestOutboxStore_RetrySkipRepublish_Integration(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	orderID := uuid.New()
+	dead := createEvent(t, store, orderID, "created")
+	failed := createEvent(t, store, orderID, "paid")
+	processed := createEvent(t, store, orderID, "completed")
+
+	_, err := store.ClaimPending(ctx, "worker", 10, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.MarkDead(ctx, "worker", dead, "no route"))
+	require.NoError(t, store.MarkFailed(ctx, "worker", failed, "broker unavailable", time.Now().Add(time.Hour)))
+	require.NoError(t, store.MarkProcessed(ctx, "worker", []uuid.UUID{processed}))
+
+	all := outbox.Filter{IDs: []uuid.UUID{dead, failed, processed}}
+
+	// Retry трогает только failed и dead и сбрасывает счётчик попыток.
+	n, err := store.Retry(ctx, all)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	for _, id := range []uuid.UUID{dead, failed} {
+		record, err := store.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, outbox.StatusPending, record.Status)
+		assert.Zero(t, record.Attempts)
+		assert.Nil(t, record.NextAttemptAt)
+	}
+
+	record, err := store.GetByID(ctx, processed)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.StatusProcessed, record.Status)
+
+	// Skip не трогает уже отправленные события.
+	n, err = store.Skip(ctx, all)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	record, err = store.GetByID(ctx, dead)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.StatusSkipped, record.Status)
+
+	// Republish возвращает в очередь и отправленные, и пропущенные события.
+	n, err = store.Republish(ctx, all)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	record, err = store.GetByID(ctx, processed)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.StatusPending, record.Status)
+	assert.Nil(t, record.ProcessedAt)
+}
+
+func TestOutboxStore_MarksRequireLease_Integration(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	id := createEvent(t, store, uuid.New(), "created")
+
+	events, err := store.ClaimPending(ctx, "worker-a", 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	// Чужая аренда: отметка не применяется.
+	err = store.MarkProcessed(ctx, "worker-b", []uuid.UUID{id})
+	require.ErrorIs(t, err, outbox.ErrLeaseLost)
+
+	record, err := store.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.StatusPending, record.Status)
+
+	require.NoError(t, store.MarkProcessed(ctx, "worker-a", []uuid.UUID{id}))
+
+	// Истёкшая аренда тоже считается потерянной.
+	expired := createEvent(t, store, uuid.New(), "created")
+	_, err = store.ClaimPending(ctx, "worker-a", 10, 10*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	err = store.MarkFailed(ctx, "worker-a", expired, "broker unavailable", time.Now())
+	require.ErrorIs(t, err, outbox.ErrLeaseLost)
+}
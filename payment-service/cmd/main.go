@@ -1,11 +1,27 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/configloader"
+	"github.com/4udiwe/big-bob-pizza/payment-service/config"
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/app"
+	"github.com/labstack/gommon/log"
 )
 
+const usage = `usage: payment-service [command]
+
+commands:
+  serve [-auto-migrate]          run the service (default)
+  migrate up|down|redo|status    manage the database schema
+  migrate create <name>          create a new SQL migration
+  config print [--redacted]      print the effective configuration, --redacted masks secrets`
+
 // @title Big Bob Pizza - Payment Service API
 // @version 1.0
 // @description API для обработки платежей в системе Big Bob Pizza
@@ -20,7 +36,40 @@ import (
 // @host localhost:8081
 // @BasePath /
 // @schemes http https
+
+// @securityDefinitions.apikey AdminAPIKey
+// @in header
+// @name X-API-Key
 func main() {
-	app := app.New(os.Getenv("CONFIG_PATH"))
-	app.Start()
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		app := app.New(os.Getenv("CONFIG_PATH"))
+		if err := app.Serve(args); err != nil {
+			log.Fatalf("serve: %v", err)
+		}
+	case "migrate":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		app := app.New(os.Getenv("CONFIG_PATH"))
+		if err := app.Migrate(ctx, args); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+	case "config":
+		err := configloader.Command(os.Stdout, args, func() (any, error) {
+			return config.New(os.Getenv("CONFIG_PATH"))
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "config: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", command, usage)
+		os.Exit(2)
+	}
 }
@@ -2,19 +2,23 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
 	"github.com/4udiwe/big-bob-pizza/payment-service/config"
 	consumer_order "github.com/4udiwe/big-bob-pizza/payment-service/internal/consumer/order"
-	"github.com/4udiwe/big-bob-pizza/payment-service/internal/database"
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/handler"
 	order_cache_repository "github.com/4udiwe/big-bob-pizza/payment-service/internal/repository/order_cache"
 	outbox_repository "github.com/4udiwe/big-bob-pizza/payment-service/internal/repository/outbox"
 	payment_repository "github.com/4udiwe/big-bob-pizza/payment-service/internal/repository/payment"
+	outbox_service "github.com/4udiwe/big-bob-pizza/payment-service/internal/service/outbox"
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/service/payment"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/health"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/httpserver"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/lifecycle"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/postgres"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/gommon/log"
@@ -24,11 +28,15 @@ type App struct {
 	cfg       *config.Config
 	interrupt <-chan os.Signal
 
+	// autoMigrate — применять миграции при старте (serve -auto-migrate).
+	autoMigrate bool
+
 	// DB
 	postgres *postgres.Postgres
 
 	// Echo
 	echoHandler *echo.Echo
+	health      *health.Health
 
 	// Repositories
 	paymentRepo    *payment_repository.Repository
@@ -37,6 +45,7 @@ type App struct {
 
 	// Services
 	paymentService *payment.Service
+	outboxService  *outbox_service.Service
 
 	// Handlers
 	postPaymentHandler handler.Handler
@@ -45,56 +54,73 @@ type App struct {
 	orderConsumer *consumer_order.Consumer
 
 	// Outbox
-	OutboxWorker *outbox.Worker
+	OutboxWorker    *outbox.Worker
+	OutboxRetention *outbox.Retention
 }
 
 func New(configPath string) *App {
 	cfg, err := config.New(configPath)
 	if err != nil {
-		log.Fatalf("app - New - config.New: %v", err)
+		// Логгер ещё не настроен, а в JSON список ошибок конфигурации склеился бы в одну строку.
+		fmt.Fprintf(os.Stderr, "app - New - config.New: %v\n", err)
+		os.Exit(1)
 	}
 
 	initLogger(cfg.Log.Level)
 
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+
 	return &App{
-		cfg: cfg,
+		cfg:       cfg,
+		interrupt: interrupt,
 	}
 }
 
 func (app *App) Start() {
+	// Компоненты регистрируются в порядке зависимостей: пулы, outbox, консьюмеры, HTTP.
+	// При остановке порядок обратный — сначала прекращается приём запросов и сообщений,
+	// затем дообрабатывается начатая работа, и только потом закрываются пулы.
+	lc := lifecycle.New(lifecycle.ShutdownTimeout(app.cfg.App.ShutdownTimeout))
+
 	// Postgres
 	log.Info("Connecting to PostgreSQL...")
 
-	postgres, err := postgres.New(app.cfg.Postgres.URL, postgres.ConnAttempts(5))
+	postgres, err := postgres.New(
+		app.cfg.Postgres.URL,
+		postgres.ConnAttempts(5),
+		postgres.SlowQueryThreshold(app.cfg.Postgres.SlowQueryThreshold),
+	)
 
 	if err != nil {
 		log.Fatalf("app - Start - Postgres failed:%v", err)
 	}
 	app.postgres = postgres
+	app.Health().AddReadinessCheck("postgres", postgres.Ping)
 
-	defer postgres.Close()
+	lc.Append(lifecycle.Hook{
+		Name: "postgres",
+		OnStop: func(context.Context) error {
+			postgres.Close()
+			return nil
+		},
+	})
 
-	// Migrations
-	if err := database.RunMigrations(context.Background(), app.postgres.Pool); err != nil {
-		log.Errorf("app - Start - Migrations failed: %v", err)
-	}
+	// Migrations: схема должна соответствовать миграциям бинарника (см. checkSchema).
+	app.checkSchema(context.Background())
 
-	// Общий контекст для всех фоновых задач
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Outbox publisher
+	kafkaPublisher := app.newKafkaPublisher()
 
-	// Consumer для order.events
-	orderKafkaConsumer := kafka.NewConsumer(app.cfg.Kafka.Brokers)
+	lc.Append(lifecycle.Hook{
+		Name: "kafka publisher",
+		OnStop: func(context.Context) error {
+			return kafkaPublisher.Close()
+		},
+	})
 
-	app.orderConsumer = consumer_order.New(
-		app.OrderCacheRepo(),
-		orderKafkaConsumer,
-		app.cfg.Kafka.Topics.OrderEvents,
-		app.cfg.Kafka.Consumer.GroupID,
-	)
-
-	// Outbox publisher
-	kafkaPublisher := kafka.NewKafkaPublisher(app.cfg.Kafka.Brokers)
+	// Listener будит воркер по NOTIFY из триггера outbox, не дожидаясь интервала опроса.
+	outboxListener := outbox.NewListener(app.postgres.Pool, outbox.DefaultChannel)
 
 	app.OutboxWorker = outbox.NewWorker(
 		app.OutboxRepo(),
@@ -104,28 +130,62 @@ func (app *App) Start() {
 		app.cfg.Outbox.RequeBatchLimit,
 		app.cfg.Outbox.Interval,
 		app.cfg.Outbox.RequeInterval,
+		outbox.MaxAttempts(app.cfg.Outbox.MaxAttempts),
+		outbox.RetryBackoff(app.cfg.Outbox.RetryBackoff, app.cfg.Outbox.RetryMaxBackoff),
+		outbox.Lease(app.cfg.Outbox.Lease),
+		outbox.WakeUp(outboxListener.C()),
+		outbox.Routes(app.outboxRouter()),
 	)
 
+	lc.Append(
+		lifecycle.Component("outbox listener", outboxListener),
+		lifecycle.Component("outbox worker", app.OutboxWorker),
+	)
+
+	// Retention переносит старые processed-записи в outbox_archive.
+	if app.cfg.Outbox.RetentionMaxAge > 0 {
+		app.OutboxRetention = outbox.NewRetention(
+			app.OutboxRepo(),
+			app.cfg.Outbox.RetentionMaxAge,
+			outbox.RetentionInterval(app.cfg.Outbox.RetentionInterval),
+			outbox.RetentionTopic(app.cfg.Outbox.Topic),
+		)
+
+		lc.Append(lifecycle.Component("outbox retention", app.OutboxRetention))
+	}
+
+	// Consumer для order.events
+	app.orderConsumer = consumer_order.New(
+		app.OrderCacheRepo(),
+		app.newKafkaConsumer("order consumer"),
+		app.cfg.Kafka.Topics.OrderEvents,
+		app.cfg.Kafka.Consumer.GroupID,
+	)
+
+	lc.Append(app.consumerHook("order consumer", app.orderConsumer))
+
+	// Kafka нужна консьюмерам и outbox, но не обработке HTTP-запросов: события копятся в outbox
+	// и уйдут, когда брокеры вернутся.
+	app.Health().AddOptionalReadinessCheck("kafka", app.kafkaCheck)
+
 	// App server
-	log.Info("Starting app server...")
 	httpServer := httpserver.New(app.EchoHandler(), httpserver.Port(app.cfg.HTTP.Port))
-	httpServer.Start()
-	log.Debugf("Server port: %s", app.cfg.HTTP.Port)
-
-	// Run consumers and publisher
-	app.orderConsumer.Run(ctx)
-	app.OutboxWorker.Run(ctx)
-
-	select {
-	case s := <-app.interrupt:
-		log.Infof("app - Start - signal: %v", s)
-	case err := <-httpServer.Notify():
-		log.Errorf("app - Start - server error: %v", err)
-	}
 
-	// Останавливаем HTTP‑сервер после получения сигнала/ошибки.
-	if err := httpServer.Shutdown(); err != nil {
-		log.Errorf("HTTP server shutdown error: %v", err)
+	lc.Append(lifecycle.Hook{
+		Name: "http server",
+		OnStart: func(context.Context) error {
+			log.Infof("Starting app server on port %s...", app.cfg.HTTP.Port)
+			httpServer.Start()
+			lc.Watch("http server", httpServer.Notify())
+			return nil
+		},
+		OnStop: httpServer.Stop,
+	})
+
+	lc.Append(app.readinessHook())
+
+	if err := lc.Run(app.interrupt); err != nil {
+		log.Errorf("app - Start: %v", err)
 	}
 
 	log.Info("Shutting down...")
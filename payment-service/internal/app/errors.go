@@ -0,0 +1,23 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/problem"
+	outbox_service "github.com/4udiwe/big-bob-pizza/payment-service/internal/service/outbox"
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/service/payment"
+	"github.com/labstack/echo/v4"
+)
+
+// errorHandler переводит ошибки сервисов в ответы application/problem+json.
+// code — часть контракта API: клиенты ветвятся по нему, поэтому менять его нельзя.
+func (app *App) errorHandler() echo.HTTPErrorHandler {
+	return problem.New(
+		problem.Map(payment.ErrPaymentNotFound, http.StatusNotFound, "payment_not_found"),
+		problem.Map(payment.ErrOrderNotFound, http.StatusNotFound, "order_not_found"),
+		problem.Map(payment.ErrOrderAlreadyPaid, http.StatusConflict, "order_already_paid"),
+		problem.Map(payment.ErrInvalidAmount, http.StatusBadRequest, "invalid_amount"),
+		problem.Map(outbox_service.ErrEventNotFound, http.StatusNotFound, "outbox_event_not_found"),
+		problem.Map(outbox_service.ErrEmptySelection, http.StatusBadRequest, "empty_selection"),
+	).Handle
+}
@@ -2,10 +2,13 @@ package app
 
 import (
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/handler"
+	get_outbox_event "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler/get_outbox_event"
+	get_outbox_events "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler/get_outbox_events"
 	get_payment "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler/get_payment"
 	get_payment_by_order "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler/get_payment_by_order"
 	get_payments "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler/get_payments"
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/handler/post_payment"
+	post_outbox_action "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler/post_outbox_action"
 )
 
 func (app *App) PostPaymentHandler() handler.Handler {
@@ -28,3 +31,22 @@ func (app *App) GetPaymentByOrderHandler() handler.Handler {
 	return get_payment_by_order.New(app.PaymentService())
 }
 
+func (app *App) GetOutboxEventsHandler() handler.Handler {
+	return get_outbox_events.New(app.OutboxService())
+}
+
+func (app *App) GetOutboxEventHandler() handler.Handler {
+	return get_outbox_event.New(app.OutboxService())
+}
+
+func (app *App) RetryOutboxHandler() handler.Handler {
+	return post_outbox_action.NewRetry(app.OutboxService())
+}
+
+func (app *App) SkipOutboxHandler() handler.Handler {
+	return post_outbox_action.NewSkip(app.OutboxService())
+}
+
+func (app *App) RepublishOutboxHandler() handler.Handler {
+	return post_outbox_action.NewRepublish(app.OutboxService())
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/health"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/lifecycle"
+)
+
+// Health возвращает проверки /livez и /readyz. Проверки зависимостей регистрируются в Start
+// по мере создания компонентов.
+func (app *App) Health() *health.Health {
+	if app.health != nil {
+		return app.health
+	}
+	app.health = health.New(
+		health.CacheTTL(app.cfg.Health.CacheTTL),
+		health.CheckTimeout(app.cfg.Health.CheckTimeout),
+	)
+	return app.health
+}
+
+// kafkaCheck проверяет, что брокеры отвечают на запрос метаданных.
+func (app *App) kafkaCheck(ctx context.Context) error {
+	return kafka.Ping(ctx, app.cfg.Kafka.Brokers)
+}
+
+// consumerLiveness — консьюмер жив, пока его цикл чтения обращается к брокеру не реже health.max_poll_age.
+func (app *App) consumerLiveness(c *kafka.KafkaConsumer) health.CheckFunc {
+	return func(context.Context) error {
+		maxAge := app.cfg.Health.MaxPollAge
+		if age := c.PollAge(); maxAge > 0 && age > maxAge {
+			return fmt.Errorf("no poll for %v", age.Round(time.Second))
+		}
+		return nil
+	}
+}
+
+// readinessHook регистрируется последним и поэтому останавливается первым: сервис отвечает
+// «не готов» и ждёт health.drain_delay, чтобы балансировщик успел вывести его из ротации,
+// пока HTTP-сервер ещё принимает запросы.
+func (app *App) readinessHook() lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: "readiness",
+		OnStop: func(ctx context.Context) error {
+			app.Health().Drain()
+
+			select {
+			case <-time.After(app.cfg.Health.DrainDelay):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
+	"github.com/labstack/gommon/log"
+)
+
+// outboxTransformers — преобразователи payload, на которые можно сослаться из outbox.routes[].transform.
+var outboxTransformers = map[string]outbox.Transformer{}
+
+// outboxRouter строит таблицу маршрутизации outbox из конфигурации.
+// Без маршрутов возвращает nil — воркер публикует всё в outbox.topic.
+func (app *App) outboxRouter() *outbox.Router {
+	if len(app.cfg.Outbox.Routes) == 0 {
+		return nil
+	}
+
+	routes := make([]outbox.Route, len(app.cfg.Outbox.Routes))
+	for i, r := range app.cfg.Outbox.Routes {
+		routes[i] = outbox.Route{
+			AggregateType: r.AggregateType,
+			EventType:     r.EventType,
+			Topic:         r.Topic,
+			Transform:     r.Transform,
+		}
+	}
+
+	router, err := outbox.NewRouter(routes, outboxTransformers)
+	if err != nil {
+		log.Fatalf("app - outboxRouter: %v", err)
+	}
+
+	return router
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	outbox_service "github.com/4udiwe/big-bob-pizza/payment-service/internal/service/outbox"
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/service/payment"
 )
 
@@ -17,3 +18,10 @@ func (app *App) PaymentService() *payment.Service {
 	return app.paymentService
 }
 
+func (app *App) OutboxService() *outbox_service.Service {
+	if app.outboxService != nil {
+		return app.outboxService
+	}
+	app.outboxService = outbox_service.NewService(app.OutboxRepo())
+	return app.outboxService
+}
@@ -2,12 +2,12 @@ package consumer_order
 
 import (
 	"context"
-	"encoding/json"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
 	"github.com/4udiwe/big-bob-pizza/order-service/pkg/kafka"
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/schema"
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
 	order_cache "github.com/4udiwe/big-bob-pizza/payment-service/internal/repository/order_cache"
-	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -36,41 +36,21 @@ func New(
 func (c *Consumer) Run(ctx context.Context) error {
 	logrus.Infof("OrderConsumer: subscribing to topic=%s group=%s", c.topic, c.groupID)
 
-	return c.consumer.Subscribe(ctx, c.topic, c.groupID, func(ctx context.Context, key, value []byte) error {
-		// Парсим envelope
-		var env kafka.Envelope
-		if err := json.Unmarshal(value, &env); err != nil {
-			logrus.Errorf("OrderConsumer: failed to parse envelope: %v", err)
-			return nil
-		}
-
-		// Обрабатываем только событие order.created
-		if env.EventType != "order.created" {
-			return nil
-		}
-
-		// Парсим payload события order.created
-		var payload struct {
-			OrderID    uuid.UUID `json:"orderId"`
-			UserID     uuid.UUID `json:"userId"`
-			TotalPrice float64   `json:"totalPrice"`
-		}
-
-		if env.Data == nil {
-			logrus.Errorf("OrderConsumer: empty data for event %s", env.EventType)
-			return nil
-		}
+	return c.consumer.Subscribe(ctx, c.topic, c.groupID, c.Dispatcher().Dispatch)
+}
 
-		if err := json.Unmarshal(env.Data, &payload); err != nil {
-			logrus.Errorf("OrderConsumer: failed to parse payload: %v", err)
-			return nil
-		}
+// Dispatcher возвращает обработчики событий топика.
+// Используется и при чтении из Kafka, и при повторном прогоне событий (replay).
+func (c *Consumer) Dispatcher() *kafka.Dispatcher {
+	d := kafka.NewDispatcher(schema.Default())
 
+	// Обрабатываем только событие order.created
+	kafka.Handle(d, func(ctx context.Context, env kafka.Envelope, ev events.OrderCreated) error {
 		// Сохраняем информацию о заказе в кэш для последующей оплаты
 		orderInfo := entity.OrderInfo{
-			OrderID:    payload.OrderID,
-			UserID:     payload.UserID,
-			TotalPrice: payload.TotalPrice,
+			OrderID:    ev.OrderID,
+			UserID:     ev.UserID,
+			TotalPrice: ev.TotalPrice,
 			CreatedAt:  env.OccurredAt,
 		}
 
@@ -79,7 +59,14 @@ func (c *Consumer) Run(ctx context.Context) error {
 			return err
 		}
 
-		logrus.Infof("OrderConsumer: order cousumed and cached for payment orderID=%s", payload.OrderID)
+		logrus.Infof("OrderConsumer: order cousumed and cached for payment orderID=%s", ev.OrderID)
 		return nil
 	})
+
+	return d
+}
+
+// Shutdown прекращает чтение топика и дожидается обработки уже прочитанных событий.
+func (c *Consumer) Shutdown(ctx context.Context) error {
+	return c.consumer.Shutdown(ctx)
 }
@@ -1,8 +1,10 @@
 package entity
 
 import (
+	"encoding/json"
 	"time"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
 	"github.com/google/uuid"
 )
 
@@ -12,6 +14,10 @@ const (
 	OutboxStatusPending   OutboxStatusName = "pending"
 	OutboxStatusFailed    OutboxStatusName = "failed"
 	OutboxStatusProcessed OutboxStatusName = "processed"
+	// OutboxStatusDead — попытки публикации исчерпаны, событие больше не отправляется.
+	OutboxStatusDead OutboxStatusName = "dead"
+	// OutboxStatusSkipped — событие вручную исключено из публикации через админский API.
+	OutboxStatusSkipped OutboxStatusName = "skipped"
 )
 
 type OutboxStatus struct {
@@ -24,9 +30,30 @@ type OutboxEvent struct {
 	AggregateType string
 	AggregateID   uuid.UUID
 	EventType     string
-	Payload       map[string]any
+	Payload       events.Event
+	// RawPayload — payload в том виде, в каком он хранится в outbox.
+	RawPayload    json.RawMessage
 	Status        OutboxStatus
 	CreatedAt     time.Time
 	ProcessedAt   *time.Time
+	Attempts      int
+	LastError     *string
+	NextAttemptAt *time.Time
 }
 
+// OutboxFilter — выборка outbox-записей для админского API.
+// Пустые поля выборку не ограничивают.
+type OutboxFilter struct {
+	IDs           []uuid.UUID
+	Status        OutboxStatusName
+	AggregateType string
+	AggregateID   *uuid.UUID
+	From          *time.Time
+	To            *time.Time
+}
+
+// IsEmpty сообщает, что фильтр выбирает все записи.
+func (f OutboxFilter) IsEmpty() bool {
+	return len(f.IDs) == 0 && f.Status == "" && f.AggregateType == "" &&
+		f.AggregateID == nil && f.From == nil && f.To == nil
+}
@@ -0,0 +1,12 @@
+package get_outbox_event
+
+import (
+	"context"
+
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+type OutboxService interface {
+	Get(ctx context.Context, id uuid.UUID) (entity.OutboxEvent, error)
+}
@@ -0,0 +1,74 @@
+package get_outbox_event
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
+	h "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	s OutboxService
+}
+
+func New(s OutboxService) h.Handler {
+	return &handler{s: s}
+}
+
+// GetOutboxEvent godoc
+// @Summary Получить outbox-событие по ID (админ)
+// @Description Возвращает событие вместе с payload в том виде, в каком он хранится в outbox, и последней ошибкой публикации
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminAPIKey
+// @Param id path string true "ID события (UUID)"
+// @Success 200 {object} Response
+// @Failure 400 {string} string "Неверный ID"
+// @Failure 401 {string} string "Неверный API-ключ"
+// @Failure 404 {string} string "Событие не найдено"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Router /admin/outbox/{id} [get]
+func (h *handler) Handle(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid event ID")
+	}
+
+	ev, err := h.s.Get(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{
+		ID:            ev.ID,
+		AggregateType: ev.AggregateType,
+		AggregateID:   ev.AggregateID,
+		EventType:     ev.EventType,
+		Payload:       ev.RawPayload,
+		Status:        ev.Status.Name,
+		Attempts:      ev.Attempts,
+		LastError:     ev.LastError,
+		NextAttemptAt: ev.NextAttemptAt,
+		CreatedAt:     ev.CreatedAt,
+		ProcessedAt:   ev.ProcessedAt,
+	})
+}
+
+type Response struct {
+	ID            uuid.UUID               `json:"id"`
+	AggregateType string                  `json:"aggregateType"`
+	AggregateID   uuid.UUID               `json:"aggregateId"`
+	EventType     string                  `json:"eventType"`
+	Payload       json.RawMessage         `json:"payload" swaggertype:"object"`
+	Status        entity.OutboxStatusName `json:"status"`
+	Attempts      int                     `json:"attempts"`
+	LastError     *string                 `json:"lastError,omitempty"`
+	NextAttemptAt *time.Time              `json:"nextAttemptAt,omitempty"`
+	CreatedAt     time.Time               `json:"createdAt"`
+	ProcessedAt   *time.Time              `json:"processedAt,omitempty"`
+}
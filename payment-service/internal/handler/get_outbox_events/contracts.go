@@ -0,0 +1,11 @@
+package get_outbox_events
+
+import (
+	"context"
+
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
+)
+
+type OutboxService interface {
+	List(ctx context.Context, filter entity.OutboxFilter, limit, offset int) ([]entity.OutboxEvent, int, error)
+}
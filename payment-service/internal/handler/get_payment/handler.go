@@ -2,13 +2,11 @@ package get_payment
 
 import (
 	"context"
-	"errors"
 	"net/http"
 	"time"
 
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
 	h "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler"
-	service "github.com/4udiwe/big-bob-pizza/payment-service/internal/service/payment"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -45,10 +43,7 @@ func (h *handler) Handle(c echo.Context) error {
 
 	payment, err := h.s.GetPaymentByID(c.Request().Context(), paymentID)
 	if err != nil {
-		if errors.Is(err, service.ErrPaymentNotFound) {
-			return echo.NewHTTPError(http.StatusNotFound, err.Error())
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := PaymentResponse{
@@ -79,7 +79,7 @@ func (h *handler) Handle(c echo.Context) error {
 
 	payments, total, err := h.s.GetAllPayments(c.Request().Context(), limit, offset, status, userID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := PaymentsResponse{
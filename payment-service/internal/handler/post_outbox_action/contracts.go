@@ -0,0 +1,16 @@
+package post_outbox_action
+
+import (
+	"context"
+
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
+)
+
+type OutboxService interface {
+	Retry(ctx context.Context, filter entity.OutboxFilter) (int64, error)
+	Skip(ctx context.Context, filter entity.OutboxFilter) (int64, error)
+	Republish(ctx context.Context, filter entity.OutboxFilter) (int64, error)
+}
+
+// Action — Retry, Skip или Republish сервиса outbox.
+type Action func(ctx context.Context, filter entity.OutboxFilter) (int64, error)
@@ -0,0 +1,105 @@
+package post_outbox_action
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
+	h "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler"
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/handler/decorator"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+type handler struct {
+	action Action
+}
+
+// Один обработчик обслуживает все действия над выборкой: отличаются они только вызываемым
+// методом сервиса, а godoc-аннотации для swag вынесены на конструкторы.
+
+// Retry godoc
+// @Summary Повторить публикацию outbox-событий (админ)
+// @Description Возвращает в очередь failed- и dead-события выборки, сбрасывая счётчик попыток. Выборка не может быть пустой
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminAPIKey
+// @Param request body Request true "Выборка событий"
+// @Success 200 {object} Response
+// @Failure 400 {string} string "Ошибка валидации или пустая выборка"
+// @Failure 401 {string} string "Неверный API-ключ"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Router /admin/outbox/retry [post]
+func NewRetry(s OutboxService) h.Handler {
+	return New(s.Retry)
+}
+
+// Skip godoc
+// @Summary Пропустить outbox-события (админ)
+// @Description Исключает из публикации pending-, failed- и dead-события выборки (статус skipped). Выборка не может быть пустой
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminAPIKey
+// @Param request body Request true "Выборка событий"
+// @Success 200 {object} Response
+// @Failure 400 {string} string "Ошибка валидации или пустая выборка"
+// @Failure 401 {string} string "Неверный API-ключ"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Router /admin/outbox/skip [post]
+func NewSkip(s OutboxService) h.Handler {
+	return New(s.Skip)
+}
+
+// Republish godoc
+// @Summary Опубликовать outbox-события заново (админ)
+// @Description Ставит в очередь события выборки в любом конечном статусе, в том числе уже отправленные. eventId сохраняется. Выборка не может быть пустой
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminAPIKey
+// @Param request body Request true "Выборка событий"
+// @Success 200 {object} Response
+// @Failure 400 {string} string "Ошибка валидации или пустая выборка"
+// @Failure 401 {string} string "Неверный API-ключ"
+// @Failure 500 {string} string "Внутренняя ошибка сервера"
+// @Router /admin/outbox/republish [post]
+func NewRepublish(s OutboxService) h.Handler {
+	return New(s.Republish)
+}
+
+func New(action Action) h.Handler {
+	return decorator.NewBindAndValidateDecorator(&handler{action: action})
+}
+
+type Request struct {
+	IDs           []uuid.UUID `json:"ids"`
+	Status        string      `json:"status" validate:"omitempty,oneof=pending failed processed dead skipped"`
+	AggregateType string      `json:"aggregateType"`
+	AggregateID   *uuid.UUID  `json:"aggregateId"`
+	From          *time.Time  `json:"from"`
+	To            *time.Time  `json:"to"`
+}
+
+type Response struct {
+	Affected int64 `json:"affected"`
+}
+
+func (h *handler) Handle(c echo.Context, in Request) error {
+	filter := entity.OutboxFilter{
+		IDs:           in.IDs,
+		Status:        entity.OutboxStatusName(in.Status),
+		AggregateType: in.AggregateType,
+		AggregateID:   in.AggregateID,
+		From:          in.From,
+		To:            in.To,
+	}
+
+	affected, err := h.action(c.Request().Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, Response{Affected: affected})
+}
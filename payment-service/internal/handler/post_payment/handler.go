@@ -1,14 +1,12 @@
 package post_payment
 
 import (
-	"errors"
 	"net/http"
 	"time"
 
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
 	h "github.com/4udiwe/big-bob-pizza/payment-service/internal/handler"
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/handler/decorator"
-	service "github.com/4udiwe/big-bob-pizza/payment-service/internal/service/payment"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
@@ -53,16 +51,7 @@ type Response struct {
 func (h *handler) Handle(c echo.Context, in Request) error {
 	payment, err := h.s.ProcessPayment(c.Request().Context(), in.OrderID, in.Amount)
 	if err != nil {
-		if errors.Is(err, service.ErrOrderNotFound) {
-			return echo.NewHTTPError(http.StatusNotFound, err.Error())
-		}
-		if errors.Is(err, service.ErrOrderAlreadyPaid) {
-			return echo.NewHTTPError(http.StatusConflict, err.Error())
-		}
-		if errors.Is(err, service.ErrInvalidAmount) {
-			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	resp := Response{
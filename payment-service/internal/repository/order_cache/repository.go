@@ -21,6 +21,8 @@ func New(pg *postgres.Postgres) *Repository {
 }
 
 func (r *Repository) Save(ctx context.Context, orderInfo entity.OrderInfo) error {
+	ctx = postgres.WithOperation(ctx, "OrderCacheRepository.Save")
+
 	logrus.Infof("OrderCacheRepository.Save: orderID=%s", orderInfo.OrderID)
 
 	// Заказы доступны для оплаты в течение 30 минут
@@ -43,6 +45,8 @@ func (r *Repository) Save(ctx context.Context, orderInfo entity.OrderInfo) error
 }
 
 func (r *Repository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (entity.OrderInfo, error) {
+	ctx = postgres.WithOperation(ctx, "OrderCacheRepository.GetByOrderID")
+
 	query := `
 		SELECT order_id, user_id, total_price, created_at
 		FROM order_cache
@@ -62,6 +66,8 @@ func (r *Repository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (entit
 }
 
 func (r *Repository) Delete(ctx context.Context, orderID uuid.UUID) error {
+	ctx = postgres.WithOperation(ctx, "OrderCacheRepository.Delete")
+
 	query, args, _ := r.Builder.
 		Delete("order_cache").
 		Where("order_id = ?", orderID).
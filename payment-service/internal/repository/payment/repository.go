@@ -22,6 +22,8 @@ func New(pg *postgres.Postgres) *Repository {
 }
 
 func (r *Repository) Create(ctx context.Context, payment entity.Payment) (entity.Payment, error) {
+	ctx = postgres.WithOperation(ctx, "PaymentRepository.Create")
+
 	logrus.Infof("PaymentRepository.Create: orderID=%s amount=%.2f", payment.OrderID, payment.Amount)
 
 	query, args, _ := r.Builder.
@@ -42,6 +44,8 @@ func (r *Repository) Create(ctx context.Context, payment entity.Payment) (entity
 }
 
 func (r *Repository) GetByID(ctx context.Context, paymentID uuid.UUID) (entity.Payment, error) {
+	ctx = postgres.WithOperation(ctx, "PaymentRepository.GetByID")
+
 	query := `
 		SELECT
 			p.id, p.order_id, p.amount, p.currency,
@@ -69,6 +73,8 @@ func (r *Repository) GetByID(ctx context.Context, paymentID uuid.UUID) (entity.P
 }
 
 func (r *Repository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (entity.Payment, error) {
+	ctx = postgres.WithOperation(ctx, "PaymentRepository.GetByOrderID")
+
 	query := `
 		SELECT
 			p.id, p.order_id, p.amount, p.currency,
@@ -98,6 +104,8 @@ func (r *Repository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (entit
 }
 
 func (r *Repository) UpdateStatus(ctx context.Context, paymentID uuid.UUID, status entity.PaymentStatus, failureReason *string) error {
+	ctx = postgres.WithOperation(ctx, "PaymentRepository.UpdateStatus")
+
 	logrus.Infof("PaymentRepository.UpdateStatus: paymentID=%s status=%s", paymentID, status.Name)
 
 	query, args, _ := r.Builder.
@@ -128,6 +136,8 @@ func (r *Repository) UpdateStatus(ctx context.Context, paymentID uuid.UUID, stat
 
 // GetAllPayments возвращает список платежей с пагинацией и фильтрацией
 func (r *Repository) GetAllPayments(ctx context.Context, limit, offset int, status *entity.PaymentStatusName, userID *uuid.UUID) ([]entity.PaymentWithUser, int, error) {
+	ctx = postgres.WithOperation(ctx, "PaymentRepository.GetAllPayments")
+
 	logrus.Infof("PaymentRepository.GetAllPayments: limit=%d offset=%d", limit, offset)
 
 	// Строим условия для WHERE
@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
+	"github.com/google/uuid"
+)
+
+//go:generate go tool mockgen -source=contracts.go -destination=mocks/mocks.go -package=mocks
+
+type OutboxRepo interface {
+	// Return page of found events sorted by creation time (newest first), total items, and error.
+	List(ctx context.Context, filter entity.OutboxFilter, limit, offset int) (events []entity.OutboxEvent, total int, err error)
+	GetByID(ctx context.Context, id uuid.UUID) (entity.OutboxEvent, error)
+	// Retry, Skip and Republish return number of affected events.
+	Retry(ctx context.Context, filter entity.OutboxFilter) (int64, error)
+	Skip(ctx context.Context, filter entity.OutboxFilter) (int64, error)
+	Republish(ctx context.Context, filter entity.OutboxFilter) (int64, error)
+}
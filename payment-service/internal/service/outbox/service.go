@@ -0,0 +1,78 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
+)
+
+// Service — операции админского API над outbox-событиями.
+type Service struct {
+	OutboxRepo OutboxRepo
+}
+
+func NewService(outboxRepo OutboxRepo) *Service {
+	return &Service{OutboxRepo: outboxRepo}
+}
+
+func (s *Service) List(ctx context.Context, filter entity.OutboxFilter, limit, offset int) ([]entity.OutboxEvent, int, error) {
+	events, total, err := s.OutboxRepo.List(ctx, filter, limit, offset)
+	if err != nil {
+		log.Errorf("OutboxService.List: %v", err)
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (entity.OutboxEvent, error) {
+	ev, err := s.OutboxRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, outbox.ErrEventNotFound) {
+			return entity.OutboxEvent{}, ErrEventNotFound
+		}
+		log.Errorf("OutboxService.Get: %v", err)
+		return entity.OutboxEvent{}, err
+	}
+	return ev, nil
+}
+
+// Retry возвращает в очередь failed- и dead-события выборки.
+func (s *Service) Retry(ctx context.Context, filter entity.OutboxFilter) (int64, error) {
+	return s.apply(ctx, "Retry", filter, s.OutboxRepo.Retry)
+}
+
+// Skip исключает из публикации неотправленные события выборки.
+func (s *Service) Skip(ctx context.Context, filter entity.OutboxFilter) (int64, error) {
+	return s.apply(ctx, "Skip", filter, s.OutboxRepo.Skip)
+}
+
+// Republish повторно публикует события выборки, в том числе уже отправленные.
+func (s *Service) Republish(ctx context.Context, filter entity.OutboxFilter) (int64, error) {
+	return s.apply(ctx, "Republish", filter, s.OutboxRepo.Republish)
+}
+
+// apply запрещает действия над всей таблицей: выборка должна быть явно ограничена.
+func (s *Service) apply(
+	ctx context.Context,
+	op string,
+	filter entity.OutboxFilter,
+	fn func(context.Context, entity.OutboxFilter) (int64, error),
+) (int64, error) {
+	if filter.IsEmpty() {
+		return 0, ErrEmptySelection
+	}
+
+	affected, err := fn(ctx, filter)
+	if err != nil {
+		log.Errorf("OutboxService.%s: %v", op, err)
+		return 0, err
+	}
+
+	log.Infof("OutboxService.%s: %d events affected", op, affected)
+	return affected, nil
+}
@@ -0,0 +1,85 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/outbox"
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
+	service "github.com/4udiwe/big-bob-pizza/payment-service/internal/service/outbox"
+	"github.com/4udiwe/big-bob-pizza/payment-service/internal/service/outbox/mocks"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_Get(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{name: "found"},
+		{name: "not found", repoErr: outbox.ErrEventNotFound, wantErr: service.ErrEventNotFound},
+		{name: "repo error", repoErr: errors.New("db down"), wantErr: errors.New("db down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockOutboxRepo(ctrl)
+			repo.EXPECT().GetByID(ctx, id).Return(entity.OutboxEvent{ID: id}, tt.repoErr)
+
+			ev, err := service.NewService(repo).Get(ctx, id)
+
+			if tt.wantErr == nil {
+				if err != nil || ev.ID != id {
+					t.Fatalf("Get() = %v, %v; want event %s", ev.ID, err, id)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr.Error() {
+				t.Fatalf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestService_Actions(t *testing.T) {
+	ctx := context.Background()
+	filter := entity.OutboxFilter{Status: entity.OutboxStatusDead}
+
+	t.Run("empty selection is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		s := service.NewService(mocks.NewMockOutboxRepo(ctrl))
+
+		for _, action := range []func(context.Context, entity.OutboxFilter) (int64, error){s.Retry, s.Skip, s.Republish} {
+			if _, err := action(ctx, entity.OutboxFilter{}); !errors.Is(err, service.ErrEmptySelection) {
+				t.Fatalf("error = %v, want ErrEmptySelection", err)
+			}
+		}
+	})
+
+	t.Run("delegates to repository", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockOutboxRepo(ctrl)
+		repo.EXPECT().Retry(ctx, filter).Return(int64(3), nil)
+		repo.EXPECT().Skip(ctx, filter).Return(int64(2), nil)
+		repo.EXPECT().Republish(ctx, filter).Return(int64(0), errors.New("db down"))
+
+		s := service.NewService(repo)
+
+		if n, err := s.Retry(ctx, filter); err != nil || n != 3 {
+			t.Fatalf("Retry() = %d, %v", n, err)
+		}
+		if n, err := s.Skip(ctx, filter); err != nil || n != 2 {
+			t.Fatalf("Skip() = %d, %v", n, err)
+		}
+		if _, err := s.Republish(ctx, filter); err == nil {
+			t.Fatal("Republish() expected error")
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/4udiwe/big-bob-pizza/order-service/pkg/events"
 	"github.com/4udiwe/big-bob-pizza/payment-service/internal/entity"
 	order_cache_repository "github.com/4udiwe/big-bob-pizza/payment-service/internal/repository/order_cache"
 	payment_repository "github.com/4udiwe/big-bob-pizza/payment-service/internal/repository/payment"
@@ -73,11 +74,11 @@ func (s *Service) ProcessPayment(ctx context.Context, orderID uuid.UUID, amount
 			ev := entity.OutboxEvent{
 				AggregateType: "payment",
 				AggregateID:   payment.ID,
-				EventType:     "payment.success",
-				Payload: map[string]any{
-					"paymentId": payment.ID,
-					"orderId":   orderID,
-					"amount":    amount,
+				EventType:     events.TypePaymentSucceeded,
+				Payload: events.PaymentSucceeded{
+					PaymentID: payment.ID,
+					OrderID:   orderID,
+					Amount:    amount,
 				},
 				Status:    entity.OutboxStatus{Name: entity.OutboxStatusPending},
 				CreatedAt: time.Now(),
@@ -97,11 +98,11 @@ func (s *Service) ProcessPayment(ctx context.Context, orderID uuid.UUID, amount
 			ev := entity.OutboxEvent{
 				AggregateType: "payment",
 				AggregateID:   payment.ID,
-				EventType:     "payment.failed",
-				Payload: map[string]any{
-					"paymentId": payment.ID,
-					"orderId":   orderID,
-					"reason":    reason,
+				EventType:     events.TypePaymentFailed,
+				Payload: events.PaymentFailed{
+					PaymentID: payment.ID,
+					OrderID:   orderID,
+					Reason:    reason,
 				},
 				Status:    entity.OutboxStatus{Name: entity.OutboxStatusPending},
 				CreatedAt: time.Now(),